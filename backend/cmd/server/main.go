@@ -32,6 +32,11 @@ func main() {
 	klineHandler := handler.NewKlineHandler()
 	analysisHandler := handler.NewAnalysisHandler()
 	opportunityHandler := handler.NewOpportunityHandler()
+	expressionHandler := handler.NewExpressionHandler()
+	calibrationHandler := handler.NewCalibrationHandler()
+	correlationHandler := handler.NewCorrelationHandler()
+	seasonalityHandler := handler.NewSeasonalityHandler()
+	scanHandler := handler.NewScanHandler()
 
 	// API routes
 	api := r.Group("/api")
@@ -41,10 +46,30 @@ func main() {
 
 		// Analysis endpoint
 		api.GET("/analysis", analysisHandler.GetAnalysis)
+		api.GET("/analysis/mtf", analysisHandler.GetMTFAnalysis)
 
 		// Opportunities endpoint
 		api.GET("/opportunities", opportunityHandler.GetOpportunities)
 
+		// Custom expression endpoints
+		api.GET("/expressions", expressionHandler.ListExpressions)
+		api.POST("/expressions", expressionHandler.SaveExpression)
+		api.DELETE("/expressions/:name", expressionHandler.DeleteExpression)
+		api.POST("/expressions/evaluate", expressionHandler.EvaluateExpression)
+
+		// Pattern reliability calibration endpoints
+		api.GET("/calibration", calibrationHandler.GetCalibration)
+		api.POST("/calibration/run", calibrationHandler.RunCalibration)
+
+		// Cross-symbol correlation and beta
+		api.GET("/correlation", correlationHandler.GetCorrelation)
+
+		// Hour, weekday and month return statistics
+		api.GET("/seasonality", seasonalityHandler.GetSeasonality)
+
+		// Multi-symbol market scanner
+		api.GET("/scan", scanHandler.Scan)
+
 		// Health check
 		api.GET("/health", func(c *gin.Context) {
 			c.JSON(200, gin.H{
@@ -61,7 +86,14 @@ func main() {
 	log.Println("  GET /api/health")
 	log.Println("  GET /api/kline?symbol=ETHUSDT&interval=1d&limit=100")
 	log.Println("  GET /api/analysis?symbol=ETHUSDT&interval=1d&limit=100")
+	log.Println("  GET /api/analysis/mtf?symbol=ETHUSDT&intervals=15m,1h,4h,1d")
 	log.Println("  GET /api/opportunities?symbol=ETHUSDT&interval=1h&min_rr=3.0")
+	log.Println("  GET|POST /api/expressions, DELETE /api/expressions/:name")
+	log.Println("  POST /api/expressions/evaluate")
+	log.Println("  GET /api/calibration?symbol=ETHUSDT&interval=1h, POST /api/calibration/run")
+	log.Println("  GET /api/correlation?symbols=ETHUSDT,SOLUSDT&benchmark=BTCUSDT&interval=1h&window=30")
+	log.Println("  GET /api/seasonality?symbol=ETHUSDT&interval=1h&timezone=UTC&bars=2000")
+	log.Println("  GET /api/scan?top=20&interval=1h&filter=rsi14<30&sort=market_quality.overall_score&page=1")
 
 	if err := r.Run(":8080"); err != nil {
 		log.Fatal("Failed to start server:", err)
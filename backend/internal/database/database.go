@@ -22,7 +22,8 @@ func InitDB() error {
 	dbPath := filepath.Join(dataDir, "opportunities.db")
 
 	var err error
-	DB, err = sql.Open("sqlite3", dbPath)
+	// Wait for locks instead of failing: concurrent analyses write zones and patterns at the same time
+	DB, err = sql.Open("sqlite3", dbPath+"?_busy_timeout=5000")
 	if err != nil {
 		return err
 	}
@@ -74,6 +75,89 @@ func createTables() error {
 	CREATE INDEX IF NOT EXISTS idx_status ON opportunities(status);
 	CREATE INDEX IF NOT EXISTS idx_timestamp ON opportunities(timestamp DESC);
 	CREATE INDEX IF NOT EXISTS idx_expires_at ON opportunities(expires_at);
+
+	CREATE TABLE IF NOT EXISTS expressions (
+		name TEXT PRIMARY KEY,
+		expression TEXT NOT NULL,
+		description TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS klines (
+		symbol TEXT NOT NULL,
+		interval TEXT NOT NULL,
+		open_time INTEGER NOT NULL,
+		open REAL NOT NULL,
+		high REAL NOT NULL,
+		low REAL NOT NULL,
+		close REAL NOT NULL,
+		volume REAL NOT NULL,
+		PRIMARY KEY (symbol, interval, open_time)
+	);
+
+	CREATE TABLE IF NOT EXISTS pattern_stats (
+		symbol TEXT NOT NULL,
+		interval TEXT NOT NULL,
+		pattern TEXT NOT NULL,
+		direction TEXT NOT NULL,
+		horizon INTEGER NOT NULL,
+		samples INTEGER NOT NULL,
+		hits INTEGER NOT NULL,
+		hit_rate REAL NOT NULL,
+		avg_return REAL NOT NULL,
+		prior_reliability REAL NOT NULL,
+		empirical_reliability REAL NOT NULL,
+		updated_at INTEGER NOT NULL,
+		PRIMARY KEY (symbol, interval, pattern, direction)
+	);
+
+	CREATE TABLE IF NOT EXISTS pattern_confirmations (
+		symbol TEXT NOT NULL,
+		interval TEXT NOT NULL,
+		pattern TEXT NOT NULL,
+		timestamp INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		direction TEXT NOT NULL,
+		bars INTEGER NOT NULL,
+		reliability REAL NOT NULL,
+		description TEXT NOT NULL,
+		confirmation_price REAL NOT NULL,
+		invalidation_price REAL NOT NULL,
+		status TEXT NOT NULL,
+		resolved_at INTEGER NOT NULL,
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL,
+		PRIMARY KEY (symbol, interval, pattern, timestamp)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_pattern_confirmations_status ON pattern_confirmations(symbol, interval, status);
+
+	CREATE TABLE IF NOT EXISTS sr_zones (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		symbol TEXT NOT NULL,
+		interval TEXT NOT NULL,
+		price REAL NOT NULL,
+		price_key REAL NOT NULL,
+		lower REAL NOT NULL,
+		upper REAL NOT NULL,
+		source TEXT NOT NULL,
+		touches INTEGER NOT NULL,
+		first_touch INTEGER NOT NULL,
+		last_touch INTEGER NOT NULL,
+		origin_role TEXT NOT NULL,
+		breaks INTEGER NOT NULL,
+		broken_at INTEGER NOT NULL,
+		side INTEGER NOT NULL,
+		in_zone INTEGER NOT NULL,
+		through INTEGER NOT NULL,
+		touch_volume REAL NOT NULL,
+		touch_bars INTEGER NOT NULL,
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_sr_zones_price ON sr_zones(symbol, interval, price_key);
 	`
 
 	_, err := DB.Exec(schema)
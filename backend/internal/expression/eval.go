@@ -0,0 +1,549 @@
+package expression
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/kudaompq/ai_trending/backend/internal/indicator"
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// Resolver looks up the source of a saved expression by name; found is false for unknown names and
+// err reports a failed lookup
+type Resolver func(name string) (source string, found bool, err error)
+
+// resolveError is a failed saved expression lookup; unlike expression errors it is not the caller's fault
+type resolveError struct {
+	name string
+	err  error
+}
+
+func (e *resolveError) Error() string { return fmt.Sprintf("saved expression %q: %v", e.name, e.err) }
+func (e *resolveError) Unwrap() error { return e.err }
+
+// evaluator evaluates expression trees over a candle series.
+// Every value is a series aligned with the candles; NaN marks bars where the value is undefined.
+// Comparisons and logical operators return 1 (true) / 0 (false).
+type evaluator struct {
+	candles   []model.Candle
+	resolve   Resolver
+	resolving map[string]bool
+	saved     map[string][]float64
+}
+
+// Evaluate parses and evaluates an expression over the candles. Errors caused by the expression wrap
+// ErrInvalidExpression; a failing Resolver's error is returned as is.
+func Evaluate(input string, candles []model.Candle, resolve Resolver) ([]float64, error) {
+	node, err := Parse(input)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &evaluator{
+		candles:   candles,
+		resolve:   resolve,
+		resolving: make(map[string]bool),
+		saved:     make(map[string][]float64),
+	}
+	series, err := e.eval(node)
+	if err != nil {
+		var lookupErr *resolveError
+		if errors.As(err, &lookupErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExpression, err)
+	}
+	return series, nil
+}
+
+// IsTrue reports whether a boolean series is true on its last bar
+func IsTrue(series []float64) bool {
+	if len(series) == 0 {
+		return false
+	}
+	last := series[len(series)-1]
+	return !math.IsNaN(last) && last != 0
+}
+
+func (e *evaluator) eval(node Node) ([]float64, error) {
+	switch n := node.(type) {
+	case *numberNode:
+		return e.constant(n.value), nil
+	case *stringNode:
+		return nil, fmt.Errorf("text %s is only allowed in filters", n)
+	case *identNode:
+		return e.evalIdent(n.name)
+	case *callNode:
+		return e.evalCall(n)
+	case *unaryNode:
+		return e.evalUnary(n)
+	case *binaryNode:
+		return e.evalBinary(n)
+	case *indexNode:
+		series, err := e.eval(n.series)
+		if err != nil {
+			return nil, err
+		}
+		return shift(series, n.offset), nil
+	default:
+		return nil, fmt.Errorf("unsupported expression node %T", node)
+	}
+}
+
+func (e *evaluator) constant(value float64) []float64 {
+	out := make([]float64, len(e.candles))
+	for i := range out {
+		out[i] = value
+	}
+	return out
+}
+
+func (e *evaluator) evalIdent(name string) ([]float64, error) {
+	out := make([]float64, len(e.candles))
+	switch name {
+	case "open":
+		for i, c := range e.candles {
+			out[i] = c.Open
+		}
+	case "high":
+		for i, c := range e.candles {
+			out[i] = c.High
+		}
+	case "low":
+		for i, c := range e.candles {
+			out[i] = c.Low
+		}
+	case "close":
+		for i, c := range e.candles {
+			out[i] = c.Close
+		}
+	case "volume":
+		for i, c := range e.candles {
+			out[i] = c.Volume
+		}
+	case "hl2":
+		for i, c := range e.candles {
+			out[i] = (c.High + c.Low) / 2
+		}
+	case "hlc3":
+		for i, c := range e.candles {
+			out[i] = (c.High + c.Low + c.Close) / 3
+		}
+	case "true":
+		return e.constant(1), nil
+	case "false":
+		return e.constant(0), nil
+	default:
+		return e.evalSaved(name)
+	}
+	return out, nil
+}
+
+// evalSaved evaluates a saved expression referenced by name
+func (e *evaluator) evalSaved(name string) ([]float64, error) {
+	if series, ok := e.saved[name]; ok {
+		return series, nil
+	}
+	if e.resolve == nil {
+		return nil, fmt.Errorf("unknown identifier %q", name)
+	}
+	source, ok, err := e.resolve(name)
+	if err != nil {
+		return nil, &resolveError{name: name, err: err}
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown identifier %q", name)
+	}
+	if e.resolving[name] {
+		return nil, fmt.Errorf("saved expression %q references itself", name)
+	}
+
+	node, err := parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("saved expression %q: %v", name, err)
+	}
+
+	e.resolving[name] = true
+	series, err := e.eval(node)
+	delete(e.resolving, name)
+	if err != nil {
+		return nil, err
+	}
+
+	e.saved[name] = series
+	return series, nil
+}
+
+func (e *evaluator) evalUnary(n *unaryNode) ([]float64, error) {
+	operand, err := e.eval(n.operand)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]float64, len(operand))
+	for i, v := range operand {
+		if math.IsNaN(v) {
+			out[i] = math.NaN()
+			continue
+		}
+		if n.op == "-" {
+			out[i] = -v
+		} else {
+			out[i] = boolValue(v == 0)
+		}
+	}
+	return out, nil
+}
+
+func (e *evaluator) evalBinary(n *binaryNode) ([]float64, error) {
+	left, err := e.eval(n.left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.eval(n.right)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]float64, len(left))
+	for i := range left {
+		a, b := left[i], right[i]
+
+		if n.op == "crosses above" || n.op == "crosses below" {
+			if i == 0 || math.IsNaN(a) || math.IsNaN(b) || math.IsNaN(left[i-1]) || math.IsNaN(right[i-1]) {
+				out[i] = math.NaN()
+			} else if n.op == "crosses above" {
+				out[i] = boolValue(left[i-1] <= right[i-1] && a > b)
+			} else {
+				out[i] = boolValue(left[i-1] >= right[i-1] && a < b)
+			}
+			continue
+		}
+
+		if math.IsNaN(a) || math.IsNaN(b) {
+			out[i] = math.NaN()
+			continue
+		}
+
+		switch n.op {
+		case "+":
+			out[i] = a + b
+		case "-":
+			out[i] = a - b
+		case "*":
+			out[i] = a * b
+		case "/":
+			if b == 0 {
+				out[i] = math.NaN()
+			} else {
+				out[i] = a / b
+			}
+		case ">":
+			out[i] = boolValue(a > b)
+		case "<":
+			out[i] = boolValue(a < b)
+		case ">=":
+			out[i] = boolValue(a >= b)
+		case "<=":
+			out[i] = boolValue(a <= b)
+		case "==":
+			out[i] = boolValue(a == b)
+		case "!=":
+			out[i] = boolValue(a != b)
+		case "and":
+			out[i] = boolValue(a != 0 && b != 0)
+		case "or":
+			out[i] = boolValue(a != 0 || b != 0)
+		default:
+			return nil, fmt.Errorf("unsupported operator %q", n.op)
+		}
+	}
+	return out, nil
+}
+
+func (e *evaluator) evalCall(n *callNode) ([]float64, error) {
+	switch n.name {
+	case "sma", "ema", "highest", "lowest":
+		series, period, err := e.seriesAndPeriod(n, true)
+		if err != nil {
+			return nil, err
+		}
+		switch n.name {
+		case "sma":
+			return applyToValid(series, func(values []float64) []float64 { return rollingSMA(values, period) }), nil
+		case "ema":
+			return applyToValid(series, func(values []float64) []float64 { return emaSeries(values, period) }), nil
+		case "highest":
+			return rollingExtreme(series, period, math.Max), nil
+		default:
+			return rollingExtreme(series, period, math.Min), nil
+		}
+
+	case "rsi":
+		series, period, err := e.seriesAndPeriod(n, false)
+		if err != nil {
+			return nil, err
+		}
+		return applyToValid(series, func(values []float64) []float64 { return rsiSeries(values, period) }), nil
+
+	case "atr":
+		period, err := e.periodArg(n, 0, 1)
+		if err != nil {
+			return nil, err
+		}
+		result := indicator.CalculateATR(e.candles, period)
+		out := nanSeries(len(e.candles))
+		for i := period - 1; i < len(result.Values); i++ {
+			out[i] = result.Values[i]
+		}
+		return out, nil
+
+	case "macd", "macd_signal", "macd_hist":
+		if len(n.args) != 0 {
+			return nil, fmt.Errorf("%s() takes no arguments", n.name)
+		}
+		return e.macdSeries(n.name), nil
+
+	case "kdj_k", "kdj_d", "kdj_j":
+		if len(n.args) != 0 {
+			return nil, fmt.Errorf("%s() takes no arguments", n.name)
+		}
+		return e.kdjSeries(n.name), nil
+
+	case "change":
+		if len(n.args) < 1 || len(n.args) > 2 {
+			return nil, fmt.Errorf("change() expects (series) or (series, bars)")
+		}
+		series, err := e.eval(n.args[0])
+		if err != nil {
+			return nil, err
+		}
+		bars := 1
+		if len(n.args) == 2 {
+			if bars, err = e.periodArg(n, 1, 2); err != nil {
+				return nil, err
+			}
+		}
+		prev := shift(series, bars)
+		out := make([]float64, len(series))
+		for i := range series {
+			out[i] = series[i] - prev[i]
+		}
+		return out, nil
+
+	case "abs":
+		if len(n.args) != 1 {
+			return nil, fmt.Errorf("abs() expects 1 argument")
+		}
+		series, err := e.eval(n.args[0])
+		if err != nil {
+			return nil, err
+		}
+		out := make([]float64, len(series))
+		for i, v := range series {
+			out[i] = math.Abs(v)
+		}
+		return out, nil
+
+	case "min", "max":
+		if len(n.args) != 2 {
+			return nil, fmt.Errorf("%s() expects 2 arguments", n.name)
+		}
+		a, err := e.eval(n.args[0])
+		if err != nil {
+			return nil, err
+		}
+		b, err := e.eval(n.args[1])
+		if err != nil {
+			return nil, err
+		}
+		out := make([]float64, len(a))
+		for i := range a {
+			if math.IsNaN(a[i]) || math.IsNaN(b[i]) {
+				out[i] = math.NaN()
+			} else if n.name == "min" {
+				out[i] = math.Min(a[i], b[i])
+			} else {
+				out[i] = math.Max(a[i], b[i])
+			}
+		}
+		return out, nil
+
+	default:
+		return nil, fmt.Errorf("unknown function %q", n.name)
+	}
+}
+
+// seriesAndPeriod parses (series, period) arguments; when seriesRequired is false,
+// a single (period) argument defaults the series to close
+func (e *evaluator) seriesAndPeriod(n *callNode, seriesRequired bool) ([]float64, int, error) {
+	if len(n.args) == 1 && !seriesRequired {
+		period, err := e.periodArg(n, 0, 1)
+		if err != nil {
+			return nil, 0, err
+		}
+		series, _ := e.evalIdent("close")
+		return series, period, nil
+	}
+
+	if len(n.args) != 2 {
+		if seriesRequired {
+			return nil, 0, fmt.Errorf("%s() expects (series, period)", n.name)
+		}
+		return nil, 0, fmt.Errorf("%s() expects (period) or (series, period)", n.name)
+	}
+
+	series, err := e.eval(n.args[0])
+	if err != nil {
+		return nil, 0, err
+	}
+	period, err := e.periodArg(n, 1, 2)
+	if err != nil {
+		return nil, 0, err
+	}
+	return series, period, nil
+}
+
+// periodArg reads a positive integer literal argument
+func (e *evaluator) periodArg(n *callNode, index, expected int) (int, error) {
+	if len(n.args) != expected {
+		return 0, fmt.Errorf("%s() expects %d argument(s)", n.name, expected)
+	}
+	num, ok := n.args[index].(*numberNode)
+	if !ok || num.value < 1 || num.value != float64(int(num.value)) {
+		return 0, fmt.Errorf("%s(): period must be a positive integer", n.name)
+	}
+	return int(num.value), nil
+}
+
+func (e *evaluator) macdSeries(name string) []float64 {
+	out := nanSeries(len(e.candles))
+	macd := indicator.NewStreamingMACD()
+	for i, c := range e.candles {
+		value := macd.Update(c.Close)
+		if !macd.Ready() {
+			continue
+		}
+		switch name {
+		case "macd":
+			out[i] = value.DIF
+		case "macd_signal":
+			out[i] = value.DEA
+		default:
+			out[i] = value.Histogram
+		}
+	}
+	return out
+}
+
+func (e *evaluator) kdjSeries(name string) []float64 {
+	out := nanSeries(len(e.candles))
+	kdj := indicator.NewStreamingKDJ()
+	for i, c := range e.candles {
+		value := kdj.Update(c)
+		if !kdj.Ready() {
+			continue
+		}
+		switch name {
+		case "kdj_k":
+			out[i] = value.K
+		case "kdj_d":
+			out[i] = value.D
+		default:
+			out[i] = value.J
+		}
+	}
+	return out
+}
+
+// applyToValid runs fn over each run of consecutive valid values and re-aligns the output. A NaN
+// (e.g. from a division by zero) stays NaN and restarts the window, so it does not poison later bars.
+func applyToValid(series []float64, fn func([]float64) []float64) []float64 {
+	out := nanSeries(len(series))
+	for start := 0; start < len(series); {
+		if math.IsNaN(series[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(series) && !math.IsNaN(series[end]) {
+			end++
+		}
+		copy(out[start:end], fn(series[start:end]))
+		start = end
+	}
+	return out
+}
+
+func emaSeries(values []float64, period int) []float64 {
+	out := nanSeries(len(values))
+	result := indicator.CalculateEMA(values, period)
+	for i := period - 1; i < len(result.Values); i++ {
+		out[i] = result.Values[i]
+	}
+	return out
+}
+
+func rsiSeries(values []float64, period int) []float64 {
+	out := nanSeries(len(values))
+	rsi := indicator.NewStreamingRSI(period)
+	for i, v := range values {
+		value := rsi.Update(v)
+		if rsi.Ready() {
+			out[i] = value
+		}
+	}
+	return out
+}
+
+func rollingSMA(values []float64, period int) []float64 {
+	out := nanSeries(len(values))
+	sum := 0.0
+	for i, v := range values {
+		sum += v
+		if i >= period {
+			sum -= values[i-period]
+		}
+		if i >= period-1 {
+			out[i] = sum / float64(period)
+		}
+	}
+	return out
+}
+
+func rollingExtreme(series []float64, period int, pick func(a, b float64) float64) []float64 {
+	out := nanSeries(len(series))
+	for i := period - 1; i < len(series); i++ {
+		value := series[i]
+		for j := i - period + 1; j < i; j++ {
+			value = pick(value, series[j])
+		}
+		out[i] = value
+	}
+	return out
+}
+
+// shift looks back offset bars: out[i] = series[i-offset]
+func shift(series []float64, offset int) []float64 {
+	out := nanSeries(len(series))
+	for i := offset; i < len(series); i++ {
+		out[i] = series[i-offset]
+	}
+	return out
+}
+
+func nanSeries(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	return out
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
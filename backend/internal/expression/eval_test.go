@@ -0,0 +1,162 @@
+package expression
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// candlesWithCloses builds candles with the given closes, a half-point body and a one-point wick
+func candlesWithCloses(closes ...float64) []model.Candle {
+	candles := make([]model.Candle, len(closes))
+	for i, c := range closes {
+		candles[i] = model.Candle{Timestamp: int64(i) * 60000, Open: c - 0.5, High: c + 1, Low: c - 1, Close: c, Volume: 100}
+	}
+	return candles
+}
+
+func sameSeries(got, want []float64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if math.IsNaN(want[i]) != math.IsNaN(got[i]) || (!math.IsNaN(want[i]) && math.Abs(got[i]-want[i]) > 1e-9) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestEvaluate(t *testing.T) {
+	nan := math.NaN()
+	rising := candlesWithCloses(1, 2, 3, 4, 5)
+	falling := candlesWithCloses(5, 4, 3, 2, 1)
+
+	tests := []struct {
+		input   string
+		candles []model.Candle
+		want    []float64
+	}{
+		{"1 + 2 * 3", rising, []float64{7, 7, 7, 7, 7}},
+		{"close - open", rising, []float64{0.5, 0.5, 0.5, 0.5, 0.5}},
+		{"close[1]", rising, []float64{nan, 1, 2, 3, 4}},
+		{"close - close[2]", rising, []float64{nan, nan, 2, 2, 2}},
+		{"close[1][1]", rising, []float64{nan, nan, 1, 2, 3}},
+		{"change(close)", rising, []float64{nan, 1, 1, 1, 1}},
+		{"change(close, 3)", rising, []float64{nan, nan, nan, 3, 3}},
+		{"sma(close, 3)", rising, []float64{nan, nan, 2, 3, 4}},
+		{"highest(close, 2)", rising, []float64{nan, 2, 3, 4, 5}},
+		{"lowest(low, 2)", rising, []float64{nan, 0, 1, 2, 3}},
+		{"close / (close - close)", rising, []float64{nan, nan, nan, nan, nan}},
+		// A division by zero mid-series restarts the window instead of poisoning later bars
+		{"sma(1 / (close - 3), 2)", candlesWithCloses(1, 2, 3, 4, 5, 6), []float64{nan, -0.75, nan, nan, 0.75, (0.5 + 1.0/3) / 2}},
+		{"close > 3", rising, []float64{0, 0, 0, 1, 1}},
+		{"not (close > 3)", rising, []float64{1, 1, 1, 0, 0}},
+		{"close >= 2 and close <= 4", rising, []float64{0, 1, 1, 1, 0}},
+		{"close == 1 or close == 5", rising, []float64{1, 0, 0, 0, 1}},
+		{"close crosses above 2.5", rising, []float64{nan, 0, 1, 0, 0}},
+		{"close crosses below 2.5", rising, []float64{nan, 0, 0, 0, 0}},
+		{"close crosses below 2.5", falling, []float64{nan, 0, 0, 1, 0}},
+		{"close crosses above close[1]", falling, []float64{nan, nan, 0, 0, 0}},
+		{"min(close, 3) + max(close, 3)", rising, []float64{4, 5, 6, 7, 8}},
+		{"abs(-close)", rising, []float64{1, 2, 3, 4, 5}},
+	}
+
+	for _, tt := range tests {
+		got, err := Evaluate(tt.input, tt.candles, nil)
+		if err != nil {
+			t.Errorf("%q: %v", tt.input, err)
+			continue
+		}
+		if !sameSeries(got, tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateArityErrors(t *testing.T) {
+	candles := candlesWithCloses(1, 2, 3, 4, 5)
+	tests := []string{
+		"sma(close)",
+		"sma(close, 3, 1)",
+		"sma(close, 0)",
+		"sma(close, 2.5)",
+		"ema(9)",
+		"rsi()",
+		"rsi(close, 14, 1)",
+		"atr()",
+		"atr(close, 14)",
+		"macd(12)",
+		"kdj_k(9)",
+		"change()",
+		"change(close, 1, 2)",
+		"abs(close, open)",
+		"min(close)",
+		"highest(close, period)",
+		"unknown_fn(close)",
+		"unknown_series > 1",
+	}
+
+	for _, input := range tests {
+		_, err := Evaluate(input, candles, nil)
+		if err == nil {
+			t.Errorf("%q: expected an error", input)
+			continue
+		}
+		if !errors.Is(err, ErrInvalidExpression) {
+			t.Errorf("%q: error %v does not wrap ErrInvalidExpression", input, err)
+		}
+	}
+}
+
+func TestEvaluateSavedExpressions(t *testing.T) {
+	candles := candlesWithCloses(1, 2, 3, 4, 5)
+	dbErr := errors.New("database is locked")
+	saved := map[string]string{
+		"body":    "close - open",
+		"big":     "body * 10",
+		"loop":    "loop + 1",
+		"broken":  "close +",
+		"flaky":   "",
+		"twice_a": "twice_b",
+		"twice_b": "twice_a",
+	}
+	resolve := func(name string) (string, bool, error) {
+		if name == "flaky" {
+			return "", false, dbErr
+		}
+		source, ok := saved[name]
+		return source, ok, nil
+	}
+
+	got, err := Evaluate("big + body", candles, resolve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameSeries(got, []float64{5.5, 5.5, 5.5, 5.5, 5.5}) {
+		t.Errorf("big + body: got %v", got)
+	}
+
+	for _, input := range []string{"loop", "twice_a", "broken", "missing"} {
+		if _, err := Evaluate(input, candles, resolve); !errors.Is(err, ErrInvalidExpression) {
+			t.Errorf("%q: got %v, want ErrInvalidExpression", input, err)
+		}
+	}
+
+	// A failed lookup is reported as is, not as the caller's mistake
+	_, err = Evaluate("flaky > 0", candles, resolve)
+	if !errors.Is(err, dbErr) || errors.Is(err, ErrInvalidExpression) {
+		t.Errorf("flaky: got %v, want the resolver error", err)
+	}
+}
+
+func TestIsTrue(t *testing.T) {
+	if IsTrue(nil) || IsTrue([]float64{1, math.NaN()}) || IsTrue([]float64{1, 0}) {
+		t.Error("expected false for empty, NaN and zero last values")
+	}
+	if !IsTrue([]float64{0, 1}) {
+		t.Error("expected true for a non-zero last value")
+	}
+}
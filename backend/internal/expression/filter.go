@@ -0,0 +1,476 @@
+package expression
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidFilter wraps every error caused by the filter or sort field itself rather than the documents
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// errUnknownField marks a field no document has, which Bind may still resolve to a saved expression
+var errUnknownField = errors.New("unknown field")
+
+// Filter is a condition on JSON documents written in the expression language:
+// "market_quality.overall_score > 70 and trend.direction = 上升". Identifiers are field paths (see
+// ResolveField) or, when no field has the name, saved expressions; quoted text and words outside ASCII
+// (上升, 看涨) are text. Text compares case-insensitively with == and !=, booleans count as 1 and 0, and
+// a comparison with a missing or null field is unknown, so a document only matches when the whole
+// condition is known to be true.
+type Filter struct {
+	node   Node
+	fields map[string]string // Field path as written -> path from the document root, set by Bind
+	saved  map[string]bool   // Identifiers naming saved expressions, set by Bind
+}
+
+// SavedLookup reports whether a saved expression with the given name exists
+type SavedLookup func(name string) (bool, error)
+
+// SavedValue returns the latest value of a saved expression for the document being matched; NaN
+// when it is undefined there
+type SavedValue func(name string) (float64, error)
+
+// ParseFilter parses a filter; an empty input matches everything
+func ParseFilter(input string) (*Filter, error) {
+	filter := &Filter{fields: make(map[string]string), saved: make(map[string]bool)}
+	if strings.TrimSpace(input) == "" {
+		return filter, nil
+	}
+
+	node, err := parse(input)
+	if err == nil {
+		err = checkFilterNode(node)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+	}
+	filter.node = node
+	return filter, nil
+}
+
+// checkFilterNode rejects the series-only parts of the language
+func checkFilterNode(node Node) error {
+	switch n := node.(type) {
+	case *unaryNode:
+		return checkFilterNode(n.operand)
+	case *binaryNode:
+		if strings.HasPrefix(n.op, "crosses") {
+			return fmt.Errorf("%q needs a series and is not supported in filters", n.op)
+		}
+		if err := checkFilterNode(n.left); err != nil {
+			return err
+		}
+		return checkFilterNode(n.right)
+	case *callNode:
+		return fmt.Errorf("function %s() is not supported in filters", n.name)
+	case *indexNode:
+		return fmt.Errorf("lookback %s is not supported in filters", n)
+	}
+	return nil
+}
+
+// Fields returns the field paths the filter references, in order of appearance
+func (f *Filter) Fields() []string {
+	fields := make([]string, 0)
+	seen := make(map[string]bool)
+	var walk func(Node)
+	walk = func(node Node) {
+		switch n := node.(type) {
+		case *identNode:
+			if isFieldName(n.name) && !seen[n.name] {
+				seen[n.name] = true
+				fields = append(fields, n.name)
+			}
+		case *unaryNode:
+			walk(n.operand)
+		case *binaryNode:
+			walk(n.left)
+			walk(n.right)
+		}
+	}
+	if f.node != nil {
+		walk(f.node)
+	}
+	return fields
+}
+
+// Bind resolves every identifier against the documents, then against the saved expressions when
+// lookup is set, rejecting unknown and ambiguous fields. Call it before Match.
+func (f *Filter) Bind(docs []map[string]interface{}, lookup SavedLookup) error {
+	for _, field := range f.Fields() {
+		path, err := ResolveField(docs, field)
+		if err == nil {
+			f.fields[field] = path
+			continue
+		}
+		if lookup == nil || ValidateName(field) != nil || !errors.Is(err, errUnknownField) {
+			return err
+		}
+		found, lookupErr := lookup(field)
+		if lookupErr != nil {
+			return lookupErr
+		}
+		if !found {
+			return fmt.Errorf("%w: %q is neither a field nor a saved expression", ErrInvalidFilter, field)
+		}
+		f.saved[field] = true
+	}
+	return nil
+}
+
+// Match reports whether the document satisfies the filter; values evaluates the saved expressions the
+// filter references for this document
+func (f *Filter) Match(doc map[string]interface{}, values SavedValue) (bool, error) {
+	if f.node == nil {
+		return true, nil
+	}
+	value, err := (&filterEval{filter: f, doc: doc, values: values, cache: make(map[string]interface{})}).eval(f.node)
+	if err != nil {
+		return false, err
+	}
+	matched, err := truth(value)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s is not a condition", ErrInvalidFilter, f.node)
+	}
+	return matched != nil && *matched, nil
+}
+
+// filterEval evaluates a filter for one document, computing each saved expression once
+type filterEval struct {
+	filter *Filter
+	doc    map[string]interface{}
+	values SavedValue
+	cache  map[string]interface{}
+}
+
+// eval computes a node's value for the document: float64, string, bool, or nil when unknown
+func (e *filterEval) eval(node Node) (interface{}, error) {
+	switch n := node.(type) {
+	case *numberNode:
+		return n.value, nil
+	case *stringNode:
+		return n.value, nil
+	case *identNode:
+		return e.evalIdent(n.name)
+	case *unaryNode:
+		operand, err := e.eval(n.operand)
+		if err != nil || operand == nil {
+			return nil, err
+		}
+		if n.op == "not" {
+			b, err := truth(operand)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s is not a condition", ErrInvalidFilter, n.operand)
+			}
+			return !*b, nil
+		}
+		x, ok := number(operand)
+		if !ok {
+			return nil, fmt.Errorf("%w: cannot negate %s", ErrInvalidFilter, n.operand)
+		}
+		return -x, nil
+	case *binaryNode:
+		return e.evalBinary(n)
+	}
+	return nil, fmt.Errorf("%w: %s is not supported in filters", ErrInvalidFilter, node)
+}
+
+func (e *filterEval) evalIdent(name string) (interface{}, error) {
+	switch {
+	case name == "true":
+		return true, nil
+	case name == "false":
+		return false, nil
+	case !isFieldName(name):
+		return name, nil
+	}
+
+	if e.filter.saved[name] {
+		return e.evalSaved(name)
+	}
+	path, ok := e.filter.fields[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: field %q is not bound", ErrInvalidFilter, name)
+	}
+	value, _ := lookupPath(e.doc, path)
+	switch value.(type) {
+	case nil, float64, string, bool:
+		return value, nil
+	}
+	return nil, fmt.Errorf("%w: field %q is not a number, boolean or text", ErrInvalidFilter, name)
+}
+
+// evalSaved returns the latest value of a saved expression, or nil when it is undefined
+func (e *filterEval) evalSaved(name string) (interface{}, error) {
+	if value, ok := e.cache[name]; ok {
+		return value, nil
+	}
+	if e.values == nil {
+		return nil, fmt.Errorf("%w: saved expression %q cannot be evaluated here", ErrInvalidFilter, name)
+	}
+	x, err := e.values(name)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if !math.IsNaN(x) {
+		value = x
+	}
+	e.cache[name] = value
+	return value, nil
+}
+
+func (e *filterEval) evalBinary(n *binaryNode) (interface{}, error) {
+	left, err := e.eval(n.left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.eval(n.right)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "and" || n.op == "or" {
+		a, errA := truth(left)
+		b, errB := truth(right)
+		if errA != nil || errB != nil {
+			return nil, fmt.Errorf("%w: %s needs conditions on both sides", ErrInvalidFilter, n.op)
+		}
+		// Three-valued logic: a known false (and) or true (or) decides regardless of the unknown side
+		decisive := n.op == "or"
+		if (a != nil && *a == decisive) || (b != nil && *b == decisive) {
+			return decisive, nil
+		}
+		if a == nil || b == nil {
+			return nil, nil
+		}
+		return !decisive, nil
+	}
+
+	if left == nil || right == nil {
+		return nil, nil
+	}
+
+	textA, isTextA := left.(string)
+	textB, isTextB := right.(string)
+	if isTextA || isTextB {
+		if !isTextA || !isTextB {
+			return nil, fmt.Errorf("%w: %s compares text with a number", ErrInvalidFilter, n)
+		}
+		switch n.op {
+		case "==":
+			return strings.EqualFold(textA, textB), nil
+		case "!=":
+			return !strings.EqualFold(textA, textB), nil
+		}
+		return nil, fmt.Errorf("%w: text only supports == and !=, got %s", ErrInvalidFilter, n)
+	}
+
+	a, _ := number(left)
+	b, _ := number(right)
+	switch n.op {
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		if b == 0 {
+			return nil, nil
+		}
+		return a / b, nil
+	case ">":
+		return a > b, nil
+	case "<":
+		return a < b, nil
+	case ">=":
+		return a >= b, nil
+	case "<=":
+		return a <= b, nil
+	case "==":
+		return a == b, nil
+	case "!=":
+		return a != b, nil
+	}
+	return nil, fmt.Errorf("%w: unsupported operator %q", ErrInvalidFilter, n.op)
+}
+
+// truth converts a value to a condition: booleans as is, numbers true when non-zero, nil unknown
+func truth(value interface{}) (*bool, error) {
+	var b bool
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case bool:
+		b = v
+	case float64:
+		b = v != 0
+	default:
+		return nil, fmt.Errorf("%v is not a condition", value)
+	}
+	return &b, nil
+}
+
+// number converts a number or boolean (1 or 0) to float64
+func number(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// isFieldName reports whether an identifier is a field path rather than a word of text: field names
+// are ASCII, while the values compared against them (上升, 看涨) are not
+func isFieldName(name string) bool {
+	if name == "true" || name == "false" {
+		return false
+	}
+	for _, r := range name {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+// ResolveField returns the path from the document root of a field. A path that resolves from the root
+// of any document is used as is; otherwise it must end in exactly one nested field across the
+// documents, so "rsi14" finds "indicators.rsi.rsi14" while "direction" is rejected when several
+// objects have one. Arrays are not searched.
+func ResolveField(docs []map[string]interface{}, path string) (string, error) {
+	keys := strings.Split(path, ".")
+	matches := make(map[string]bool)
+	for _, doc := range docs {
+		if _, ok := lookupKeys(doc, keys); ok {
+			return path, nil
+		}
+		collectNestedMatches(doc, "", keys, matches)
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%w: %w %q", ErrInvalidFilter, errUnknownField, path)
+	case 1:
+		for match := range matches {
+			return match, nil
+		}
+	}
+
+	candidates := make([]string, 0, len(matches))
+	for match := range matches {
+		candidates = append(candidates, match)
+	}
+	sort.Strings(candidates)
+	return "", fmt.Errorf("%w: field %q is ambiguous, use one of %s", ErrInvalidFilter, path, strings.Join(candidates, ", "))
+}
+
+// collectNestedMatches records the full paths of every object below obj in which keys resolve
+func collectNestedMatches(obj map[string]interface{}, prefix string, keys []string, matches map[string]bool) {
+	for name, value := range obj {
+		child, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		childPath := prefix + name
+		if _, ok := lookupKeys(child, keys); ok {
+			matches[childPath+"."+strings.Join(keys, ".")] = true
+		}
+		collectNestedMatches(child, childPath+".", keys, matches)
+	}
+}
+
+// lookupPath resolves a dotted path from the document root
+func lookupPath(doc map[string]interface{}, path string) (interface{}, bool) {
+	return lookupKeys(doc, strings.Split(path, "."))
+}
+
+// lookupKeys follows keys from obj
+func lookupKeys(obj map[string]interface{}, keys []string) (interface{}, bool) {
+	var current interface{} = obj
+	for _, key := range keys {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// Document converts a value into a generic JSON document for filtering and sorting
+func Document(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	doc := make(map[string]interface{})
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// CompareFields orders two documents by the value at a path from the root (see ResolveField),
+// ascending or descending: numbers numerically, text lexically, booleans false first. Missing and
+// null values sort last in either direction. Returns -1, 0 or 1.
+func CompareFields(a, b map[string]interface{}, path string, descending bool) int {
+	va, okA := lookupPath(a, path)
+	vb, okB := lookupPath(b, path)
+	okA = okA && va != nil
+	okB = okB && vb != nil
+	switch {
+	case !okA && !okB:
+		return 0
+	case !okA:
+		return 1
+	case !okB:
+		return -1
+	}
+
+	cmp := 0
+	switch x := va.(type) {
+	case float64:
+		if y, ok := vb.(float64); ok {
+			cmp = compareOrdered(x, y)
+		}
+	case string:
+		if y, ok := vb.(string); ok {
+			cmp = compareOrdered(x, y)
+		}
+	case bool:
+		if y, ok := vb.(bool); ok && x != y {
+			cmp = -1
+			if x {
+				cmp = 1
+			}
+		}
+	}
+	if descending {
+		return -cmp
+	}
+	return cmp
+}
+
+func compareOrdered[T float64 | string](a, b T) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
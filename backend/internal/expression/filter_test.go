@@ -0,0 +1,269 @@
+package expression
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// filterDocs mirrors the shape of analysis results: nested objects, text, booleans and nulls
+func filterDocs() []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"symbol":          "BTCUSDT",
+			"trend":           map[string]interface{}{"direction": "上升", "strength": 80.0},
+			"market_quality":  map[string]interface{}{"overall_score": 75.0},
+			"indicators":      map[string]interface{}{"rsi": map[string]interface{}{"rsi14": 28.0}},
+			"regime":          map[string]interface{}{"type": "TRENDING_UP", "direction": "UP"},
+			"relative_volume": nil,
+			"breakout":        true,
+		},
+		{
+			"symbol":         "ETHUSDT",
+			"trend":          map[string]interface{}{"direction": "下降", "strength": 60.0},
+			"market_quality": map[string]interface{}{"overall_score": 55.0},
+			"indicators":     map[string]interface{}{"rsi": map[string]interface{}{"rsi14": 45.0}},
+			"regime":         map[string]interface{}{"type": "RANGING", "direction": "FLAT"},
+			"breakout":       false,
+		},
+		{
+			"symbol":     "SOLUSDT",
+			"trend":      map[string]interface{}{"direction": "上升", "strength": 65.0},
+			"indicators": map[string]interface{}{"rsi": map[string]interface{}{"rsi14": 71.0}},
+			"regime":     map[string]interface{}{"type": "TRENDING_UP", "direction": "UP"},
+			"breakout":   true,
+		},
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	docs := filterDocs()
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{"", []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"}},
+		{"market_quality.overall_score > 70", []string{"BTCUSDT"}},
+		{"trend.direction = 上升", []string{"BTCUSDT", "SOLUSDT"}},
+		{"trend.direction != 上升", []string{"ETHUSDT"}},
+		{"RSI14 < 30", []string{"BTCUSDT"}},
+		{"rsi14 < 30 or rsi14 > 70", []string{"BTCUSDT", "SOLUSDT"}},
+		{"trend.direction == '上升' && trend.strength >= 70", []string{"BTCUSDT"}},
+		{`regime.type == "trending_up"`, []string{"BTCUSDT", "SOLUSDT"}},
+		{"breakout", []string{"BTCUSDT", "SOLUSDT"}},
+		{"not breakout", []string{"ETHUSDT"}},
+		{"breakout == true and trend.strength * 2 > 150", []string{"BTCUSDT"}},
+		{"(trend.strength - 60) / 5 >= 1", []string{"BTCUSDT", "SOLUSDT"}},
+		// SOLUSDT has no overall score: unknown, so neither the condition nor its negation matches
+		{"overall_score < 60", []string{"ETHUSDT"}},
+		{"not (overall_score >= 60)", []string{"ETHUSDT"}},
+		// A known true side of "or" (or false side of "and") decides despite the unknown side
+		{"overall_score > 70 or trend.strength < 70", []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"}},
+		{"overall_score > 70 and trend.strength > 90", nil},
+		{"relative_volume > 1", nil},
+	}
+
+	for _, tt := range tests {
+		filter, err := ParseFilter(tt.filter)
+		if err != nil {
+			t.Errorf("%q: %v", tt.filter, err)
+			continue
+		}
+		if err := filter.Bind(docs, nil); err != nil {
+			t.Errorf("%q: bind: %v", tt.filter, err)
+			continue
+		}
+
+		var got []string
+		for _, doc := range docs {
+			ok, err := filter.Match(doc, nil)
+			if err != nil {
+				t.Errorf("%q: %v", tt.filter, err)
+				break
+			}
+			if ok {
+				got = append(got, doc["symbol"].(string))
+			}
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%q: matched %v, want %v", tt.filter, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%q: matched %v, want %v", tt.filter, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestFilterSavedExpressions(t *testing.T) {
+	docs := filterDocs()
+	// Latest values of the saved expressions per symbol; NaN is undefined
+	values := map[string]map[string]float64{
+		"BTCUSDT": {"spread": 2, "squeeze": 1},
+		"ETHUSDT": {"spread": -1, "squeeze": 0},
+		"SOLUSDT": {"spread": math.NaN(), "squeeze": 1},
+	}
+	lookup := func(name string) (bool, error) { return name == "spread" || name == "squeeze", nil }
+
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{"spread > 0", []string{"BTCUSDT"}},
+		{"squeeze and trend.direction = 上升", []string{"BTCUSDT", "SOLUSDT"}},
+		{"spread < 0 or squeeze", []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"}},
+		{"not (spread > 0)", []string{"ETHUSDT"}},
+	}
+	for _, tt := range tests {
+		filter, err := ParseFilter(tt.filter)
+		if err == nil {
+			err = filter.Bind(docs, lookup)
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.filter, err)
+			continue
+		}
+
+		var got []string
+		for _, doc := range docs {
+			symbol := doc["symbol"].(string)
+			calls := 0
+			ok, err := filter.Match(doc, func(name string) (float64, error) {
+				calls++
+				return values[symbol][name], nil
+			})
+			if err != nil {
+				t.Errorf("%q: %v", tt.filter, err)
+				break
+			}
+			if calls > 2 {
+				t.Errorf("%q: %d evaluations for %s, want each saved expression once", tt.filter, calls, symbol)
+			}
+			if ok {
+				got = append(got, symbol)
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%q: matched %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+
+	// Fields take precedence over saved expressions, and names that are neither are rejected
+	saved := func(name string) (bool, error) { return name == "breakout", nil }
+	filter, _ := ParseFilter("breakout")
+	if err := filter.Bind(docs, saved); err != nil {
+		t.Fatalf("bind: %v", err)
+	}
+	if ok, err := filter.Match(docs[0], nil); err != nil || !ok {
+		t.Errorf("breakout: %v %v, want the field to match", ok, err)
+	}
+	filter, _ = ParseFilter("missing > 1")
+	if err := filter.Bind(docs, saved); !errors.Is(err, ErrInvalidFilter) {
+		t.Errorf("bind: %v, want an ErrInvalidFilter for missing", err)
+	}
+	failing := errors.New("database is down")
+	filter, _ = ParseFilter("spread > 0")
+	if err := filter.Bind(docs, func(string) (bool, error) { return false, failing }); !errors.Is(err, failing) {
+		t.Errorf("bind: %v, want the lookup error", err)
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	docs := filterDocs()
+	tests := []struct {
+		filter string
+		stage  string // Where the error is reported: parse, bind or match
+	}{
+		{"overall_score >", "parse"},
+		{"1 < 2 < 3", "parse"},
+		{"rsi(14) < 30", "parse"},
+		{"close crosses above 30", "parse"},
+		{"rsi14[1] < 30", "parse"},
+		{"'unterminated", "parse"},
+		{"missing_field > 1", "bind"},
+		{"direction = UP", "bind"}, // trend.direction and regime.direction
+		{"trend.direction > 上升", "match"},
+		{"trend.strength == 上升", "match"},
+		{"trend.direction", "match"},
+		{"trend.direction and breakout", "match"},
+	}
+
+	for _, tt := range tests {
+		filter, err := ParseFilter(tt.filter)
+		stage := "parse"
+		if err == nil {
+			stage = "bind"
+			err = filter.Bind(docs, nil)
+		}
+		if err == nil {
+			stage = "match"
+			_, err = filter.Match(docs[0], nil)
+		}
+		if err == nil {
+			t.Errorf("%q: expected an error", tt.filter)
+			continue
+		}
+		if stage != tt.stage || !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("%q: %s error %v, want an ErrInvalidFilter at %s", tt.filter, stage, err, tt.stage)
+		}
+	}
+}
+
+func TestResolveField(t *testing.T) {
+	docs := filterDocs()
+	tests := map[string]string{
+		"symbol":                       "symbol",
+		"trend.direction":              "trend.direction",
+		"rsi14":                        "indicators.rsi.rsi14",
+		"rsi.rsi14":                    "indicators.rsi.rsi14",
+		"overall_score":                "market_quality.overall_score",
+		"market_quality.overall_score": "market_quality.overall_score",
+	}
+	for path, want := range tests {
+		got, err := ResolveField(docs, path)
+		if err != nil || got != want {
+			t.Errorf("ResolveField(%q) = %q, %v, want %q", path, got, err, want)
+		}
+	}
+
+	if _, err := ResolveField(docs, "direction"); !errors.Is(err, ErrInvalidFilter) {
+		t.Errorf("direction: got %v, want an ambiguity error", err)
+	}
+}
+
+func TestCompareFields(t *testing.T) {
+	docs := filterDocs()
+	order := func(path string, descending bool) []string {
+		sorted := append([]map[string]interface{}(nil), docs...)
+		sort.SliceStable(sorted, func(i, j int) bool { return CompareFields(sorted[i], sorted[j], path, descending) < 0 })
+		symbols := make([]string, len(sorted))
+		for i, doc := range sorted {
+			symbols[i] = doc["symbol"].(string)
+		}
+		return symbols
+	}
+
+	tests := []struct {
+		path       string
+		descending bool
+		want       [3]string
+	}{
+		{"indicators.rsi.rsi14", false, [3]string{"BTCUSDT", "ETHUSDT", "SOLUSDT"}},
+		{"indicators.rsi.rsi14", true, [3]string{"SOLUSDT", "ETHUSDT", "BTCUSDT"}},
+		// Missing values sort last both ways
+		{"market_quality.overall_score", false, [3]string{"ETHUSDT", "BTCUSDT", "SOLUSDT"}},
+		{"market_quality.overall_score", true, [3]string{"BTCUSDT", "ETHUSDT", "SOLUSDT"}},
+		{"breakout", false, [3]string{"ETHUSDT", "BTCUSDT", "SOLUSDT"}},
+	}
+	for _, tt := range tests {
+		got := order(tt.path, tt.descending)
+		if [3]string(got) != tt.want {
+			t.Errorf("%s descending=%v: got %v, want %v", tt.path, tt.descending, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package expression
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind identifies the lexical class of a token
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+	tokenComma
+)
+
+// token represents a single lexical token
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+	pos  int
+}
+
+// tokenize splits an expression into tokens
+func tokenize(input string) ([]token, error) {
+	tokens := make([]token, 0)
+	runes := []rune(input)
+	i := 0
+
+	for i < len(runes) {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			text := string(runes[start:i])
+			num, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", text, start)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, num: num, pos: start})
+
+		case unicode.IsLetter(r) || r == '_':
+			// Identifiers may be dotted paths (market_quality.overall_score), used by filters
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' ||
+				(runes[i] == '.' && i+1 < len(runes) && (unicode.IsLetter(runes[i+1]) || runes[i+1] == '_'))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: strings.ToLower(string(runes[start:i])), pos: start})
+
+		case r == '"' || r == '\'':
+			start := i
+			i++
+			for i < len(runes) && runes[i] != r {
+				i++
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("unterminated text at position %d", start)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: string(runes[start+1 : i]), pos: start})
+			i++
+
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case r == '[':
+			tokens = append(tokens, token{kind: tokenLBracket, text: "[", pos: i})
+			i++
+		case r == ']':
+			tokens = append(tokens, token{kind: tokenRBracket, text: "]", pos: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: i})
+			i++
+
+		case strings.ContainsRune("+-*/<>=!&|", r):
+			start := i
+			op := string(r)
+			if i+1 < len(runes) {
+				two := string(runes[i : i+2])
+				switch two {
+				case ">=", "<=", "==", "!=", "&&", "||":
+					op = two
+				}
+			}
+			if op == "&" || op == "|" {
+				return nil, fmt.Errorf("unexpected %q at position %d", op, start)
+			}
+			i += len([]rune(op))
+			if op == "=" {
+				op = "=="
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: start})
+
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(runes)})
+	return tokens, nil
+}
@@ -0,0 +1,395 @@
+package expression
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrInvalidExpression wraps every error caused by the expression itself: syntax, unknown names, arity
+var ErrInvalidExpression = errors.New("invalid expression")
+
+// ErrInvalidName wraps every reason a saved expression name is rejected
+var ErrInvalidName = errors.New("invalid name")
+
+// Node is a parsed expression tree node
+type Node interface {
+	String() string
+}
+
+// numberNode is a numeric literal
+type numberNode struct {
+	value float64
+}
+
+// stringNode is quoted text, only meaningful in filters
+type stringNode struct {
+	value string
+}
+
+// identNode is a series name (close, volume, ...) or a saved expression reference
+type identNode struct {
+	name string
+}
+
+// callNode is a built-in function call
+type callNode struct {
+	name string
+	args []Node
+}
+
+// unaryNode is a prefix operator ("-" or "not")
+type unaryNode struct {
+	op      string
+	operand Node
+}
+
+// binaryNode is an infix operator, including "crosses above" / "crosses below"
+type binaryNode struct {
+	op          string
+	left, right Node
+}
+
+// indexNode looks back Offset bars on a series (close[1] is the previous close)
+type indexNode struct {
+	series Node
+	offset int
+}
+
+func (n *numberNode) String() string { return fmt.Sprintf("%g", n.value) }
+func (n *stringNode) String() string { return strconv.Quote(n.value) }
+func (n *identNode) String() string  { return n.name }
+func (n *unaryNode) String() string  { return fmt.Sprintf("(%s %s)", n.op, n.operand) }
+func (n *binaryNode) String() string { return fmt.Sprintf("(%s %s %s)", n.left, n.op, n.right) }
+func (n *indexNode) String() string  { return fmt.Sprintf("%s[%d]", n.series, n.offset) }
+func (n *callNode) String() string {
+	s := n.name + "("
+	for i, arg := range n.args {
+		if i > 0 {
+			s += ", "
+		}
+		s += arg.String()
+	}
+	return s + ")"
+}
+
+// parser is a recursive-descent parser over the token stream
+//
+//	or         := and ( ("or" | "||") and )*
+//	and        := not ( ("and" | "&&") not )*
+//	not        := ("not" | "!") not | comparison
+//	comparison := additive ( compOp additive | "crosses" ("above" | "below") additive )?  (not chained)
+//	additive   := multiply ( ("+" | "-") multiply )*
+//	multiply   := unary ( ("*" | "/") unary )*
+//	unary      := "-" unary | postfix
+//	postfix    := primary ( "[" NUMBER "]" )*
+//	primary    := NUMBER | STRING | IDENT | IDENT "(" args ")" | "(" or ")"
+//	compOp     := ">" | "<" | ">=" | "<=" | "==" | "=" | "!="    ("=" is read as "==")
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Parse parses an expression into a tree
+func Parse(input string) (Node, error) {
+	node, err := parse(input)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExpression, err)
+	}
+	return node, nil
+}
+
+// parse parses an expression, returning unwrapped syntax errors
+func parse(input string) (Node, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 1 {
+		return nil, fmt.Errorf("empty expression")
+	}
+
+	p := &parser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		tok := p.peek()
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+	return node, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) isKeyword(word string) bool {
+	tok := p.peek()
+	return tok.kind == tokenIdent && tok.text == word
+}
+
+func (p *parser) isOperator(ops ...string) bool {
+	tok := p.peek()
+	if tok.kind != tokenOperator {
+		return false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) expect(kind tokenKind, text string) error {
+	tok := p.next()
+	if tok.kind != kind {
+		if tok.kind == tokenEOF {
+			return fmt.Errorf("expected %q at end of expression", text)
+		}
+		return fmt.Errorf("expected %q at position %d, got %q", text, tok.pos, tok.text)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") || p.isOperator("||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") || p.isOperator("&&") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (Node, error) {
+	if p.isKeyword("not") || p.isOperator("!") {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: "not", operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+// comparisonOps are the infix comparison operators
+var comparisonOps = []string{">", "<", ">=", "<=", "==", "!="}
+
+func (p *parser) parseComparison() (Node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.isOperator(comparisonOps...) {
+		op := p.next().text
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryNode{op: op, left: left, right: right}, p.checkUnchained()
+	}
+
+	if p.isKeyword("crosses") {
+		p.next()
+		direction := p.next()
+		if direction.kind != tokenIdent || (direction.text != "above" && direction.text != "below") {
+			return nil, fmt.Errorf("expected \"above\" or \"below\" after \"crosses\" at position %d", direction.pos)
+		}
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryNode{op: "crosses " + direction.text, left: left, right: right}, p.checkUnchained()
+	}
+
+	return left, nil
+}
+
+// checkUnchained rejects a second comparison right after one ("1 < 2 < 3"), which would otherwise
+// compare a boolean with a number
+func (p *parser) checkUnchained() error {
+	if p.isOperator(comparisonOps...) || p.isKeyword("crosses") {
+		return fmt.Errorf("comparisons cannot be chained at position %d, join them with \"and\"", p.peek().pos)
+	}
+	return nil
+}
+
+func (p *parser) parseAdditive() (Node, error) {
+	left, err := p.parseMultiply()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("+", "-") {
+		op := p.next().text
+		right, err := p.parseMultiply()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseMultiply() (Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("*", "/") {
+		op := p.next().text
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	if p.isOperator("-") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: "-", operand: operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (Node, error) {
+	node, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenLBracket {
+		p.next()
+		tok := p.next()
+		if tok.kind != tokenNumber || tok.num < 0 || tok.num != float64(int(tok.num)) {
+			return nil, fmt.Errorf("lookback index must be a non-negative integer at position %d", tok.pos)
+		}
+		if err := p.expect(tokenRBracket, "]"); err != nil {
+			return nil, err
+		}
+		node = &indexNode{series: node, offset: int(tok.num)}
+	}
+
+	return node, nil
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenNumber:
+		return &numberNode{value: tok.num}, nil
+
+	case tokenString:
+		return &stringNode{value: tok.text}, nil
+
+	case tokenIdent:
+		if p.peek().kind != tokenLParen {
+			return &identNode{name: tok.text}, nil
+		}
+		p.next()
+
+		args := make([]Node, 0)
+		if p.peek().kind != tokenRParen {
+			for {
+				arg, err := p.parseOr()
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+				if p.peek().kind != tokenComma {
+					break
+				}
+				p.next()
+			}
+		}
+		if err := p.expect(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return &callNode{name: tok.text, args: args}, nil
+
+	case tokenLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return node, nil
+
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+}
+
+// reservedNames cannot be used as saved expression names
+var reservedNames = map[string]bool{
+	"open": true, "high": true, "low": true, "close": true, "volume": true, "hl2": true, "hlc3": true,
+	"true": true, "false": true, "and": true, "or": true, "not": true, "crosses": true, "above": true, "below": true,
+	"sma": true, "ema": true, "rsi": true, "atr": true, "macd": true, "macd_signal": true, "macd_hist": true,
+	"kdj_k": true, "kdj_d": true, "kdj_j": true, "highest": true, "lowest": true, "change": true,
+	"abs": true, "min": true, "max": true,
+}
+
+// ValidateName checks that a saved expression name is a usable identifier
+func ValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidName)
+	}
+	for i, r := range name {
+		isLetter := (r >= 'a' && r <= 'z') || r == '_'
+		isDigit := r >= '0' && r <= '9'
+		if !isLetter && !(isDigit && i > 0) {
+			return fmt.Errorf("%w: name must be lowercase letters, digits and underscores, starting with a letter", ErrInvalidName)
+		}
+	}
+	if reservedNames[name] {
+		return fmt.Errorf("%w: name %q is reserved", ErrInvalidName, name)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package expression
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParsePrecedence(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"1 + 2 * 3", "(1 + (2 * 3))"},
+		{"(1 + 2) * 3", "((1 + 2) * 3)"},
+		{"1 - 2 - 3", "((1 - 2) - 3)"},
+		{"8 / 4 / 2", "((8 / 4) / 2)"},
+		{"-close[1] * 2", "((- close[1]) * 2)"},
+		{"close[1][2]", "close[1][2]"},
+		{"close > open and volume > 1000", "((close > open) and (volume > 1000))"},
+		{"a or b and c", "(a or (b and c))"},
+		{"A && B || C", "((a and b) or c)"},
+		{"not a and b", "((not a) and b)"},
+		{"!(a or b)", "(not (a or b))"},
+		{"close + 1 > open * 2", "((close + 1) > (open * 2))"},
+		{"ema(close, 9) crosses above ema(close, 21)", "(ema(close, 9) crosses above ema(close, 21))"},
+		{"rsi(14) crosses below 30 or rsi(14) > 70", "((rsi(14) crosses below 30) or (rsi(14) > 70))"},
+	}
+
+	for _, tt := range tests {
+		node, err := Parse(tt.input)
+		if err != nil {
+			t.Errorf("%q: %v", tt.input, err)
+			continue
+		}
+		if got := node.String(); got != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"1 +",
+		"(1 + 2",
+		"1 < 2 < 3",
+		"close > open == 1",
+		"close crosses above open crosses below high",
+		"close crosses sideways open",
+		"close[1.5]",
+		"close[-1]",
+		"close[1",
+		"sma(close, 9",
+		"close $ open",
+		"close open",
+	}
+
+	for _, input := range tests {
+		_, err := Parse(input)
+		if err == nil {
+			t.Errorf("%q: expected an error", input)
+			continue
+		}
+		if !errors.Is(err, ErrInvalidExpression) {
+			t.Errorf("%q: error %v does not wrap ErrInvalidExpression", input, err)
+		}
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"golden_cross", "rsi_low2", "x"} {
+		if err := ValidateName(name); err != nil {
+			t.Errorf("%q: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "2fast", "Upper", "with-dash", "close", "ema"} {
+		if err := ValidateName(name); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("%q: got %v, want ErrInvalidName", name, err)
+		}
+	}
+}
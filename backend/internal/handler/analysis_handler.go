@@ -1,22 +1,28 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kudaompq/ai_trending/backend/internal/indicator"
 	"github.com/kudaompq/ai_trending/backend/internal/service"
 )
 
 // AnalysisHandler handles analysis related requests
 type AnalysisHandler struct {
 	analysisService *service.AnalysisService
+	mtfService      *service.MTFService
 }
 
 // NewAnalysisHandler creates a new analysis handler
 func NewAnalysisHandler() *AnalysisHandler {
 	return &AnalysisHandler{
 		analysisService: service.NewAnalysisService(),
+		mtfService:      service.NewMTFService(),
 	}
 }
 
@@ -24,16 +30,22 @@ func NewAnalysisHandler() *AnalysisHandler {
 func (h *AnalysisHandler) GetAnalysis(c *gin.Context) {
 	symbol := c.DefaultQuery("symbol", "ETHUSDT")
 	interval := c.DefaultQuery("interval", "1d")
-	limitStr := c.DefaultQuery("limit", "100")
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 || limit > 500 {
-		limit = 100
+	opts, err := analysisOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
 	}
 
-	result, err := h.analysisService.PerformAnalysis(symbol, interval, limit)
+	result, err := h.analysisService.PerformAnalysisWithOptions(symbol, interval, analysisLimit(c), opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidBenchmark) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
 			"error": err.Error(),
 		})
 		return
@@ -41,3 +53,100 @@ func (h *AnalysisHandler) GetAnalysis(c *gin.Context) {
 
 	c.JSON(http.StatusOK, result)
 }
+
+// GetMTFAnalysis handles GET /api/analysis/mtf
+func (h *AnalysisHandler) GetMTFAnalysis(c *gin.Context) {
+	symbol := c.DefaultQuery("symbol", "ETHUSDT")
+	intervals := service.DefaultMTFIntervals
+	if requested := c.Query("intervals"); requested != "" {
+		intervals = strings.Split(requested, ",")
+	}
+
+	opts, err := analysisOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.mtfService.Analyze(symbol, intervals, analysisLimit(c), opts)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidInterval) || errors.Is(err, service.ErrInvalidBenchmark) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// analysisLimit reads the candle limit, defaulting to 100
+func analysisLimit(c *gin.Context) int {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return limit
+}
+
+// analysisOptions reads the optional analysis settings from the query string
+func analysisOptions(c *gin.Context) (service.AnalysisOptions, error) {
+	opts := service.DefaultAnalysisOptions()
+	opts.VolumeProfile.Mode = c.DefaultQuery("vp_mode", opts.VolumeProfile.Mode)
+	switch opts.VolumeProfile.Mode {
+	case "fixed", "session", "visible":
+	default:
+		return opts, fmt.Errorf("invalid vp_mode %q, want fixed, session or visible", opts.VolumeProfile.Mode)
+	}
+	if swingMode := c.Query("swing_mode"); swingMode != "" {
+		opts.Swings.Mode = indicator.SwingMode(strings.ToUpper(swingMode))
+		switch opts.Swings.Mode {
+		case indicator.SwingModeFractal, indicator.SwingModePercent, indicator.SwingModeATR:
+		default:
+			return opts, fmt.Errorf("invalid swing_mode %q, want fractal, percent or atr", swingMode)
+		}
+	}
+	if swingStrength, err := strconv.Atoi(c.Query("swing_strength")); err == nil && swingStrength > 0 && swingStrength <= 10 {
+		opts.Swings.Strength = swingStrength
+	}
+	if swingPercent, err := strconv.ParseFloat(c.Query("swing_percent"), 64); err == nil && swingPercent > 0 && swingPercent < 1 {
+		opts.Swings.Percent = swingPercent
+	}
+	if swingATR, err := strconv.ParseFloat(c.Query("swing_atr"), 64); err == nil && swingATR > 0 {
+		opts.Swings.ATRMultiple = swingATR
+	}
+	if vpLookback, err := strconv.Atoi(c.Query("vp_lookback")); err == nil && vpLookback > 0 {
+		opts.VolumeProfile.Lookback = vpLookback
+	}
+	if vpBins, err := strconv.Atoi(c.Query("vp_bins")); err == nil && vpBins > 0 && vpBins <= 200 {
+		opts.VolumeProfile.Bins = vpBins
+	}
+	if patternScan, err := strconv.ParseBool(c.Query("pattern_scan")); err == nil {
+		opts.PatternScan = patternScan
+	}
+	if empirical, err := strconv.ParseBool(c.Query("empirical_reliability")); err == nil {
+		opts.EmpiricalReliability = empirical
+	}
+	if srTimeframes := c.Query("sr_timeframes"); srTimeframes != "" {
+		opts.SRTimeframes = strings.Split(srTimeframes, ",")
+	}
+	if sessions := c.Query("sessions"); sessions != "" {
+		defs, err := indicator.ParseSessions(sessions)
+		if err != nil {
+			return opts, err
+		}
+		opts.Sessions = defs
+	}
+	opts.Benchmark = strings.ToUpper(strings.TrimSpace(c.Query("benchmark")))
+	if rsWatchlist := c.Query("rs_watchlist"); rsWatchlist != "" {
+		for _, symbol := range strings.Split(rsWatchlist, ",") {
+			opts.RSWatchlist = append(opts.RSWatchlist, strings.ToUpper(strings.TrimSpace(symbol)))
+		}
+	}
+	return opts, nil
+}
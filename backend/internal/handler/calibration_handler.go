@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kudaompq/ai_trending/backend/internal/service"
+)
+
+// CalibrationHandler handles pattern reliability calibration requests
+type CalibrationHandler struct {
+	calibrationService *service.CalibrationService
+}
+
+// NewCalibrationHandler creates a new calibration handler
+func NewCalibrationHandler() *CalibrationHandler {
+	return &CalibrationHandler{
+		calibrationService: service.NewCalibrationService(),
+	}
+}
+
+// calibrationRequest is the body of POST /api/calibration/run
+type calibrationRequest struct {
+	Symbol   string `json:"symbol"`
+	Interval string `json:"interval"`
+	Horizon  int    `json:"horizon"` // Forward bars per occurrence
+	Bars     int    `json:"bars"`    // Stored history to scan
+	Sync     bool   `json:"sync"`    // Download history from Binance first
+}
+
+// RunCalibration handles POST /api/calibration/run
+func (h *CalibrationHandler) RunCalibration(c *gin.Context) {
+	var req calibrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	if req.Symbol == "" {
+		req.Symbol = "ETHUSDT"
+	}
+	if req.Interval == "" {
+		req.Interval = "1h"
+	}
+	if req.Horizon <= 0 || req.Horizon > 200 {
+		req.Horizon = 0
+	}
+	if req.Bars <= 0 || req.Bars > 20000 {
+		req.Bars = 5000
+	}
+
+	if req.Sync {
+		if _, err := h.calibrationService.SyncHistory(req.Symbol, req.Interval, req.Bars); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "History sync failed: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	result, err := h.calibrationService.Calibrate(req.Symbol, req.Interval, req.Horizon, req.Bars)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetCalibration handles GET /api/calibration
+func (h *CalibrationHandler) GetCalibration(c *gin.Context) {
+	symbol := c.DefaultQuery("symbol", "ETHUSDT")
+	interval := c.DefaultQuery("interval", "1h")
+
+	stats, err := h.calibrationService.GetStats(symbol, interval)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"symbol":   symbol,
+		"interval": interval,
+		"stats":    stats,
+	})
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kudaompq/ai_trending/backend/internal/service"
+)
+
+// CorrelationHandler handles cross-symbol correlation requests
+type CorrelationHandler struct {
+	correlationService *service.CorrelationService
+}
+
+// NewCorrelationHandler creates a new correlation handler
+func NewCorrelationHandler() *CorrelationHandler {
+	return &CorrelationHandler{
+		correlationService: service.NewCorrelationService(),
+	}
+}
+
+// GetCorrelation handles GET /api/correlation
+func (h *CorrelationHandler) GetCorrelation(c *gin.Context) {
+	symbols := strings.Split(strings.ToUpper(c.DefaultQuery("symbols", "ETHUSDT,SOLUSDT,BNBUSDT")), ",")
+	for i := range symbols {
+		symbols[i] = strings.TrimSpace(symbols[i])
+	}
+	benchmark := strings.ToUpper(strings.TrimSpace(c.DefaultQuery("benchmark", "BTCUSDT")))
+	interval := c.DefaultQuery("interval", "1h")
+
+	window, err := strconv.Atoi(c.DefaultQuery("window", "30"))
+	if err != nil || window < 5 || window > 500 {
+		window = 30
+	}
+	// Four windows of history by default, within the 1500 candles a request may load
+	defaultBars := min(window*4, 1500)
+	bars, err := strconv.Atoi(c.DefaultQuery("bars", strconv.Itoa(defaultBars)))
+	if err != nil || bars <= window || bars > 1500 {
+		bars = defaultBars
+	}
+
+	result, err := h.correlationService.ComputeMatrix(symbols, benchmark, interval, window, bars)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidInterval) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kudaompq/ai_trending/backend/internal/expression"
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+	"github.com/kudaompq/ai_trending/backend/internal/repository"
+	"github.com/kudaompq/ai_trending/backend/internal/service"
+)
+
+// ExpressionHandler handles custom expression requests
+type ExpressionHandler struct {
+	expressionService *service.ExpressionService
+}
+
+// NewExpressionHandler creates a new expression handler
+func NewExpressionHandler() *ExpressionHandler {
+	return &ExpressionHandler{
+		expressionService: service.NewExpressionService(),
+	}
+}
+
+// evaluateRequest is the body of POST /api/expressions/evaluate
+type evaluateRequest struct {
+	Symbol     string `json:"symbol"`
+	Interval   string `json:"interval"`
+	Limit      int    `json:"limit"`
+	Expression string `json:"expression"`
+	Name       string `json:"name"`
+}
+
+// EvaluateExpression handles POST /api/expressions/evaluate
+func (h *ExpressionHandler) EvaluateExpression(c *gin.Context) {
+	var req evaluateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	if req.Symbol == "" {
+		req.Symbol = "ETHUSDT"
+	}
+	if req.Interval == "" {
+		req.Interval = "1h"
+	}
+	if req.Limit <= 0 || req.Limit > 500 {
+		req.Limit = 200
+	}
+
+	result, err := h.expressionService.Evaluate(req.Symbol, req.Interval, req.Limit, req.Expression, req.Name)
+	if err != nil {
+		c.JSON(expressionErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListExpressions handles GET /api/expressions
+func (h *ExpressionHandler) ListExpressions(c *gin.Context) {
+	expressions, err := h.expressionService.ListExpressions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"expressions": expressions,
+	})
+}
+
+// SaveExpression handles POST /api/expressions
+func (h *ExpressionHandler) SaveExpression(c *gin.Context) {
+	var expr model.SavedExpression
+	if err := c.ShouldBindJSON(&expr); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.expressionService.SaveExpression(&expr); err != nil {
+		c.JSON(expressionErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, expr)
+}
+
+// DeleteExpression handles DELETE /api/expressions/:name
+func (h *ExpressionHandler) DeleteExpression(c *gin.Context) {
+	if err := h.expressionService.DeleteExpression(c.Param("name")); err != nil {
+		c.JSON(expressionErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deleted": c.Param("name"),
+	})
+}
+
+// expressionErrorStatus maps an expression service error to its HTTP status: unknown names are 404,
+// invalid expressions and names 400, anything else (database, exchange) 500
+func expressionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrExpressionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, expression.ErrInvalidExpression), errors.Is(err, expression.ErrInvalidName):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -1,10 +1,14 @@
 package handler
 
 import (
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kudaompq/ai_trending/backend/internal/indicator"
 	"github.com/kudaompq/ai_trending/backend/internal/repository"
 	"github.com/kudaompq/ai_trending/backend/internal/service"
 )
@@ -14,6 +18,8 @@ type OpportunityHandler struct {
 	binanceRepo        *repository.BinanceRepository
 	analysisService    *service.AnalysisService
 	opportunityService *service.OpportunityService
+	seasonalityService *service.SeasonalityService
+	mtfService         *service.MTFService
 }
 
 // NewOpportunityHandler creates a new opportunity handler
@@ -22,6 +28,8 @@ func NewOpportunityHandler() *OpportunityHandler {
 		binanceRepo:        repository.NewBinanceRepository(),
 		analysisService:    service.NewAnalysisService(),
 		opportunityService: service.NewOpportunityService(),
+		seasonalityService: service.NewSeasonalityService(),
+		mtfService:         service.NewMTFService(),
 	}
 }
 
@@ -32,6 +40,15 @@ func (h *OpportunityHandler) GetOpportunities(c *gin.Context) {
 	interval := c.DefaultQuery("interval", "1h")
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
 	minRR, _ := strconv.ParseFloat(c.DefaultQuery("min_rr", "2.0"), 64)
+	confirmedOnly, _ := strconv.ParseBool(c.DefaultQuery("confirmed_only", "false"))
+	skipDeadHours, _ := strconv.ParseBool(c.DefaultQuery("skip_dead_hours", "false"))
+	timezone := c.DefaultQuery("timezone", "UTC")
+	if _, err := time.LoadLocation(timezone); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid timezone: " + timezone,
+		})
+		return
+	}
 
 	// Get candles
 	candles, err := h.binanceRepo.GetKlines(symbol, interval, limit)
@@ -51,8 +68,33 @@ func (h *OpportunityHandler) GetOpportunities(c *gin.Context) {
 		return
 	}
 
+	opts := service.OpportunityOptions{
+		MinRiskReward:         minRR,
+		ConfirmedPatternsOnly: confirmedOnly,
+	}
+
+	// Higher-timeframe trend context penalizes counter-trend trades; "none" disables it
+	higherTimeframes := service.DefaultHigherTimeframes(interval)
+	if htf := c.Query("htf"); htf == "none" {
+		higherTimeframes = nil
+	} else if htf != "" {
+		higherTimeframes = strings.Split(htf, ",")
+	}
+	opts.HigherTimeframes = h.mtfService.HigherTimeframeBiases(symbol, interval, higherTimeframes)
+
+	// Optionally suppress new signals in historically dead hours, judged on hourly candles whatever the
+	// interval; without statistics nothing is suppressed
+	if skipDeadHours {
+		seasonality, err := h.seasonalityService.Analyze(symbol, indicator.SessionTimeframe, timezone, 2000)
+		if err != nil {
+			log.Printf("Failed to load seasonality for dead-hour filter: %v", err)
+		} else {
+			opts.Seasonality = seasonality
+		}
+	}
+
 	// Detect opportunities
-	opportunities := h.opportunityService.DetectOpportunities(candles, analysis, minRR)
+	opportunities := h.opportunityService.DetectOpportunitiesWithOptions(candles, analysis, opts)
 
 	// Calculate summary
 	totalCount := len(opportunities)
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kudaompq/ai_trending/backend/internal/expression"
+	"github.com/kudaompq/ai_trending/backend/internal/service"
+)
+
+// ScanHandler handles market scanner requests
+type ScanHandler struct {
+	scanService *service.ScanService
+}
+
+// NewScanHandler creates a new scan handler
+func NewScanHandler() *ScanHandler {
+	return &ScanHandler{
+		scanService: service.NewScanService(),
+	}
+}
+
+// Scan handles GET /api/scan
+func (h *ScanHandler) Scan(c *gin.Context) {
+	req := service.ScanRequest{
+		Interval: c.DefaultQuery("interval", "1h"),
+		Filter:   c.Query("filter"),
+		Sort:     c.Query("sort"),
+		Desc:     strings.ToLower(c.DefaultQuery("order", "desc")) != "asc",
+	}
+	if symbols := c.Query("symbols"); symbols != "" {
+		for _, symbol := range strings.Split(strings.ToUpper(symbols), ",") {
+			if symbol = strings.TrimSpace(symbol); symbol != "" {
+				req.Symbols = append(req.Symbols, symbol)
+			}
+		}
+	}
+	if len(req.Symbols) > 50 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "at most 50 symbols can be scanned",
+		})
+		return
+	}
+
+	var err error
+	if req.Top, err = strconv.Atoi(c.DefaultQuery("top", "20")); err != nil || req.Top <= 0 || req.Top > 50 {
+		req.Top = 20
+	}
+	if req.Limit, err = strconv.Atoi(c.DefaultQuery("limit", "100")); err != nil || req.Limit <= 0 || req.Limit > 500 {
+		req.Limit = 100
+	}
+	if req.Page, err = strconv.Atoi(c.DefaultQuery("page", "1")); err != nil || req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize, err = strconv.Atoi(c.DefaultQuery("page_size", "10")); err != nil || req.PageSize <= 0 || req.PageSize > 50 {
+		req.PageSize = 10
+	}
+	req.Refresh, _ = strconv.ParseBool(c.DefaultQuery("refresh", "false"))
+
+	result, err := h.scanService.Scan(req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, expression.ErrInvalidFilter) || errors.Is(err, expression.ErrInvalidExpression) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
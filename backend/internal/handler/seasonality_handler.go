@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kudaompq/ai_trending/backend/internal/service"
+)
+
+// SeasonalityHandler handles seasonality requests
+type SeasonalityHandler struct {
+	seasonalityService *service.SeasonalityService
+}
+
+// NewSeasonalityHandler creates a new seasonality handler
+func NewSeasonalityHandler() *SeasonalityHandler {
+	return &SeasonalityHandler{
+		seasonalityService: service.NewSeasonalityService(),
+	}
+}
+
+// GetSeasonality handles GET /api/seasonality
+func (h *SeasonalityHandler) GetSeasonality(c *gin.Context) {
+	symbol := strings.ToUpper(c.DefaultQuery("symbol", "ETHUSDT"))
+	interval := c.DefaultQuery("interval", "1h")
+	timezone := c.DefaultQuery("timezone", "UTC")
+
+	bars, err := strconv.Atoi(c.DefaultQuery("bars", "2000"))
+	if err != nil || bars < 100 || bars > 20000 {
+		bars = 2000
+	}
+
+	result, err := h.seasonalityService.Analyze(symbol, interval, timezone, bars)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidTimezone) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
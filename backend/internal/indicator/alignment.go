@@ -0,0 +1,138 @@
+package indicator
+
+import (
+	"math"
+	"sort"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// sharedLevelThreshold is how close (relative to price) nearest levels of two timeframes must be to be shared
+const sharedLevelThreshold = 0.005
+
+// CalculateTimeframeBias reduces a timeframe's trend and EMAs to directions
+func CalculateTimeframeBias(interval string, trend model.TrendAnalysis, ema model.EMAIndicator) model.TimeframeBias {
+	bias := model.TimeframeBias{
+		Interval:      interval,
+		Trend:         trend.Direction,
+		TrendStrength: trend.Strength,
+		EMAAlignment:  EMAStackAlignment(ema),
+	}
+	switch trend.Direction {
+	case "上升":
+		bias.TrendSign = 1
+	case "下降":
+		bias.TrendSign = -1
+	}
+	return bias
+}
+
+// EMAStackAlignment returns 1 when EMA9 > EMA21 > EMA50 (and EMA200 below, when available),
+// -1 for the reverse order and 0 otherwise
+func EMAStackAlignment(ema model.EMAIndicator) int {
+	if ema.EMA9 <= 0 || ema.EMA21 <= 0 || ema.EMA50 <= 0 {
+		return 0
+	}
+	if ema.EMA9 > ema.EMA21 && ema.EMA21 > ema.EMA50 && (ema.EMA200 <= 0 || ema.EMA50 > ema.EMA200) {
+		return 1
+	}
+	if ema.EMA9 < ema.EMA21 && ema.EMA21 < ema.EMA50 && (ema.EMA200 <= 0 || ema.EMA50 < ema.EMA200) {
+		return -1
+	}
+	return 0
+}
+
+// CalculateTimeframeAlignment scores how well the timeframes agree: 40% trend direction, 35% EMA stack
+// and 25% nearest confluence levels shared with another timeframe. Direction agreement is the net
+// share |ups - downs| / n, so opposing timeframes cancel and sideways ones dilute.
+func CalculateTimeframeAlignment(results []*model.AnalysisResult) model.TimeframeAlignment {
+	alignment := model.TimeframeAlignment{
+		Direction:    "NEUTRAL",
+		Biases:       []model.TimeframeBias{},
+		SharedLevels: []model.SharedLevel{},
+	}
+	if len(results) == 0 {
+		return alignment
+	}
+
+	trendSum, emaSum := 0, 0
+	for _, result := range results {
+		bias := CalculateTimeframeBias(result.Interval, result.Trend, result.Indicators.EMA)
+		alignment.Biases = append(alignment.Biases, bias)
+		trendSum += bias.TrendSign
+		emaSum += bias.EMAAlignment
+	}
+	n := float64(len(results))
+	alignment.TrendAgreement = math.Abs(float64(trendSum)) / n
+	alignment.EMAAgreement = math.Abs(float64(emaSum)) / n
+
+	switch net := trendSum + emaSum; {
+	case net > 0:
+		alignment.Direction = "BULLISH"
+	case net < 0:
+		alignment.Direction = "BEARISH"
+	}
+
+	alignment.SharedLevels = sharedNearestLevels(results)
+	sharing := make(map[string]bool)
+	for _, level := range alignment.SharedLevels {
+		for _, tf := range level.Timeframes {
+			sharing[tf] = true
+		}
+	}
+	alignment.LevelAgreement = float64(len(sharing)) / n
+
+	alignment.Score = (alignment.TrendAgreement*0.4 + alignment.EMAAgreement*0.35 + alignment.LevelAgreement*0.25) * 100
+	return alignment
+}
+
+// sharedNearestLevels groups the nearest confluence support and resistance of every timeframe and
+// keeps the groups that span at least two timeframes, strongest agreement first
+func sharedNearestLevels(results []*model.AnalysisResult) []model.SharedLevel {
+	type nearestLevel struct {
+		price     float64
+		levelType string
+		interval  string
+	}
+
+	levels := make([]nearestLevel, 0, 2*len(results))
+	for _, result := range results {
+		confluence := result.MarketStructure.KeyLevelConfluence
+		for _, level := range []*model.ConfluenceLevel{confluence.NearestSupport, confluence.NearestResistance} {
+			if level != nil && level.Price > 0 {
+				levels = append(levels, nearestLevel{level.Price, level.Type, result.Interval})
+			}
+		}
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i].price < levels[j].price })
+
+	shared := make([]model.SharedLevel, 0)
+	for i := 0; i < len(levels); {
+		j := i + 1
+		for j < len(levels) && (levels[j].price-levels[i].price)/levels[i].price <= sharedLevelThreshold {
+			j++
+		}
+
+		timeframes := make([]string, 0)
+		sum := 0.0
+		for _, level := range levels[i:j] {
+			if !containsString(timeframes, level.interval) {
+				timeframes = append(timeframes, level.interval)
+			}
+			sum += level.price
+		}
+		if len(timeframes) >= 2 {
+			shared = append(shared, model.SharedLevel{
+				Price:      sum / float64(j-i),
+				Type:       levels[i].levelType,
+				Timeframes: timeframes,
+			})
+		}
+		i = j
+	}
+
+	sort.SliceStable(shared, func(i, j int) bool {
+		return len(shared[i].Timeframes) > len(shared[j].Timeframes)
+	})
+	return shared
+}
@@ -0,0 +1,67 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// timeframeResult builds an analysis result with a trend, an EMA stack and a nearest support
+func timeframeResult(interval, trend string, ema model.EMAIndicator, support float64) *model.AnalysisResult {
+	return &model.AnalysisResult{
+		Interval:   interval,
+		Trend:      model.TrendAnalysis{Direction: trend, Strength: 0.7},
+		Indicators: model.Indicators{EMA: ema},
+		MarketStructure: model.MarketStructure{
+			KeyLevelConfluence: model.KeyLevelConfluence{
+				NearestSupport: &model.ConfluenceLevel{Price: support, Type: "SUPPORT"},
+			},
+		},
+	}
+}
+
+func TestCalculateTimeframeAlignment(t *testing.T) {
+	bullish := model.EMAIndicator{EMA9: 110, EMA21: 105, EMA50: 100, EMA200: 90}
+	bearish := model.EMAIndicator{EMA9: 90, EMA21: 95, EMA50: 100, EMA200: 110}
+
+	// Every timeframe up with a bullish stack, 15m/1h/4h sharing support at ~100
+	aligned := CalculateTimeframeAlignment([]*model.AnalysisResult{
+		timeframeResult("15m", "上升", bullish, 100),
+		timeframeResult("1h", "上升", bullish, 100.2),
+		timeframeResult("4h", "上升", bullish, 100.4),
+		timeframeResult("1d", "上升", bullish, 90),
+	})
+	if aligned.Direction != "BULLISH" || aligned.TrendAgreement != 1 || aligned.EMAAgreement != 1 {
+		t.Errorf("aligned %+v", aligned)
+	}
+	if len(aligned.SharedLevels) != 1 || len(aligned.SharedLevels[0].Timeframes) != 3 || aligned.LevelAgreement != 0.75 {
+		t.Errorf("shared levels %+v, level agreement %v", aligned.SharedLevels, aligned.LevelAgreement)
+	}
+	if want := (0.4 + 0.35 + 0.25*0.75) * 100; math.Abs(aligned.Score-want) > 1e-9 {
+		t.Errorf("score %v, want %v", aligned.Score, want)
+	}
+
+	// Lower timeframes up against higher timeframes down cancel out
+	conflicting := CalculateTimeframeAlignment([]*model.AnalysisResult{
+		timeframeResult("1h", "上升", bullish, 100),
+		timeframeResult("4h", "下降", bearish, 80),
+		timeframeResult("1d", "下降", bearish, 60),
+		timeframeResult("15m", "上升", bullish, 120),
+	})
+	if conflicting.Direction != "NEUTRAL" || conflicting.Score != 0 {
+		t.Errorf("conflicting %+v, want neutral with zero score", conflicting)
+	}
+}
+
+func TestEMAStackAlignment(t *testing.T) {
+	if a := EMAStackAlignment(model.EMAIndicator{EMA9: 3, EMA21: 2, EMA50: 1}); a != 1 {
+		t.Errorf("bullish stack without EMA200: %d", a)
+	}
+	if a := EMAStackAlignment(model.EMAIndicator{EMA9: 3, EMA21: 2, EMA50: 1, EMA200: 5}); a != 0 {
+		t.Errorf("EMA50 below EMA200 should not be a bullish stack: %d", a)
+	}
+	if a := EMAStackAlignment(model.EMAIndicator{EMA9: 1, EMA21: 2, EMA50: 3, EMA200: 4}); a != -1 {
+		t.Errorf("bearish stack: %d", a)
+	}
+}
@@ -0,0 +1,133 @@
+package indicator
+
+import (
+	"sort"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// patternKey identifies calibrated statistics; a pattern such as 孕线 is bullish or bearish depending on context
+type patternKey struct {
+	pattern   string
+	direction string
+}
+
+// DefaultCalibrationHorizon is the default number of forward bars used to score a pattern
+const DefaultCalibrationHorizon = 10
+
+// CalibrationPriorWeight is how many pseudo-samples the hardcoded reliability counts for when
+// shrinking the empirical hit rate, so rare patterns stay close to their prior
+const CalibrationPriorWeight = 10
+
+// MinCalibrationSamples is the sample size below which the empirical reliability is reported but not used
+const MinCalibrationSamples = 20
+
+// CalibratePatterns scans the candles for patterns and measures each directional pattern's
+// direction-aligned close-to-close return, separately per direction, over the next horizon bars. A hit is a positive aligned
+// return. Neutral patterns and occurrences without a full forward window are skipped.
+// Symbol, Interval and UpdatedAt are left for the caller.
+func CalibratePatterns(candles []model.Candle, trendAt func(candles []model.Candle) string, horizon int) []model.PatternStats {
+	stats := make([]model.PatternStats, 0)
+	if horizon <= 0 || len(candles) <= horizon {
+		return stats
+	}
+
+	indexOf := make(map[int64]int, len(candles))
+	for i, c := range candles {
+		indexOf[c.Timestamp] = i
+	}
+
+	type accumulator struct {
+		stats     model.PatternStats
+		sumReturn float64
+		sumPrior  float64
+	}
+	byPattern := make(map[patternKey]*accumulator)
+
+	for _, p := range ScanPatterns(candles, trendAt) {
+		sign := 0.0
+		switch p.Direction {
+		case "看涨":
+			sign = 1
+		case "看跌":
+			sign = -1
+		}
+		i, ok := indexOf[p.Timestamp]
+		if sign == 0 || !ok || i+horizon >= len(candles) || candles[i].Close <= 0 {
+			continue
+		}
+
+		key := patternKey{p.Pattern, p.Direction}
+		acc, ok := byPattern[key]
+		if !ok {
+			acc = &accumulator{stats: model.PatternStats{
+				Pattern:   p.Pattern,
+				Direction: p.Direction,
+				Horizon:   horizon,
+			}}
+			byPattern[key] = acc
+		}
+
+		ret := sign * (candles[i+horizon].Close - candles[i].Close) / candles[i].Close * 100
+		acc.stats.Samples++
+		if ret > 0 {
+			acc.stats.Hits++
+		}
+		acc.sumReturn += ret
+		acc.sumPrior += p.Reliability
+	}
+
+	for _, acc := range byPattern {
+		s := acc.stats
+		n := float64(s.Samples)
+		s.HitRate = float64(s.Hits) / n
+		s.AvgReturn = acc.sumReturn / n
+		s.PriorReliability = acc.sumPrior / n
+		s.EmpiricalReliability = EmpiricalReliability(s.Hits, s.Samples, s.PriorReliability)
+		stats = append(stats, s)
+	}
+
+	// Most observed first
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Samples != stats[j].Samples {
+			return stats[i].Samples > stats[j].Samples
+		}
+		if stats[i].Pattern != stats[j].Pattern {
+			return stats[i].Pattern < stats[j].Pattern
+		}
+		return stats[i].Direction < stats[j].Direction
+	})
+
+	return stats
+}
+
+// EmpiricalReliability shrinks the observed hit rate toward the prior reliability:
+// (hits + prior × k) / (samples + k) with k = CalibrationPriorWeight
+func EmpiricalReliability(hits, samples int, prior float64) float64 {
+	return (float64(hits) + prior*CalibrationPriorWeight) / float64(samples+CalibrationPriorWeight)
+}
+
+// ApplyPatternStats records the prior and empirical reliability on each pattern that has statistics
+// for its direction.
+// When useEmpirical is set and the sample size reaches MinCalibrationSamples, Reliability is replaced
+// by the empirical value.
+func ApplyPatternStats(patterns []model.CandlestickPattern, stats []model.PatternStats, useEmpirical bool) {
+	byPattern := make(map[patternKey]model.PatternStats, len(stats))
+	for _, s := range stats {
+		byPattern[patternKey{s.Pattern, s.Direction}] = s
+	}
+
+	for i := range patterns {
+		s, ok := byPattern[patternKey{patterns[i].Pattern, patterns[i].Direction}]
+		if !ok || s.Samples == 0 {
+			continue
+		}
+		prior := patterns[i].Reliability
+		patterns[i].PriorReliability = prior
+		patterns[i].EmpiricalReliability = EmpiricalReliability(s.Hits, s.Samples, prior)
+		patterns[i].SampleSize = s.Samples
+		if useEmpirical && s.Samples >= MinCalibrationSamples {
+			patterns[i].Reliability = patterns[i].EmpiricalReliability
+		}
+	}
+}
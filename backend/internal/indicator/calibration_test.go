@@ -0,0 +1,97 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+func TestCalibratePatterns(t *testing.T) {
+	candles := randomWalkCandles(600, 7)
+	trendAt := func(window []model.Candle) string { return "盘整" }
+	horizon := 5
+
+	stats := CalibratePatterns(candles, trendAt, horizon)
+	if len(stats) == 0 {
+		t.Fatal("expected pattern statistics")
+	}
+
+	for _, s := range stats {
+		if s.Direction != "看涨" && s.Direction != "看跌" {
+			t.Errorf("%s: neutral pattern calibrated", s.Pattern)
+		}
+		if s.Samples <= 0 || s.Hits > s.Samples || s.Horizon != horizon {
+			t.Errorf("%s: invalid counts %+v", s.Pattern, s)
+		}
+		if math.Abs(s.HitRate-float64(s.Hits)/float64(s.Samples)) > 1e-12 {
+			t.Errorf("%s: hit rate %v does not match %d/%d", s.Pattern, s.HitRate, s.Hits, s.Samples)
+		}
+		lo, hi := math.Min(s.HitRate, s.PriorReliability), math.Max(s.HitRate, s.PriorReliability)
+		if s.EmpiricalReliability < lo-1e-12 || s.EmpiricalReliability > hi+1e-12 {
+			t.Errorf("%s: empirical %v not between hit rate %v and prior %v", s.Pattern, s.EmpiricalReliability, s.HitRate, s.PriorReliability)
+		}
+	}
+
+	// One entry per pattern and direction
+	seen := make(map[patternKey]bool)
+	for _, s := range stats {
+		key := patternKey{s.Pattern, s.Direction}
+		if seen[key] {
+			t.Errorf("%s %s calibrated twice", s.Pattern, s.Direction)
+		}
+		seen[key] = true
+	}
+
+	// Patterns completing within the last horizon bars have no forward window
+	if got := CalibratePatterns(candles[:horizon], trendAt, horizon); len(got) != 0 {
+		t.Errorf("expected no statistics without a forward window, got %d", len(got))
+	}
+}
+
+func TestApplyPatternStats(t *testing.T) {
+	stats := []model.PatternStats{
+		{Pattern: "锤子线", Direction: "看涨", Samples: 40, Hits: 10},
+		{Pattern: "吞没形态", Direction: "看涨", Samples: 5, Hits: 5},
+		{Pattern: "孕线", Direction: "看涨", Samples: 30, Hits: 24},
+		{Pattern: "孕线", Direction: "看跌", Samples: 30, Hits: 6},
+	}
+	patterns := []model.CandlestickPattern{
+		{Pattern: "锤子线", Direction: "看涨", Reliability: 0.75},
+		{Pattern: "吞没形态", Direction: "看涨", Reliability: 0.8},
+		{Pattern: "十字星", Direction: "中性", Reliability: 0.6},
+		{Pattern: "孕线", Direction: "看涨", Reliability: 0.6},
+		{Pattern: "孕线", Direction: "看跌", Reliability: 0.6},
+		{Pattern: "吞没形态", Direction: "看跌", Reliability: 0.8},
+	}
+
+	ApplyPatternStats(patterns, stats, true)
+
+	hammer := patterns[0]
+	want := EmpiricalReliability(10, 40, 0.75)
+	if hammer.PriorReliability != 0.75 || hammer.SampleSize != 40 || hammer.Reliability != want {
+		t.Errorf("hammer: got %+v, want reliability %v", hammer, want)
+	}
+
+	// Too few samples: reported but not used
+	engulfing := patterns[1]
+	if engulfing.Reliability != 0.8 || engulfing.EmpiricalReliability == 0 || engulfing.SampleSize != 5 {
+		t.Errorf("engulfing: got %+v", engulfing)
+	}
+
+	// Uncalibrated patterns are untouched
+	if doji := patterns[2]; doji.PriorReliability != 0 || doji.SampleSize != 0 || doji.Reliability != 0.6 {
+		t.Errorf("doji: got %+v", doji)
+	}
+
+	// Each direction of a two-sided pattern uses its own statistics
+	if got, want := patterns[3].Reliability, EmpiricalReliability(24, 30, 0.6); got != want {
+		t.Errorf("bullish harami: reliability %v, want %v", got, want)
+	}
+	if got, want := patterns[4].Reliability, EmpiricalReliability(6, 30, 0.6); got != want {
+		t.Errorf("bearish harami: reliability %v, want %v", got, want)
+	}
+	if bearish := patterns[5]; bearish.SampleSize != 0 || bearish.Reliability != 0.8 {
+		t.Errorf("bearish engulfing took the bullish statistics: %+v", bearish)
+	}
+}
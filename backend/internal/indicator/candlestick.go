@@ -204,6 +204,34 @@ func IdentifyPatterns(candles []model.Candle, trend string) []model.CandlestickP
 		}
 	}
 
+	// Extended pattern library
+	patterns = append(patterns, identifyExtendedPatterns(candles, trend)...)
+
+	// Stamp every pattern with the candle that completed it
+	for i := range patterns {
+		patterns[i].Timestamp = curr.Timestamp
+	}
+
+	return patterns
+}
+
+// ScanPatterns runs the pattern detectors at every bar in the window. trendAt is evaluated on the
+// candles up to each bar, so each pattern is judged against the trend at the time it formed rather
+// than the current trend. Position stays relative to the last candle and Timestamp is the candle
+// that completed the pattern. Results are oldest first.
+func ScanPatterns(candles []model.Candle, trendAt func(candles []model.Candle) string) []model.CandlestickPattern {
+	patterns := make([]model.CandlestickPattern, 0)
+	n := len(candles)
+
+	for i := 0; i < n; i++ {
+		window := candles[:i+1]
+		offset := n - 1 - i
+		for _, p := range IdentifyPatterns(window, trendAt(window)) {
+			p.Position -= offset
+			patterns = append(patterns, p)
+		}
+	}
+
 	return patterns
 }
 
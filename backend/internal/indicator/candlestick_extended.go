@@ -0,0 +1,453 @@
+package indicator
+
+import (
+	"math"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+func candleBody(c model.Candle) float64 {
+	return math.Abs(c.Close - c.Open)
+}
+
+func candleRange(c model.Candle) float64 {
+	return c.High - c.Low
+}
+
+func isBullishCandle(c model.Candle) bool {
+	return c.Close > c.Open
+}
+
+func isBearishCandle(c model.Candle) bool {
+	return c.Close < c.Open
+}
+
+// isLongBody reports whether the body fills at least 60% of the candle's range
+func isLongBody(c model.Candle) bool {
+	r := candleRange(c)
+	return r > 0 && candleBody(c)/r >= 0.6
+}
+
+// IsMarubozu checks for a candle with (almost) no shadows: body ≥ 95% of the range
+func IsMarubozu(candle model.Candle) (bool, string) {
+	totalLength := candleRange(candle)
+	if totalLength == 0 || candleBody(candle)/totalLength < 0.95 {
+		return false, ""
+	}
+	if isBullishCandle(candle) {
+		return true, "Bullish"
+	}
+	return true, "Bearish"
+}
+
+// IsSpinningTop checks for a small body (5-30% of the range) with both shadows longer than the body
+func IsSpinningTop(candle model.Candle) bool {
+	bodyLength := candleBody(candle)
+	totalLength := candleRange(candle)
+	upperShadow := candle.High - math.Max(candle.Open, candle.Close)
+	lowerShadow := math.Min(candle.Open, candle.Close) - candle.Low
+
+	if totalLength == 0 {
+		return false
+	}
+	ratio := bodyLength / totalLength
+
+	return ratio > 0.05 && ratio <= 0.3 &&
+		upperShadow > bodyLength &&
+		lowerShadow > bodyLength
+}
+
+// IsBeltHold checks for a long candle opening at its extreme against the trend:
+// bullish opens on its low in a downtrend, bearish opens on its high in an uptrend
+func IsBeltHold(candle model.Candle, trend string) (bool, string) {
+	totalLength := candleRange(candle)
+	if totalLength == 0 || !isLongBody(candle) {
+		return false, ""
+	}
+	upperShadow := candle.High - math.Max(candle.Open, candle.Close)
+	lowerShadow := math.Min(candle.Open, candle.Close) - candle.Low
+
+	// Opens at the extreme, but still has a shadow on the closing side (not a marubozu)
+	if trend == "下降" && isBullishCandle(candle) &&
+		lowerShadow <= totalLength*0.02 && upperShadow > totalLength*0.05 {
+		return true, "Bullish"
+	}
+	if trend == "上升" && isBearishCandle(candle) &&
+		upperShadow <= totalLength*0.02 && lowerShadow > totalLength*0.05 {
+		return true, "Bearish"
+	}
+	return false, ""
+}
+
+// IsTweezerTop checks for matching highs at the end of an uptrend, bullish then bearish
+func IsTweezerTop(prev, curr model.Candle, trend string) bool {
+	tolerance := (candleRange(prev) + candleRange(curr)) / 2 * 0.05
+
+	return trend == "上升" &&
+		isBullishCandle(prev) &&
+		isBearishCandle(curr) &&
+		math.Abs(prev.High-curr.High) <= tolerance
+}
+
+// IsTweezerBottom checks for matching lows at the end of a downtrend, bearish then bullish
+func IsTweezerBottom(prev, curr model.Candle, trend string) bool {
+	tolerance := (candleRange(prev) + candleRange(curr)) / 2 * 0.05
+
+	return trend == "下降" &&
+		isBearishCandle(prev) &&
+		isBullishCandle(curr) &&
+		math.Abs(prev.Low-curr.Low) <= tolerance
+}
+
+// IsKicker checks for two long opposite candles with a gap between their opens;
+// the second candle opens beyond the first candle's open
+func IsKicker(prev, curr model.Candle) (bool, string) {
+	if !isLongBody(prev) || !isLongBody(curr) {
+		return false, ""
+	}
+	if isBearishCandle(prev) && isBullishCandle(curr) && curr.Open >= prev.Open {
+		return true, "Bullish"
+	}
+	if isBullishCandle(prev) && isBearishCandle(curr) && curr.Open <= prev.Open {
+		return true, "Bearish"
+	}
+	return false, ""
+}
+
+// IsThreeInsideUp checks for a bullish harami confirmed by a close above the first candle's open
+func IsThreeInsideUp(c1, c2, c3 model.Candle, trend string) bool {
+	isHarami, haramiType := IsHarami(c1, c2)
+
+	return trend == "下降" &&
+		isHarami && haramiType == "Bullish" &&
+		isBullishCandle(c3) &&
+		c3.Close > c1.Open
+}
+
+// IsThreeInsideDown checks for a bearish harami confirmed by a close below the first candle's open
+func IsThreeInsideDown(c1, c2, c3 model.Candle, trend string) bool {
+	isHarami, haramiType := IsHarami(c1, c2)
+
+	return trend == "上升" &&
+		isHarami && haramiType == "Bearish" &&
+		isBearishCandle(c3) &&
+		c3.Close < c1.Open
+}
+
+// IsThreeOutsideUp checks for a bullish engulfing body confirmed by a higher close
+func IsThreeOutsideUp(c1, c2, c3 model.Candle, trend string) bool {
+	return trend == "下降" &&
+		isBearishCandle(c1) &&
+		isBullishCandle(c2) &&
+		c2.Open <= c1.Close && c2.Close > c1.Open && // Second body engulfs the first
+		isBullishCandle(c3) &&
+		c3.Close > c2.Close
+}
+
+// IsThreeOutsideDown checks for a bearish engulfing body confirmed by a lower close
+func IsThreeOutsideDown(c1, c2, c3 model.Candle, trend string) bool {
+	return trend == "上升" &&
+		isBullishCandle(c1) &&
+		isBearishCandle(c2) &&
+		c2.Open >= c1.Close && c2.Close < c1.Open && // Second body engulfs the first
+		isBearishCandle(c3) &&
+		c3.Close < c2.Close
+}
+
+// IsAbandonedBaby checks for a doji that gaps away from both neighbours (shadows do not overlap)
+func IsAbandonedBaby(c1, c2, c3 model.Candle, trend string) (bool, string) {
+	isDoji, _ := IsDoji(c2)
+	if !isDoji {
+		return false, ""
+	}
+
+	if trend == "下降" && isBearishCandle(c1) && isBullishCandle(c3) &&
+		c2.High < c1.Low && c2.High < c3.Low {
+		return true, "Bullish"
+	}
+	if trend == "上升" && isBullishCandle(c1) && isBearishCandle(c3) &&
+		c2.Low > c1.High && c2.Low > c3.High {
+		return true, "Bearish"
+	}
+	return false, ""
+}
+
+// IsUpsideTasukiGap checks for two rising candles with a gap, then a bearish candle
+// that opens inside the second body and closes inside the gap without filling it
+func IsUpsideTasukiGap(c1, c2, c3 model.Candle, trend string) bool {
+	return trend == "上升" &&
+		isBullishCandle(c1) &&
+		isBullishCandle(c2) &&
+		c2.Low > c1.High && // Gap up
+		isBearishCandle(c3) &&
+		c3.Open > c2.Open && c3.Open < c2.Close &&
+		c3.Close < c2.Low && c3.Close > c1.High
+}
+
+// IsDownsideTasukiGap checks for two falling candles with a gap, then a bullish candle
+// that opens inside the second body and closes inside the gap without filling it
+func IsDownsideTasukiGap(c1, c2, c3 model.Candle, trend string) bool {
+	return trend == "下降" &&
+		isBearishCandle(c1) &&
+		isBearishCandle(c2) &&
+		c2.High < c1.Low && // Gap down
+		isBullishCandle(c3) &&
+		c3.Open < c2.Open && c3.Open > c2.Close &&
+		c3.Close > c2.High && c3.Close < c1.Low
+}
+
+// IsRisingThreeMethods checks for a long bullish candle, three small candles held within its
+// range, and a long bullish candle closing above the first
+func IsRisingThreeMethods(c []model.Candle, trend string) bool {
+	if len(c) != 5 || trend != "上升" {
+		return false
+	}
+	first, last := c[0], c[4]
+	if !isBullishCandle(first) || !isLongBody(first) || !isBullishCandle(last) || !isLongBody(last) {
+		return false
+	}
+	for _, mid := range c[1:4] {
+		if mid.High > first.High || mid.Low < first.Low || candleBody(mid) >= candleBody(first)*0.5 {
+			return false
+		}
+	}
+	return last.Close > first.Close
+}
+
+// IsFallingThreeMethods checks for a long bearish candle, three small candles held within its
+// range, and a long bearish candle closing below the first
+func IsFallingThreeMethods(c []model.Candle, trend string) bool {
+	if len(c) != 5 || trend != "下降" {
+		return false
+	}
+	first, last := c[0], c[4]
+	if !isBearishCandle(first) || !isLongBody(first) || !isBearishCandle(last) || !isLongBody(last) {
+		return false
+	}
+	for _, mid := range c[1:4] {
+		if mid.High > first.High || mid.Low < first.Low || candleBody(mid) >= candleBody(first)*0.5 {
+			return false
+		}
+	}
+	return last.Close < first.Close
+}
+
+// identifyExtendedPatterns runs the extended pattern library on the final candles
+func identifyExtendedPatterns(candles []model.Candle, trend string) []model.CandlestickPattern {
+	patterns := make([]model.CandlestickPattern, 0)
+	n := len(candles)
+	if n < 1 {
+		return patterns
+	}
+
+	curr := candles[n-1]
+
+	// Single candle patterns
+	if ok, kind := IsMarubozu(curr); ok {
+		p := model.CandlestickPattern{
+			Pattern:     "光头光脚阳线",
+			Type:        "持续",
+			Direction:   "看涨",
+			Position:    0,
+			Reliability: 0.70,
+			Description: "几乎没有影线的大阳线，买方全程主导",
+		}
+		if kind == "Bearish" {
+			p.Pattern = "光头光脚阴线"
+			p.Direction = "看跌"
+			p.Description = "几乎没有影线的大阴线，卖方全程主导"
+		}
+		patterns = append(patterns, p)
+	}
+
+	if IsSpinningTop(curr) {
+		patterns = append(patterns, model.CandlestickPattern{
+			Pattern:     "纺锤线",
+			Type:        "反转",
+			Direction:   "中性",
+			Position:    0,
+			Reliability: 0.55,
+			Description: "实体小、上下影线长，多空僵持",
+		})
+	}
+
+	if ok, kind := IsBeltHold(curr, trend); ok {
+		p := model.CandlestickPattern{
+			Pattern:     "看涨捉腰带线",
+			Type:        "反转",
+			Direction:   "看涨",
+			Position:    0,
+			Reliability: 0.65,
+			Description: "下降趋势中以最低价开盘后大幅上涨",
+		}
+		if kind == "Bearish" {
+			p.Pattern = "看跌捉腰带线"
+			p.Direction = "看跌"
+			p.Description = "上升趋势中以最高价开盘后大幅下跌"
+		}
+		patterns = append(patterns, p)
+	}
+
+	// Two candle patterns
+	if n >= 2 {
+		prev := candles[n-2]
+
+		if IsTweezerTop(prev, curr, trend) {
+			patterns = append(patterns, model.CandlestickPattern{
+				Pattern:     "平头顶部",
+				Type:        "反转",
+				Direction:   "看跌",
+				Position:    -1,
+				Reliability: 0.65,
+				Description: "两根K线最高价相同，上方阻力明显",
+			})
+		}
+
+		if IsTweezerBottom(prev, curr, trend) {
+			patterns = append(patterns, model.CandlestickPattern{
+				Pattern:     "平头底部",
+				Type:        "反转",
+				Direction:   "看涨",
+				Position:    -1,
+				Reliability: 0.65,
+				Description: "两根K线最低价相同，下方支撑明显",
+			})
+		}
+
+		if ok, kind := IsKicker(prev, curr); ok {
+			p := model.CandlestickPattern{
+				Pattern:     "看涨反冲",
+				Type:        "反转",
+				Direction:   "看涨",
+				Position:    -1,
+				Reliability: 0.90,
+				Description: "大阴线后跳空高开大阳线，情绪急剧反转",
+			}
+			if kind == "Bearish" {
+				p.Pattern = "看跌反冲"
+				p.Direction = "看跌"
+				p.Description = "大阳线后跳空低开大阴线，情绪急剧反转"
+			}
+			patterns = append(patterns, p)
+		}
+	}
+
+	// Three candle patterns
+	if n >= 3 {
+		c1 := candles[n-3]
+		c2 := candles[n-2]
+		c3 := candles[n-1]
+
+		if IsThreeInsideUp(c1, c2, c3, trend) {
+			patterns = append(patterns, model.CandlestickPattern{
+				Pattern:     "三内部上涨",
+				Type:        "反转",
+				Direction:   "看涨",
+				Position:    -2,
+				Reliability: 0.80,
+				Description: "看涨孕线后阳线收于首根开盘价之上，反转确认",
+			})
+		}
+
+		if IsThreeInsideDown(c1, c2, c3, trend) {
+			patterns = append(patterns, model.CandlestickPattern{
+				Pattern:     "三内部下跌",
+				Type:        "反转",
+				Direction:   "看跌",
+				Position:    -2,
+				Reliability: 0.80,
+				Description: "看跌孕线后阴线收于首根开盘价之下，反转确认",
+			})
+		}
+
+		if IsThreeOutsideUp(c1, c2, c3, trend) {
+			patterns = append(patterns, model.CandlestickPattern{
+				Pattern:     "三外部上涨",
+				Type:        "反转",
+				Direction:   "看涨",
+				Position:    -2,
+				Reliability: 0.85,
+				Description: "看涨吞没后继续收高，反转确认",
+			})
+		}
+
+		if IsThreeOutsideDown(c1, c2, c3, trend) {
+			patterns = append(patterns, model.CandlestickPattern{
+				Pattern:     "三外部下跌",
+				Type:        "反转",
+				Direction:   "看跌",
+				Position:    -2,
+				Reliability: 0.85,
+				Description: "看跌吞没后继续收低，反转确认",
+			})
+		}
+
+		if ok, kind := IsAbandonedBaby(c1, c2, c3, trend); ok {
+			p := model.CandlestickPattern{
+				Pattern:     "看涨弃婴",
+				Type:        "反转",
+				Direction:   "看涨",
+				Position:    -2,
+				Reliability: 0.90,
+				Description: "跳空十字星孤立于底部，罕见的强烈反转信号",
+			}
+			if kind == "Bearish" {
+				p.Pattern = "看跌弃婴"
+				p.Direction = "看跌"
+				p.Description = "跳空十字星孤立于顶部，罕见的强烈反转信号"
+			}
+			patterns = append(patterns, p)
+		}
+
+		if IsUpsideTasukiGap(c1, c2, c3, trend) {
+			patterns = append(patterns, model.CandlestickPattern{
+				Pattern:     "上升跳空并列阴阳线",
+				Type:        "持续",
+				Direction:   "看涨",
+				Position:    -2,
+				Reliability: 0.65,
+				Description: "回调未能回补跳空缺口，上升趋势延续",
+			})
+		}
+
+		if IsDownsideTasukiGap(c1, c2, c3, trend) {
+			patterns = append(patterns, model.CandlestickPattern{
+				Pattern:     "下降跳空并列阴阳线",
+				Type:        "持续",
+				Direction:   "看跌",
+				Position:    -2,
+				Reliability: 0.65,
+				Description: "反弹未能回补跳空缺口，下降趋势延续",
+			})
+		}
+	}
+
+	// Five candle patterns
+	if n >= 5 {
+		last5 := candles[n-5:]
+
+		if IsRisingThreeMethods(last5, trend) {
+			patterns = append(patterns, model.CandlestickPattern{
+				Pattern:     "上升三法",
+				Type:        "持续",
+				Direction:   "看涨",
+				Position:    -4,
+				Reliability: 0.75,
+				Description: "大阳线后小幅整理，再以大阳线创新高，上升趋势延续",
+			})
+		}
+
+		if IsFallingThreeMethods(last5, trend) {
+			patterns = append(patterns, model.CandlestickPattern{
+				Pattern:     "下降三法",
+				Type:        "持续",
+				Direction:   "看跌",
+				Position:    -4,
+				Reliability: 0.75,
+				Description: "大阴线后小幅整理，再以大阴线创新低，下降趋势延续",
+			})
+		}
+	}
+
+	return patterns
+}
@@ -0,0 +1,177 @@
+package indicator
+
+import (
+	"testing"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+func ohlc(open, high, low, close float64) model.Candle {
+	return model.Candle{Open: open, High: high, Low: low, Close: close, Volume: 1000}
+}
+
+func TestExtendedPatternDetectors(t *testing.T) {
+	kind := func(ok bool, k string) string {
+		if !ok {
+			return ""
+		}
+		return k
+	}
+
+	tests := []struct {
+		name   string
+		detect func() string
+		want   string
+	}{
+		// Marubozu
+		{"marubozu bullish", func() string { return kind(IsMarubozu(ohlc(100, 110, 100, 110))) }, "Bullish"},
+		{"marubozu bearish", func() string { return kind(IsMarubozu(ohlc(110, 110, 100, 100))) }, "Bearish"},
+		{"marubozu with shadows", func() string { return kind(IsMarubozu(ohlc(100, 112, 98, 110))) }, ""},
+		{"marubozu flat candle", func() string { return kind(IsMarubozu(ohlc(100, 100, 100, 100))) }, ""},
+
+		// Spinning top
+		{"spinning top", func() string { return kind(IsSpinningTop(ohlc(100, 106, 94, 101)), "yes") }, "yes"},
+		{"spinning top rejects doji", func() string { return kind(IsSpinningTop(ohlc(100, 106, 94, 100.2)), "yes") }, ""},
+		{"spinning top rejects long body", func() string { return kind(IsSpinningTop(ohlc(100, 106, 94, 105)), "yes") }, ""},
+		{"spinning top needs both shadows", func() string { return kind(IsSpinningTop(ohlc(100, 101.5, 90, 101)), "yes") }, ""},
+
+		// Belt hold
+		{"belt hold bullish", func() string { return kind(IsBeltHold(ohlc(100, 112, 100, 110), "下降")) }, "Bullish"},
+		{"belt hold bearish", func() string { return kind(IsBeltHold(ohlc(110, 110, 98, 100), "上升")) }, "Bearish"},
+		{"belt hold wrong trend", func() string { return kind(IsBeltHold(ohlc(100, 112, 100, 110), "上升")) }, ""},
+		{"belt hold rejects marubozu", func() string { return kind(IsBeltHold(ohlc(100, 110, 100, 110), "下降")) }, ""},
+
+		// Tweezers
+		{"tweezer top", func() string {
+			return kind(IsTweezerTop(ohlc(100, 110, 99, 108), ohlc(108, 110, 100, 101), "上升"), "yes")
+		}, "yes"},
+		{"tweezer top mismatched highs", func() string {
+			return kind(IsTweezerTop(ohlc(100, 110, 99, 108), ohlc(108, 112, 100, 101), "上升"), "yes")
+		}, ""},
+		{"tweezer top wrong trend", func() string {
+			return kind(IsTweezerTop(ohlc(100, 110, 99, 108), ohlc(108, 110, 100, 101), "下降"), "yes")
+		}, ""},
+		{"tweezer bottom", func() string {
+			return kind(IsTweezerBottom(ohlc(110, 111, 100, 102), ohlc(102, 109, 100, 108), "下降"), "yes")
+		}, "yes"},
+
+		// Kicker
+		{"kicker bullish", func() string { return kind(IsKicker(ohlc(110, 111, 99, 100), ohlc(111, 122, 110, 121))) }, "Bullish"},
+		{"kicker bearish", func() string { return kind(IsKicker(ohlc(100, 111, 99, 110), ohlc(99, 100, 88, 89))) }, "Bearish"},
+		{"kicker without gap", func() string { return kind(IsKicker(ohlc(110, 111, 99, 100), ohlc(105, 116, 104, 115))) }, ""},
+
+		// Three inside
+		{"three inside up", func() string {
+			return kind(IsThreeInsideUp(ohlc(110, 111, 99, 100), ohlc(102, 106, 101, 105), ohlc(105, 113, 104, 112), "下降"), "yes")
+		}, "yes"},
+		{"three inside up unconfirmed", func() string {
+			return kind(IsThreeInsideUp(ohlc(110, 111, 99, 100), ohlc(102, 106, 101, 105), ohlc(105, 109, 104, 108), "下降"), "yes")
+		}, ""},
+		{"three inside down", func() string {
+			return kind(IsThreeInsideDown(ohlc(100, 111, 99, 110), ohlc(108, 109, 104, 105), ohlc(105, 106, 97, 98), "上升"), "yes")
+		}, "yes"},
+
+		// Three outside
+		{"three outside up", func() string {
+			return kind(IsThreeOutsideUp(ohlc(105, 106, 99, 100), ohlc(99, 108, 98, 107), ohlc(107, 111, 106, 110), "下降"), "yes")
+		}, "yes"},
+		{"three outside up unconfirmed", func() string {
+			return kind(IsThreeOutsideUp(ohlc(105, 106, 99, 100), ohlc(99, 108, 98, 107), ohlc(107, 108, 105, 106), "下降"), "yes")
+		}, ""},
+		{"three outside down", func() string {
+			return kind(IsThreeOutsideDown(ohlc(100, 106, 99, 105), ohlc(106, 107, 97, 98), ohlc(98, 99, 94, 95), "上升"), "yes")
+		}, "yes"},
+
+		// Abandoned baby
+		{"abandoned baby bullish", func() string {
+			return kind(IsAbandonedBaby(ohlc(110, 111, 99, 100), ohlc(96, 97, 95, 96), ohlc(99, 108, 98, 107), "下降"))
+		}, "Bullish"},
+		{"abandoned baby bearish", func() string {
+			return kind(IsAbandonedBaby(ohlc(100, 111, 99, 110), ohlc(114, 115, 113, 114), ohlc(112, 112.5, 103, 104), "上升"))
+		}, "Bearish"},
+		{"abandoned baby shadows overlap", func() string {
+			return kind(IsAbandonedBaby(ohlc(110, 111, 99, 100), ohlc(96, 99.5, 95, 96), ohlc(99, 108, 98, 107), "下降"))
+		}, ""},
+
+		// Tasuki gaps
+		{"upside tasuki gap", func() string {
+			return kind(IsUpsideTasukiGap(ohlc(100, 106, 99, 105), ohlc(107, 113, 107, 112), ohlc(110, 111, 106.5, 106.5), "上升"), "yes")
+		}, "yes"},
+		{"upside tasuki gap filled", func() string {
+			return kind(IsUpsideTasukiGap(ohlc(100, 106, 99, 105), ohlc(107, 113, 107, 112), ohlc(110, 111, 105, 105), "上升"), "yes")
+		}, ""},
+		{"downside tasuki gap", func() string {
+			return kind(IsDownsideTasukiGap(ohlc(105, 106, 99, 100), ohlc(98, 98, 92, 93), ohlc(95, 98.5, 94, 98.5), "下降"), "yes")
+		}, "yes"},
+
+		// Three methods
+		{"rising three methods", func() string {
+			return kind(IsRisingThreeMethods([]model.Candle{
+				ohlc(100, 111, 99, 110), ohlc(109, 110, 106, 107), ohlc(107, 108, 105, 106), ohlc(106, 108, 104, 107), ohlc(107, 113, 106, 112),
+			}, "上升"), "yes")
+		}, "yes"},
+		{"rising three methods breaks range", func() string {
+			return kind(IsRisingThreeMethods([]model.Candle{
+				ohlc(100, 111, 99, 110), ohlc(109, 110, 98, 107), ohlc(107, 108, 105, 106), ohlc(106, 108, 104, 107), ohlc(107, 113, 106, 112),
+			}, "上升"), "yes")
+		}, ""},
+		{"falling three methods", func() string {
+			return kind(IsFallingThreeMethods([]model.Candle{
+				ohlc(110, 111, 99, 100), ohlc(101, 104, 100, 103), ohlc(103, 105, 102, 104), ohlc(104, 105, 101, 102), ohlc(102, 103, 96, 97),
+			}, "下降"), "yes")
+		}, "yes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.detect(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentifyPatternsExtended(t *testing.T) {
+	tests := []struct {
+		name      string
+		candles   []model.Candle
+		trend     string
+		pattern   string
+		direction string
+		position  int
+	}{
+		{"marubozu", []model.Candle{ohlc(100, 110, 100, 110)}, "盘整", "光头光脚阳线", "看涨", 0},
+		{"spinning top", []model.Candle{ohlc(100, 106, 94, 101)}, "盘整", "纺锤线", "中性", 0},
+		{"belt hold", []model.Candle{ohlc(110, 110, 98, 100)}, "上升", "看跌捉腰带线", "看跌", 0},
+		{"tweezer bottom", []model.Candle{ohlc(110, 111, 100, 102), ohlc(102, 109, 100, 108)}, "下降", "平头底部", "看涨", -1},
+		{"kicker", []model.Candle{ohlc(110, 111, 99, 100), ohlc(111, 122, 110, 121)}, "盘整", "看涨反冲", "看涨", -1},
+		{"three inside down", []model.Candle{ohlc(100, 111, 99, 110), ohlc(108, 109, 104, 105), ohlc(105, 106, 97, 98)}, "上升", "三内部下跌", "看跌", -2},
+		{"three outside up", []model.Candle{ohlc(105, 106, 99, 100), ohlc(99, 108, 98, 107), ohlc(107, 111, 106, 110)}, "下降", "三外部上涨", "看涨", -2},
+		{"abandoned baby", []model.Candle{ohlc(110, 111, 99, 100), ohlc(96, 97, 95, 96), ohlc(99, 108, 98, 107)}, "下降", "看涨弃婴", "看涨", -2},
+		{"downside tasuki gap", []model.Candle{ohlc(105, 106, 99, 100), ohlc(98, 98, 92, 93), ohlc(95, 98.5, 94, 98.5)}, "下降", "下降跳空并列阴阳线", "看跌", -2},
+		{"rising three methods", []model.Candle{
+			ohlc(100, 111, 99, 110), ohlc(109, 110, 106, 107), ohlc(107, 108, 105, 106), ohlc(106, 108, 104, 107), ohlc(107, 113, 106, 112),
+		}, "上升", "上升三法", "看涨", -4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var found *model.CandlestickPattern
+			patterns := IdentifyPatterns(tt.candles, tt.trend)
+			for i := range patterns {
+				if patterns[i].Pattern == tt.pattern {
+					found = &patterns[i]
+				}
+			}
+			if found == nil {
+				t.Fatalf("pattern %q not identified, got %+v", tt.pattern, patterns)
+			}
+			if found.Direction != tt.direction || found.Position != tt.position {
+				t.Errorf("got direction %q position %d, want %q %d", found.Direction, found.Position, tt.direction, tt.position)
+			}
+			if found.Reliability <= 0 || found.Reliability > 1 || found.Type == "" {
+				t.Errorf("invalid type %q or reliability %v", found.Type, found.Reliability)
+			}
+		})
+	}
+}
@@ -0,0 +1,655 @@
+package indicator
+
+import (
+	"math"
+	"sort"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// chartSwing is a swing point used by chart pattern detection
+type chartSwing struct {
+	index  int
+	price  float64
+	isHigh bool
+}
+
+// priceLine is a straight line in (candle index, price) space
+type priceLine struct {
+	slope     float64
+	intercept float64
+}
+
+func (l priceLine) at(index int) float64 {
+	return l.slope*float64(index) + l.intercept
+}
+
+// lineThrough returns the line through two swing points
+func lineThrough(a, b chartSwing) priceLine {
+	if a.index == b.index {
+		return priceLine{intercept: a.price}
+	}
+	slope := (b.price - a.price) / float64(b.index-a.index)
+	return priceLine{slope: slope, intercept: a.price - slope*float64(a.index)}
+}
+
+// fitLine fits a least-squares line through (index, price) points
+func fitLine(indices []int, prices []float64) priceLine {
+	n := float64(len(indices))
+	if n == 0 {
+		return priceLine{}
+	}
+
+	var sumX, sumY, sumXY, sumXX float64
+	for i := range indices {
+		x := float64(indices[i])
+		sumX += x
+		sumY += prices[i]
+		sumXY += x * prices[i]
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return priceLine{intercept: sumY / n}
+	}
+	slope := (n*sumXY - sumX*sumY) / denominator
+	return priceLine{slope: slope, intercept: (sumY - slope*sumX) / n}
+}
+
+// DetectChartPatterns detects classical multi-bar chart patterns built on the confirmed swings:
+// head and shoulders (and inverse), double/triple tops and bottoms, triangles, wedges, flags and pennants
+func DetectChartPatterns(candles []model.Candle, swingPoints []model.SwingPoint) []model.ChartPattern {
+	patterns := make([]model.ChartPattern, 0)
+	if len(candles) < 30 {
+		return patterns
+	}
+
+	atr := CalculateATR(candles, 14).GetCurrentATR()
+	if atr <= 0 {
+		return patterns
+	}
+
+	mirrored := mirrorCandles(candles)
+	swings := chartSwings(swingPoints)
+	mirroredSwings := mirrorSwings(swings)
+
+	// Tops on the original series, bottoms on the mirrored series
+	patterns = append(patterns, detectHeadAndShoulders(candles, swings, atr, false)...)
+	patterns = append(patterns, detectHeadAndShoulders(mirrored, mirroredSwings, atr, true)...)
+	patterns = append(patterns, detectMultipleTops(candles, swings, atr, false)...)
+	patterns = append(patterns, detectMultipleTops(mirrored, mirroredSwings, atr, true)...)
+
+	if p := detectTriangleOrWedge(candles, swings, atr); p != nil {
+		patterns = append(patterns, *p)
+	}
+
+	if p := detectFlag(candles, atr, false); p != nil {
+		patterns = append(patterns, *p)
+	}
+	if p := detectFlag(mirrored, atr, true); p != nil {
+		patterns = append(patterns, *p)
+	}
+
+	// Keep failed patterns only while they are recent
+	recent := make([]model.ChartPattern, 0, len(patterns))
+	for _, p := range patterns {
+		if p.Status == "FAILED" && lastPointIndex(p) < len(candles)-50 {
+			continue
+		}
+		recent = append(recent, p)
+	}
+	patterns = dedupeChartPatterns(recent)
+
+	// Most recent patterns first
+	sort.SliceStable(patterns, func(i, j int) bool {
+		return lastPointIndex(patterns[i]) > lastPointIndex(patterns[j])
+	})
+
+	if len(patterns) > 10 {
+		patterns = patterns[:10]
+	}
+
+	return patterns
+}
+
+// dedupeChartPatterns keeps one pattern per swing: when patterns share a swing point, the one built on
+// more swings wins (a triple top over the double tops inside it), then the more reliable, then the more
+// recent. Overlapping double tops such as H1-H2 and H2-H3 are reduced to the later one.
+func dedupeChartPatterns(patterns []model.ChartPattern) []model.ChartPattern {
+	ranked := make([]model.ChartPattern, len(patterns))
+	copy(ranked, patterns)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if len(ranked[i].Points) != len(ranked[j].Points) {
+			return len(ranked[i].Points) > len(ranked[j].Points)
+		}
+		if ranked[i].Reliability != ranked[j].Reliability {
+			return ranked[i].Reliability > ranked[j].Reliability
+		}
+		return lastPointIndex(ranked[i]) > lastPointIndex(ranked[j])
+	})
+
+	type swingKey struct {
+		index int
+		price float64
+	}
+	claimed := make(map[swingKey]bool)
+	kept := make([]model.ChartPattern, 0, len(ranked))
+	for _, p := range ranked {
+		shared := false
+		for _, point := range p.Points {
+			if claimed[swingKey{point.Index, point.Price}] {
+				shared = true
+				break
+			}
+		}
+		if shared {
+			continue
+		}
+		for _, point := range p.Points {
+			claimed[swingKey{point.Index, point.Price}] = true
+		}
+		kept = append(kept, p)
+	}
+	return kept
+}
+
+// chartSwings converts the confirmed swings from the swing engine to chart swings
+func chartSwings(swings []model.SwingPoint) []chartSwing {
+	swings = ConfirmedSwings(swings)
+	result := make([]chartSwing, len(swings))
+	for i, s := range swings {
+		result[i] = chartSwing{index: s.Index, price: s.Price, isHigh: s.Type == model.SwingHigh}
+	}
+	return result
+}
+
+// mirrorSwings maps chart swings onto mirrored candles: prices negated, highs and lows swapped
+func mirrorSwings(swings []chartSwing) []chartSwing {
+	mirrored := make([]chartSwing, len(swings))
+	for i, s := range swings {
+		mirrored[i] = chartSwing{index: s.index, price: -s.price, isHigh: !s.isHigh}
+	}
+	return mirrored
+}
+
+// detectHeadAndShoulders finds H-L-H-L-H sequences with a dominant middle peak.
+// With inverse set, the candles and swings are mirrored and the results are flipped back.
+func detectHeadAndShoulders(candles []model.Candle, swings []chartSwing, atr float64, inverse bool) []model.ChartPattern {
+	patterns := make([]model.ChartPattern, 0)
+
+	for i := 0; i+4 < len(swings); i++ {
+		ls, l1, head, l2, rs := swings[i], swings[i+1], swings[i+2], swings[i+3], swings[i+4]
+		if !ls.isHigh || !head.isHigh || !rs.isHigh {
+			continue
+		}
+
+		// Head clearly above both shoulders, shoulders roughly level
+		if head.price-math.Max(ls.price, rs.price) < atr {
+			continue
+		}
+		if math.Abs(ls.price-rs.price) > atr {
+			continue
+		}
+
+		neckline := lineThrough(l1, l2)
+		height := head.price - neckline.at(head.index)
+		if height <= atr || ls.price <= neckline.at(ls.index) || rs.price <= neckline.at(rs.index) {
+			continue
+		}
+		// A top needs a prior advance from below the neckline
+		if !hasPriorMove(candles, ls.index, rs.index-ls.index, neckline.at(ls.index)) {
+			continue
+		}
+
+		expiry := rs.index + (rs.index - ls.index)
+		status, breakoutIdx := evaluateBreakout(candles, rs.index, expiry, false, neckline.at, func(int) float64 { return head.price })
+		levelIdx := min(len(candles)-1, expiry)
+		if breakoutIdx >= 0 {
+			levelIdx = breakoutIdx
+		}
+		breakout := neckline.at(levelIdx)
+
+		p := model.ChartPattern{
+			Pattern:   "头肩顶",
+			Code:      "HEAD_AND_SHOULDERS",
+			Type:      "反转",
+			Direction: "看跌",
+			Points: []model.PatternPoint{
+				patternPoint(candles, "LS", ls),
+				patternPoint(candles, "NL1", l1),
+				patternPoint(candles, "HEAD", head),
+				patternPoint(candles, "NL2", l2),
+				patternPoint(candles, "RS", rs),
+			},
+			Neckline: []model.PatternPoint{
+				linePoint(candles, "NL_START", ls.index, neckline),
+				linePoint(candles, "NL_END", levelIdx, neckline),
+			},
+			BreakoutLevel:     breakout,
+			InvalidationLevel: head.price,
+			Target:            breakout - height,
+			Status:            status,
+			BreakoutIndex:     breakoutIdx,
+			Reliability:       0.80,
+		}
+
+		if inverse {
+			flipPattern(&p)
+			p.Pattern = "头肩底"
+			p.Code = "INVERSE_HEAD_AND_SHOULDERS"
+			p.Direction = "看涨"
+		}
+		patterns = append(patterns, p)
+	}
+
+	return patterns
+}
+
+// detectMultipleTops finds double and triple tops (bottoms when inverse is set)
+func detectMultipleTops(candles []model.Candle, swings []chartSwing, atr float64, inverse bool) []model.ChartPattern {
+	patterns := make([]model.ChartPattern, 0)
+	tolerance := atr * 0.5
+
+	for i := 0; i+2 < len(swings); i++ {
+		if !swings[i].isHigh {
+			continue
+		}
+
+		// Prefer a triple top when three peaks line up
+		if i+4 < len(swings) {
+			h1, l1, h2, l2, h3 := swings[i], swings[i+1], swings[i+2], swings[i+3], swings[i+4]
+			top := math.Max(h1.price, math.Max(h2.price, h3.price))
+			bottom := math.Min(h1.price, math.Min(h2.price, h3.price))
+			valley := math.Min(l1.price, l2.price)
+
+			if top-bottom <= tolerance && bottom-valley >= atr*1.5 && hasPriorMove(candles, h1.index, h3.index-h1.index, valley) {
+				p := buildMultipleTop(candles, []chartSwing{h1, l1, h2, l2, h3}, valley, top, inverse)
+				patterns = append(patterns, p)
+				i += 3
+				continue
+			}
+		}
+
+		h1, valley, h2 := swings[i], swings[i+1], swings[i+2]
+		top := math.Max(h1.price, h2.price)
+		if h2.index-h1.index >= 5 && math.Abs(h1.price-h2.price) <= tolerance &&
+			math.Min(h1.price, h2.price)-valley.price >= atr*1.5 &&
+			hasPriorMove(candles, h1.index, h2.index-h1.index, valley.price) {
+			patterns = append(patterns, buildMultipleTop(candles, []chartSwing{h1, valley, h2}, valley.price, top, inverse))
+		}
+	}
+
+	return patterns
+}
+
+func buildMultipleTop(candles []model.Candle, anchors []chartSwing, valley, top float64, inverse bool) model.ChartPattern {
+	last := anchors[len(anchors)-1]
+	flat := func(int) float64 { return valley }
+	expiry := last.index + (last.index - anchors[0].index)
+	status, breakoutIdx := evaluateBreakout(candles, last.index, expiry, false, flat, func(int) float64 { return top })
+
+	points := make([]model.PatternPoint, len(anchors))
+	peak := 1
+	for i, a := range anchors {
+		label := "VALLEY"
+		if a.isHigh {
+			label = "PEAK" + string(rune('0'+peak))
+			peak++
+		}
+		points[i] = patternPoint(candles, label, a)
+	}
+
+	p := model.ChartPattern{
+		Pattern:   "双顶",
+		Code:      "DOUBLE_TOP",
+		Type:      "反转",
+		Direction: "看跌",
+		Points:    points,
+		Neckline: []model.PatternPoint{
+			linePoint(candles, "NL_START", anchors[0].index, priceLine{intercept: valley}),
+			linePoint(candles, "NL_END", len(candles)-1, priceLine{intercept: valley}),
+		},
+		BreakoutLevel:     valley,
+		InvalidationLevel: top,
+		Target:            valley - (top - valley),
+		Status:            status,
+		BreakoutIndex:     breakoutIdx,
+		Reliability:       0.75,
+	}
+
+	if len(anchors) == 5 {
+		p.Pattern = "三重顶"
+		p.Code = "TRIPLE_TOP"
+		p.Reliability = 0.80
+	}
+
+	if inverse {
+		flipPattern(&p)
+		p.Direction = "看涨"
+		if len(anchors) == 5 {
+			p.Pattern = "三重底"
+			p.Code = "TRIPLE_BOTTOM"
+		} else {
+			p.Pattern = "双底"
+			p.Code = "DOUBLE_BOTTOM"
+		}
+	}
+
+	return p
+}
+
+// detectTriangleOrWedge fits boundary lines through the latest swing highs and lows
+// and classifies converging structures as triangles or wedges
+func detectTriangleOrWedge(candles []model.Candle, swings []chartSwing, atr float64) *model.ChartPattern {
+	highs := make([]chartSwing, 0)
+	lows := make([]chartSwing, 0)
+	for i := len(swings) - 1; i >= 0 && (len(highs) < 3 || len(lows) < 3); i-- {
+		if swings[i].isHigh && len(highs) < 3 {
+			highs = append([]chartSwing{swings[i]}, highs...)
+		} else if !swings[i].isHigh && len(lows) < 3 {
+			lows = append([]chartSwing{swings[i]}, lows...)
+		}
+	}
+	if len(highs) < 2 || len(lows) < 2 || len(highs)+len(lows) < 5 {
+		return nil
+	}
+
+	upper := fitSwingLine(highs)
+	lower := fitSwingLine(lows)
+
+	start := int(math.Min(float64(highs[0].index), float64(lows[0].index)))
+	lastSwing := int(math.Max(float64(highs[len(highs)-1].index), float64(lows[len(lows)-1].index)))
+	end := len(candles) - 1
+
+	widthStart := upper.at(start) - lower.at(start)
+	widthEnd := upper.at(lastSwing) - lower.at(lastSwing)
+	if widthStart <= 0 || widthEnd <= 0 || widthEnd > widthStart*0.8 {
+		return nil
+	}
+
+	upperMove := upper.at(lastSwing) - upper.at(start)
+	lowerMove := lower.at(lastSwing) - lower.at(start)
+	upperFlat := math.Abs(upperMove) < atr
+	lowerFlat := math.Abs(lowerMove) < atr
+
+	var p model.ChartPattern
+	switch {
+	case upperFlat && lowerMove > atr:
+		p = model.ChartPattern{Pattern: "上升三角形", Code: "ASCENDING_TRIANGLE", Type: "持续", Direction: "看涨", Reliability: 0.75}
+	case lowerFlat && upperMove < -atr:
+		p = model.ChartPattern{Pattern: "下降三角形", Code: "DESCENDING_TRIANGLE", Type: "持续", Direction: "看跌", Reliability: 0.75}
+	case upperMove < -atr && lowerMove > atr:
+		// Symmetric triangles continue the prior trend
+		direction := "看涨"
+		if prior := start - 20; prior >= 0 && candles[prior].Close > candles[start].Close {
+			direction = "看跌"
+		}
+		p = model.ChartPattern{Pattern: "对称三角形", Code: "SYMMETRIC_TRIANGLE", Type: "持续", Direction: direction, Reliability: 0.65}
+	case upperMove > atr && lowerMove > upperMove:
+		p = model.ChartPattern{Pattern: "上升楔形", Code: "RISING_WEDGE", Type: "反转", Direction: "看跌", Reliability: 0.70}
+	case lowerMove < -atr && upperMove < lowerMove:
+		p = model.ChartPattern{Pattern: "下降楔形", Code: "FALLING_WEDGE", Type: "反转", Direction: "看涨", Reliability: 0.70}
+	default:
+		return nil
+	}
+
+	bullish := p.Direction == "看涨"
+	breakoutLine, invalidationLine := upper, lower
+	if !bullish {
+		breakoutLine, invalidationLine = lower, upper
+	}
+
+	// Triangles resolve before the apex; treat the pattern as failed after that
+	expiry := lastSwing + (lastSwing - start)
+	status, breakoutIdx := evaluateBreakout(candles, lastSwing, expiry, bullish, breakoutLine.at, invalidationLine.at)
+	levelIdx := min(end, expiry)
+	if breakoutIdx >= 0 {
+		levelIdx = breakoutIdx
+	}
+
+	p.BreakoutLevel = breakoutLine.at(levelIdx)
+	p.InvalidationLevel = invalidationLine.at(levelIdx)
+	if bullish {
+		p.Target = p.BreakoutLevel + widthStart
+	} else {
+		p.Target = p.BreakoutLevel - widthStart
+	}
+	p.Status = status
+	p.BreakoutIndex = breakoutIdx
+
+	for i, h := range highs {
+		p.Points = append(p.Points, patternPoint(candles, "UPPER"+string(rune('1'+i)), h))
+	}
+	for i, l := range lows {
+		p.Points = append(p.Points, patternPoint(candles, "LOWER"+string(rune('1'+i)), l))
+	}
+	p.Neckline = []model.PatternPoint{
+		linePoint(candles, "UPPER_START", start, upper),
+		linePoint(candles, "UPPER_END", levelIdx, upper),
+		linePoint(candles, "LOWER_START", start, lower),
+		linePoint(candles, "LOWER_END", levelIdx, lower),
+	}
+
+	return &p
+}
+
+func fitSwingLine(swings []chartSwing) priceLine {
+	indices := make([]int, len(swings))
+	prices := make([]float64, len(swings))
+	for i, s := range swings {
+		indices[i] = s.index
+		prices[i] = s.price
+	}
+	return fitLine(indices, prices)
+}
+
+// detectFlag looks for a sharp pole followed by a tight consolidation ending at the latest candle.
+// Bull flags/pennants are detected directly; bear variants via mirrored candles.
+func detectFlag(candles []model.Candle, atr float64, inverse bool) *model.ChartPattern {
+	n := len(candles)
+
+	for length := 15; length >= 5; length-- {
+		consStart := n - length
+		poleEnd := consStart - 1
+		if poleEnd-12 < 0 {
+			continue
+		}
+
+		// Pole starts at the lowest low 3-12 bars before the consolidation
+		poleStart := poleEnd - 3
+		for i := poleEnd - 12; i <= poleEnd-3; i++ {
+			if candles[i].Low < candles[poleStart].Low {
+				poleStart = i
+			}
+		}
+		poleLow := candles[poleStart].Low
+		poleHigh := poleLow
+		for i := poleStart; i <= poleEnd; i++ {
+			poleHigh = math.Max(poleHigh, candles[i].High)
+		}
+		height := poleHigh - poleLow
+		if height < atr*4 || candles[poleEnd].High < poleHigh-height*0.25 {
+			continue
+		}
+
+		// Consolidation excludes the latest candle, which is checked for the breakout
+		indices := make([]int, 0, length-1)
+		highs := make([]float64, 0, length-1)
+		lows := make([]float64, 0, length-1)
+		consHigh, consLow := -math.MaxFloat64, math.MaxFloat64
+		for i := consStart; i < n-1; i++ {
+			indices = append(indices, i)
+			highs = append(highs, candles[i].High)
+			lows = append(lows, candles[i].Low)
+			consHigh = math.Max(consHigh, candles[i].High)
+			consLow = math.Min(consLow, candles[i].Low)
+		}
+
+		if consHigh > poleHigh+height*0.1 || consLow < poleHigh-height*0.5 || consHigh-consLow > height*0.5 {
+			continue
+		}
+
+		upper := fitLine(indices, highs)
+		lower := fitLine(indices, lows)
+		upperMove := upper.slope * float64(length)
+		lowerMove := lower.slope * float64(length)
+
+		var p model.ChartPattern
+		if upperMove < 0 && lowerMove > 0 {
+			p = model.ChartPattern{Pattern: "上升三角旗", Code: "BULL_PENNANT", Type: "持续", Direction: "看涨", Reliability: 0.70}
+		} else if upperMove <= height*0.1 && lowerMove <= height*0.1 {
+			p = model.ChartPattern{Pattern: "上升旗形", Code: "BULL_FLAG", Type: "持续", Direction: "看涨", Reliability: 0.70}
+		} else {
+			continue
+		}
+
+		last := candles[n-1]
+		breakout := upper.at(n - 1)
+		p.Status = "FORMING"
+		p.BreakoutIndex = -1
+		if last.Close > breakout {
+			p.Status = "CONFIRMED"
+			p.BreakoutIndex = n - 1
+		} else if last.Close < lower.at(n-1) {
+			p.Status = "FAILED"
+		}
+
+		p.BreakoutLevel = breakout
+		p.InvalidationLevel = consLow
+		p.Target = breakout + height
+		p.Points = []model.PatternPoint{
+			patternPoint(candles, "POLE_START", chartSwing{index: poleStart, price: poleLow}),
+			patternPoint(candles, "POLE_END", chartSwing{index: poleEnd, price: poleHigh}),
+			patternPoint(candles, "FLAG_END", chartSwing{index: n - 2, price: candles[n-2].Close}),
+		}
+		p.Neckline = []model.PatternPoint{
+			linePoint(candles, "UPPER_START", consStart, upper),
+			linePoint(candles, "UPPER_END", n-1, upper),
+			linePoint(candles, "LOWER_START", consStart, lower),
+			linePoint(candles, "LOWER_END", n-1, lower),
+		}
+
+		if inverse {
+			flipPattern(&p)
+			p.Direction = "看跌"
+			if p.Code == "BULL_PENNANT" {
+				p.Pattern = "下降三角旗"
+				p.Code = "BEAR_PENNANT"
+			} else {
+				p.Pattern = "下降旗形"
+				p.Code = "BEAR_FLAG"
+			}
+		}
+		return &p
+	}
+
+	return nil
+}
+
+// evaluateBreakout scans candles after startIdx for a close through the breakout line
+// (CONFIRMED) or through the invalidation line (FAILED). Patterns with no breakout by
+// expiryIdx are FAILED; otherwise the pattern is still FORMING.
+func evaluateBreakout(
+	candles []model.Candle,
+	startIdx int,
+	expiryIdx int,
+	bullish bool,
+	breakout func(int) float64,
+	invalidation func(int) float64,
+) (string, int) {
+	for i := startIdx + 1; i < len(candles); i++ {
+		if i > expiryIdx {
+			return "FAILED", -1
+		}
+		close := candles[i].Close
+		if bullish {
+			if close > breakout(i) {
+				return "CONFIRMED", i
+			}
+			if close < invalidation(i) {
+				return "FAILED", -1
+			}
+		} else {
+			if close < breakout(i) {
+				return "CONFIRMED", i
+			}
+			if close > invalidation(i) {
+				return "FAILED", -1
+			}
+		}
+	}
+	return "FORMING", -1
+}
+
+// hasPriorMove reports whether price traded below level within span bars before index,
+// i.e. the top formed after an advance rather than inside a range
+func hasPriorMove(candles []model.Candle, index, span int, level float64) bool {
+	start := index - span
+	if start < 0 {
+		start = 0
+	}
+	for i := start; i < index; i++ {
+		if candles[i].Low < level {
+			return true
+		}
+	}
+	return false
+}
+
+// mirrorCandles negates prices so that bottoms can be detected with top detectors
+func mirrorCandles(candles []model.Candle) []model.Candle {
+	mirrored := make([]model.Candle, len(candles))
+	for i, c := range candles {
+		mirrored[i] = model.Candle{
+			Timestamp: c.Timestamp,
+			Open:      -c.Open,
+			High:      -c.Low,
+			Low:       -c.High,
+			Close:     -c.Close,
+			Volume:    c.Volume,
+		}
+	}
+	return mirrored
+}
+
+// flipPattern maps a pattern detected on mirrored candles back to real prices
+func flipPattern(p *model.ChartPattern) {
+	for i := range p.Points {
+		p.Points[i].Price = -p.Points[i].Price
+	}
+	for i := range p.Neckline {
+		p.Neckline[i].Price = -p.Neckline[i].Price
+	}
+	p.BreakoutLevel = -p.BreakoutLevel
+	p.InvalidationLevel = -p.InvalidationLevel
+	p.Target = -p.Target
+}
+
+func patternPoint(candles []model.Candle, label string, s chartSwing) model.PatternPoint {
+	return model.PatternPoint{
+		Label:     label,
+		Index:     s.index,
+		Timestamp: candles[s.index].Timestamp,
+		Price:     s.price,
+	}
+}
+
+func linePoint(candles []model.Candle, label string, index int, line priceLine) model.PatternPoint {
+	return model.PatternPoint{
+		Label:     label,
+		Index:     index,
+		Timestamp: candles[index].Timestamp,
+		Price:     line.at(index),
+	}
+}
+
+func lastPointIndex(p model.ChartPattern) int {
+	last := 0
+	for _, point := range p.Points {
+		if point.Index > last {
+			last = point.Index
+		}
+	}
+	return last
+}
@@ -0,0 +1,133 @@
+package indicator
+
+import (
+	"testing"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// reflectCandles mirrors the candles around price 100, turning tops into bottoms
+func reflectCandles(candles []model.Candle) []model.Candle {
+	reflected := make([]model.Candle, len(candles))
+	for i, c := range candles {
+		reflected[i] = model.Candle{Timestamp: c.Timestamp, Open: 200 - c.Open, High: 200 - c.Low, Low: 200 - c.High, Close: 200 - c.Close, Volume: c.Volume}
+	}
+	return reflected
+}
+
+// poleCandles is a quiet range followed by a 30 point pole over 6 bars
+func poleCandles() []model.Candle {
+	return zigzagCandles([]float64{100, 102, 100, 102, 100, 102, 100, 130}, 6)
+}
+
+// flagCandles drifts down from the pole top in a parallel channel
+func flagCandles() []model.Candle {
+	candles := poleCandles()
+	return append(candles, zigzagCandles([]float64{130, 126}, 8)[1:]...)
+}
+
+// pennantCandles narrows from the pole top around 126
+func pennantCandles() []model.Candle {
+	candles := poleCandles()
+	for k := 0; k < 10; k++ {
+		p := 126.0
+		candles = append(candles, model.Candle{Open: p, High: 130 - 0.4*float64(k), Low: 123 + 0.3*float64(k), Close: p, Volume: 1})
+	}
+	for i := range candles {
+		candles[i].Timestamp = int64(i) * hourMs
+	}
+	return candles
+}
+
+func TestDetectChartPatterns(t *testing.T) {
+	headAndShoulders := zigzagCandles([]float64{80, 100, 90, 110, 90, 100, 85}, 6)
+	doubleTop := zigzagCandles([]float64{90, 80, 100, 88, 100.3, 80}, 6)
+	tripleTop := zigzagCandles([]float64{80, 100, 88, 100, 88, 100, 80}, 6)
+	ascending := zigzagCandles([]float64{95, 100, 86, 100, 92, 100, 96}, 6)
+	symmetric := zigzagCandles([]float64{90, 110, 86, 106, 92, 102, 96}, 6)
+	risingWedge := zigzagCandles([]float64{85, 100, 88, 104, 96, 108, 100}, 6)
+	bullFlag := flagCandles()
+	bullPennant := pennantCandles()
+
+	tests := []struct {
+		name      string
+		candles   []model.Candle
+		code      string
+		direction string
+		status    string // Empty when any status is fine
+	}{
+		{"head and shoulders", headAndShoulders, "HEAD_AND_SHOULDERS", "看跌", "CONFIRMED"},
+		{"inverse head and shoulders", reflectCandles(headAndShoulders), "INVERSE_HEAD_AND_SHOULDERS", "看涨", "CONFIRMED"},
+		{"double top", doubleTop, "DOUBLE_TOP", "看跌", "CONFIRMED"},
+		{"double bottom", reflectCandles(doubleTop), "DOUBLE_BOTTOM", "看涨", "CONFIRMED"},
+		{"triple top", tripleTop, "TRIPLE_TOP", "看跌", "CONFIRMED"},
+		{"triple bottom", reflectCandles(tripleTop), "TRIPLE_BOTTOM", "看涨", "CONFIRMED"},
+		{"ascending triangle", ascending, "ASCENDING_TRIANGLE", "看涨", ""},
+		{"descending triangle", reflectCandles(ascending), "DESCENDING_TRIANGLE", "看跌", ""},
+		{"symmetric triangle", symmetric, "SYMMETRIC_TRIANGLE", "看涨", ""},
+		{"rising wedge", risingWedge, "RISING_WEDGE", "看跌", ""},
+		{"falling wedge", reflectCandles(risingWedge), "FALLING_WEDGE", "看涨", ""},
+		{"bull flag", bullFlag, "BULL_FLAG", "看涨", "FORMING"},
+		{"bear flag", reflectCandles(bullFlag), "BEAR_FLAG", "看跌", "FORMING"},
+		{"bull pennant", bullPennant, "BULL_PENNANT", "看涨", "FORMING"},
+		{"bear pennant", reflectCandles(bullPennant), "BEAR_PENNANT", "看跌", "FORMING"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patterns := DetectChartPatterns(tt.candles, FindSwings(tt.candles, DefaultSwingConfig()))
+			var found *model.ChartPattern
+			codes := make([]string, len(patterns))
+			for i := range patterns {
+				codes[i] = patterns[i].Code
+				if patterns[i].Code == tt.code {
+					found = &patterns[i]
+				}
+			}
+			if found == nil {
+				t.Fatalf("no %s among %v", tt.code, codes)
+			}
+			if found.Direction != tt.direction {
+				t.Errorf("direction %s, want %s", found.Direction, tt.direction)
+			}
+			if tt.status != "" && found.Status != tt.status {
+				t.Errorf("status %s, want %s", found.Status, tt.status)
+			}
+			assertNoSharedSwings(t, patterns)
+		})
+	}
+}
+
+func TestDetectChartPatternsDedupesOverlappingTops(t *testing.T) {
+	// Rising peaks: H1-H2 and H2-H3 are both double tops, H1-H3 is too wide for a triple top
+	candles := zigzagCandles([]float64{80, 100, 87, 100.8, 88, 101.6, 80}, 6)
+	patterns := DetectChartPatterns(candles, FindSwings(candles, DefaultSwingConfig()))
+	assertNoSharedSwings(t, patterns)
+
+	var tops []model.ChartPattern
+	for _, p := range patterns {
+		if p.Code == "DOUBLE_TOP" || p.Code == "TRIPLE_TOP" {
+			tops = append(tops, p)
+		}
+	}
+	if len(tops) != 1 || tops[0].Code != "DOUBLE_TOP" {
+		t.Fatalf("got %d tops %+v, want a single double top", len(tops), tops)
+	}
+	// The later double top wins
+	if first := tops[0].Points[0]; first.Index != 18 {
+		t.Errorf("double top starts at bar %d, want the second peak at 18", first.Index)
+	}
+}
+
+func assertNoSharedSwings(t *testing.T, patterns []model.ChartPattern) {
+	t.Helper()
+	owner := make(map[int]string)
+	for _, p := range patterns {
+		for _, point := range p.Points {
+			if other, ok := owner[point.Index]; ok {
+				t.Errorf("%s and %s share the swing at %d", other, p.Code, point.Index)
+			}
+			owner[point.Index] = p.Code
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package indicator
+
+import (
+	"math"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// Pattern confirmation states
+const (
+	PatternPending   = "PENDING"
+	PatternConfirmed = "CONFIRMED"
+	PatternFailed    = "FAILED"
+)
+
+// PatternConfirmationBars is how many closed candles after completion a pattern has to confirm
+const PatternConfirmationBars = 3
+
+// PatternConfirmationLevels returns the close that confirms a directional pattern (beyond the body
+// of the completion candle) and the close that invalidates it (beyond the pattern's extreme).
+// Position must be relative to the last of candles. Neutral patterns return ok=false.
+func PatternConfirmationLevels(candles []model.Candle, p model.CandlestickPattern) (trigger, invalidation float64, ok bool) {
+	n := len(candles)
+	end := -1
+	for i := n - 1; i >= 0; i-- {
+		if candles[i].Timestamp == p.Timestamp {
+			end = i
+			break
+		}
+	}
+	start := n - 1 + p.Position
+	if end < 0 || start < 0 || start > end {
+		return 0, 0, false
+	}
+
+	completion := candles[end]
+	switch p.Direction {
+	case "看涨":
+		invalidation = completion.Low
+		for i := start; i <= end; i++ {
+			invalidation = math.Min(invalidation, candles[i].Low)
+		}
+		return math.Max(completion.Open, completion.Close), invalidation, true
+	case "看跌":
+		invalidation = completion.High
+		for i := start; i <= end; i++ {
+			invalidation = math.Max(invalidation, candles[i].High)
+		}
+		return math.Min(completion.Open, completion.Close), invalidation, true
+	}
+
+	return 0, 0, false
+}
+
+// ResolvePatternConfirmation walks the closed candles after the pattern's completion candle.
+// The first close beyond the invalidation level fails the pattern, the first close beyond the trigger
+// confirms it, and a pattern still unresolved after PatternConfirmationBars candles fails.
+// Returns the state and the timestamp of the resolving candle (0 while pending).
+func ResolvePatternConfirmation(direction string, trigger, invalidation float64, after []model.Candle) (string, int64) {
+	sign := 1.0
+	if direction == "看跌" {
+		sign = -1
+	}
+
+	for _, c := range after[:min(len(after), PatternConfirmationBars)] {
+		if sign*(c.Close-invalidation) < 0 {
+			return PatternFailed, c.Timestamp
+		}
+		if sign*(c.Close-trigger) > 0 {
+			return PatternConfirmed, c.Timestamp
+		}
+	}
+	if len(after) >= PatternConfirmationBars {
+		return PatternFailed, after[PatternConfirmationBars-1].Timestamp
+	}
+
+	return PatternPending, 0
+}
+
+// ConfirmPatterns sets the confirmation state of each directional pattern from the candles after it.
+// Position must be relative to the last of candles, as returned by IdentifyPatterns or ScanPatterns.
+func ConfirmPatterns(candles []model.Candle, patterns []model.CandlestickPattern) {
+	for i := range patterns {
+		p := &patterns[i]
+		trigger, invalidation, ok := PatternConfirmationLevels(candles, *p)
+		if !ok {
+			continue
+		}
+
+		var after []model.Candle
+		for j := len(candles) - 1; j >= 0; j-- {
+			if candles[j].Timestamp == p.Timestamp {
+				after = candles[j+1:]
+				break
+			}
+		}
+
+		p.ConfirmationPrice = trigger
+		p.InvalidationPrice = invalidation
+		p.Confirmation, p.ResolvedAt = ResolvePatternConfirmation(p.Direction, trigger, invalidation, after)
+	}
+}
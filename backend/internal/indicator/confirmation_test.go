@@ -0,0 +1,67 @@
+package indicator
+
+import (
+	"testing"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+func TestResolvePatternConfirmation(t *testing.T) {
+	closes := func(values ...float64) []model.Candle {
+		candles := make([]model.Candle, len(values))
+		for i, v := range values {
+			candles[i] = model.Candle{Timestamp: int64(i + 1), Open: v, High: v, Low: v, Close: v}
+		}
+		return candles
+	}
+
+	tests := []struct {
+		name       string
+		direction  string
+		after      []model.Candle
+		want       string
+		resolvedAt int64
+	}{
+		{"bullish no candles yet", "看涨", nil, PatternPending, 0},
+		{"bullish waiting", "看涨", closes(102, 103), PatternPending, 0},
+		{"bullish confirmed", "看涨", closes(103, 106), PatternConfirmed, 2},
+		{"bullish invalidated", "看涨", closes(101, 94, 110), PatternFailed, 2},
+		{"bullish expired", "看涨", closes(101, 102, 103, 110), PatternFailed, 3},
+		{"bearish confirmed", "看跌", closes(94), PatternConfirmed, 1},
+		{"bearish invalidated", "看跌", closes(97, 111), PatternFailed, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trigger, invalidation := 105.0, 95.0
+			if tt.direction == "看跌" {
+				trigger, invalidation = 95.0, 110.0
+			}
+			got, resolvedAt := ResolvePatternConfirmation(tt.direction, trigger, invalidation, tt.after)
+			if got != tt.want || resolvedAt != tt.resolvedAt {
+				t.Errorf("got %s at %d, want %s at %d", got, resolvedAt, tt.want, tt.resolvedAt)
+			}
+		})
+	}
+}
+
+func TestConfirmPatterns(t *testing.T) {
+	candles := []model.Candle{
+		{Timestamp: 1, Open: 110, High: 111, Low: 99, Close: 100},
+		{Timestamp: 2, Open: 99, High: 108, Low: 98, Close: 107}, // Bullish engulfing completes
+		{Timestamp: 3, Open: 107, High: 110, Low: 106, Close: 109},
+	}
+	patterns := []model.CandlestickPattern{
+		{Pattern: "看涨吞没", Direction: "看涨", Position: -2, Timestamp: 2},
+		{Pattern: "十字星", Direction: "中性", Position: 0, Timestamp: 3},
+	}
+
+	ConfirmPatterns(candles, patterns)
+
+	if p := patterns[0]; p.Confirmation != PatternConfirmed || p.ResolvedAt != 3 || p.ConfirmationPrice != 107 || p.InvalidationPrice != 98 {
+		t.Errorf("engulfing: got %+v", p)
+	}
+	if p := patterns[1]; p.Confirmation != "" {
+		t.Errorf("neutral pattern got state %q", p.Confirmation)
+	}
+}
@@ -0,0 +1,120 @@
+package indicator
+
+import (
+	"math"
+	"sort"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// AlignCloses joins candle series on their timestamps, keeping only timestamps present in every
+// series, so a gap in one symbol drops that bar for all of them. Returns the shared timestamps and
+// each series' closes at those timestamps, in input order.
+func AlignCloses(series [][]model.Candle) ([]int64, [][]float64) {
+	if len(series) == 0 {
+		return nil, nil
+	}
+
+	counts := make(map[int64]int)
+	for _, candles := range series {
+		seen := make(map[int64]bool, len(candles))
+		for _, c := range candles {
+			if !seen[c.Timestamp] {
+				seen[c.Timestamp] = true
+				counts[c.Timestamp]++
+			}
+		}
+	}
+
+	timestamps := make([]int64, 0, len(counts))
+	for ts, count := range counts {
+		if count == len(series) {
+			timestamps = append(timestamps, ts)
+		}
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+	closes := make([][]float64, len(series))
+	for k, candles := range series {
+		byTime := make(map[int64]float64, len(candles))
+		for _, c := range candles {
+			byTime[c.Timestamp] = c.Close
+		}
+		closes[k] = make([]float64, len(timestamps))
+		for i, ts := range timestamps {
+			closes[k][i] = byTime[ts]
+		}
+	}
+
+	return timestamps, closes
+}
+
+// LogReturns returns ln(close[i]/close[i-1]); non-positive prices yield a zero return
+func LogReturns(closes []float64) []float64 {
+	if len(closes) < 2 {
+		return []float64{}
+	}
+	returns := make([]float64, len(closes)-1)
+	for i := 1; i < len(closes); i++ {
+		if closes[i] > 0 && closes[i-1] > 0 {
+			returns[i-1] = math.Log(closes[i] / closes[i-1])
+		}
+	}
+	return returns
+}
+
+// Correlation returns the Pearson correlation of two equally long series (0 when undefined)
+func Correlation(a, b []float64) float64 {
+	cov, varA, varB := covariance(a, b)
+	if varA <= 0 || varB <= 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varA*varB)
+}
+
+// Beta returns the slope of asset returns on benchmark returns: cov(asset, benchmark) / var(benchmark)
+func Beta(asset, benchmark []float64) float64 {
+	cov, _, varBench := covariance(asset, benchmark)
+	if varBench <= 0 {
+		return 0
+	}
+	return cov / varBench
+}
+
+// RollingBeta computes correlation and beta to the benchmark over every window of returns.
+// timestamps are the aligned candle timestamps, one more than the returns.
+func RollingBeta(asset, benchmark []float64, timestamps []int64, window int) []model.RollingBeta {
+	points := make([]model.RollingBeta, 0)
+	if window < 2 || len(asset) != len(benchmark) || len(timestamps) != len(asset)+1 {
+		return points
+	}
+
+	for end := window; end <= len(asset); end++ {
+		a, b := asset[end-window:end], benchmark[end-window:end]
+		points = append(points, model.RollingBeta{
+			Timestamp:   timestamps[end],
+			Correlation: Correlation(a, b),
+			Beta:        Beta(a, b),
+		})
+	}
+
+	return points
+}
+
+// covariance returns the sample covariance of a and b and their sample variances
+func covariance(a, b []float64) (cov, varA, varB float64) {
+	n := len(a)
+	if n < 2 || len(b) != n {
+		return 0, 0, 0
+	}
+
+	meanA, meanB := average(a), average(b)
+	for i := 0; i < n; i++ {
+		da, db := a[i]-meanA, b[i]-meanB
+		cov += da * db
+		varA += da * da
+		varB += db * db
+	}
+	d := float64(n - 1)
+	return cov / d, varA / d, varB / d
+}
@@ -0,0 +1,54 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+func TestAlignCloses(t *testing.T) {
+	a := []model.Candle{{Timestamp: 1, Close: 10}, {Timestamp: 2, Close: 11}, {Timestamp: 3, Close: 12}, {Timestamp: 4, Close: 13}}
+	b := []model.Candle{{Timestamp: 1, Close: 20}, {Timestamp: 3, Close: 22}, {Timestamp: 4, Close: 23}, {Timestamp: 5, Close: 24}}
+
+	timestamps, closes := AlignCloses([][]model.Candle{a, b})
+	if len(timestamps) != 3 || timestamps[0] != 1 || timestamps[1] != 3 || timestamps[2] != 4 {
+		t.Fatalf("timestamps %v", timestamps)
+	}
+	if closes[0][1] != 12 || closes[1][1] != 22 {
+		t.Errorf("closes %v", closes)
+	}
+}
+
+func TestCorrelationAndBeta(t *testing.T) {
+	bench := LogReturns(closesOf(randomWalkCandles(200, 11)))
+
+	// An asset moving twice the benchmark: correlation 1, beta 2
+	levered := make([]float64, len(bench))
+	inverse := make([]float64, len(bench))
+	for i, r := range bench {
+		levered[i] = 2 * r
+		inverse[i] = -r
+	}
+
+	if c := Correlation(levered, bench); math.Abs(c-1) > 1e-9 {
+		t.Errorf("correlation %v, want 1", c)
+	}
+	if b := Beta(levered, bench); math.Abs(b-2) > 1e-9 {
+		t.Errorf("beta %v, want 2", b)
+	}
+	if c := Correlation(inverse, bench); math.Abs(c+1) > 1e-9 {
+		t.Errorf("inverse correlation %v, want -1", c)
+	}
+
+	timestamps := make([]int64, len(bench)+1)
+	rolling := RollingBeta(levered, bench, timestamps, 30)
+	if len(rolling) != len(bench)-29 {
+		t.Fatalf("rolling points %d, want %d", len(rolling), len(bench)-29)
+	}
+	for _, p := range rolling {
+		if math.Abs(p.Beta-2) > 1e-9 {
+			t.Fatalf("rolling beta %v, want 2", p.Beta)
+		}
+	}
+}
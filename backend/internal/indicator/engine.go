@@ -0,0 +1,148 @@
+package indicator
+
+import (
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// EngineConfig holds the interval-dependent settings of an IndicatorEngine
+type EngineConfig struct {
+	Interval      string              `json:"interval"` // Pivots use PivotTimeframe(Interval)
+	VolumeProfile VolumeProfileConfig `json:"volume_profile"`
+	Window        int                 `json:"window"` // Candles a "visible" volume profile covers
+}
+
+// IndicatorEngine maintains the standard indicator set incrementally for one symbol/interval.
+// Feed it closed candles in order with Update; use Preview for the in-progress candle.
+type IndicatorEngine struct {
+	Config        EngineConfig            `json:"config"`
+	LastTimestamp int64                   `json:"last_timestamp"`
+	Count         int                     `json:"count"`
+	EMA9          *StreamingEMA           `json:"ema9"`
+	EMA21         *StreamingEMA           `json:"ema21"`
+	EMA50         *StreamingEMA           `json:"ema50"`
+	EMA200        *StreamingEMA           `json:"ema200"`
+	ATR           *StreamingATR           `json:"atr"`
+	MACD          *StreamingMACD          `json:"macd"`
+	RSI6          *StreamingRSI           `json:"rsi6"`
+	RSI14         *StreamingRSI           `json:"rsi14"`
+	KDJ           *StreamingKDJ           `json:"kdj"`
+	VolumeProfile *StreamingVolumeProfile `json:"volume_profile"`
+	Pivots        *StreamingPivots        `json:"pivots"`
+}
+
+// NewIndicatorEngine creates an empty indicator engine
+func NewIndicatorEngine(config EngineConfig) *IndicatorEngine {
+	return &IndicatorEngine{
+		Config:        config,
+		EMA9:          NewStreamingEMA(9),
+		EMA21:         NewStreamingEMA(21),
+		EMA50:         NewStreamingEMA(50),
+		EMA200:        NewStreamingEMA(200),
+		ATR:           NewStreamingATR(14),
+		MACD:          NewStreamingMACD(),
+		RSI6:          NewStreamingRSI(6),
+		RSI14:         NewStreamingRSI(14),
+		KDJ:           NewStreamingKDJ(),
+		VolumeProfile: NewStreamingVolumeProfile(config.VolumeProfile, config.Window),
+		Pivots:        NewStreamingPivots(PivotTimeframe(config.Interval)),
+	}
+}
+
+// NewIndicatorEngineFromCandles creates an engine warmed up with historical candles
+func NewIndicatorEngineFromCandles(config EngineConfig, candles []model.Candle) *IndicatorEngine {
+	engine := NewIndicatorEngine(config)
+	for _, candle := range candles {
+		engine.Update(candle)
+	}
+	return engine
+}
+
+// Update feeds a closed candle into every indicator.
+// Candles at or before the last processed timestamp are ignored.
+func (e *IndicatorEngine) Update(candle model.Candle) bool {
+	if e.Count > 0 && candle.Timestamp <= e.LastTimestamp {
+		return false
+	}
+
+	e.EMA9.Update(candle.Close)
+	e.EMA21.Update(candle.Close)
+	e.EMA50.Update(candle.Close)
+	e.EMA200.Update(candle.Close)
+	e.ATR.Update(candle)
+	e.MACD.Update(candle.Close)
+	e.RSI6.Update(candle.Close)
+	e.RSI14.Update(candle.Close)
+	e.KDJ.Update(candle)
+	e.VolumeProfile.Update(candle)
+	e.Pivots.Update(candle)
+
+	e.LastTimestamp = candle.Timestamp
+	e.Count++
+	return true
+}
+
+// Indicators returns the current indicator values
+func (e *IndicatorEngine) Indicators() model.Indicators {
+	return model.Indicators{
+		MACD: e.MACD.Value(),
+		KDJ:  e.KDJ.Value(),
+		RSI: model.RSIIndicator{
+			RSI6:  e.RSI6.Value(),
+			RSI14: e.RSI14.Value(),
+		},
+		ATR: model.ATRIndicator{
+			Value:  e.ATR.Value(),
+			Period: e.ATR.Period,
+		},
+		EMA: model.EMAIndicator{
+			EMA9:   e.EMA9.Value(),
+			EMA21:  e.EMA21.Value(),
+			EMA50:  e.EMA50.Value(),
+			EMA200: e.EMA200.Value(),
+		},
+		VolumeProfile: e.VolumeProfile.Value(),
+		Pivots:        e.Pivots.Value(),
+	}
+}
+
+// Continues reports whether the engine has seen the candles up to the first of the given ones, so
+// feeding them leaves no gap
+func (e *IndicatorEngine) Continues(candles []model.Candle) bool {
+	return e.Count > 0 && len(candles) > 0 && e.LastTimestamp >= candles[0].Timestamp
+}
+
+// Preview returns the indicator values as if the given (unclosed) candle were appended,
+// without changing the engine state
+func (e *IndicatorEngine) Preview(candle model.Candle) model.Indicators {
+	preview := e.Snapshot()
+	preview.Update(candle)
+	return preview.Indicators()
+}
+
+// Snapshot returns a deep copy of the engine state that can be stored or JSON-encoded
+func (e *IndicatorEngine) Snapshot() *IndicatorEngine {
+	ema9, ema21, ema50, ema200 := *e.EMA9, *e.EMA21, *e.EMA50, *e.EMA200
+	atr, rsi6, rsi14, pivots := *e.ATR, *e.RSI6, *e.RSI14, *e.Pivots
+
+	return &IndicatorEngine{
+		Config:        e.Config,
+		LastTimestamp: e.LastTimestamp,
+		Count:         e.Count,
+		EMA9:          &ema9,
+		EMA21:         &ema21,
+		EMA50:         &ema50,
+		EMA200:        &ema200,
+		ATR:           &atr,
+		MACD:          e.MACD.Snapshot(),
+		RSI6:          &rsi6,
+		RSI14:         &rsi14,
+		KDJ:           e.KDJ.Snapshot(),
+		VolumeProfile: e.VolumeProfile.Snapshot(),
+		Pivots:        &pivots,
+	}
+}
+
+// Restore replaces the engine state with a previously taken snapshot
+func (e *IndicatorEngine) Restore(snapshot *IndicatorEngine) {
+	*e = *snapshot.Snapshot()
+}
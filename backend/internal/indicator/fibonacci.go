@@ -73,13 +73,33 @@ func CalculateFibonacciLevels(high, low float64, isUptrend bool) *FibonacciLevel
 	}
 }
 
-// FindSwingHighLow finds the most recent swing high and low in the candles
-func FindSwingHighLow(candles []model.Candle, lookback int) (high, low float64, highIndex, lowIndex int) {
+// FindSwingHighLow finds the highest swing high and lowest swing low within the lookback window.
+// The swings may include the open leg, so a running extreme can anchor the levels; if the window holds
+// no swings the absolute extremes are used.
+func FindSwingHighLow(candles []model.Candle, swings []model.SwingPoint, lookback int) (high, low float64, highIndex, lowIndex int) {
 	if len(candles) < lookback {
 		lookback = len(candles)
 	}
 
 	startIndex := len(candles) - lookback
+	highIndex, lowIndex = -1, -1
+
+	for _, swing := range swings {
+		if swing.Index < startIndex {
+			continue
+		}
+		if swing.Type == model.SwingHigh && (highIndex < 0 || swing.Price > high) {
+			high, highIndex = swing.Price, swing.Index
+		}
+		if swing.Type == model.SwingLow && (lowIndex < 0 || swing.Price < low) {
+			low, lowIndex = swing.Price, swing.Index
+		}
+	}
+
+	if highIndex >= 0 && lowIndex >= 0 {
+		return high, low, highIndex, lowIndex
+	}
+
 	high = candles[startIndex].High
 	low = candles[startIndex].Low
 	highIndex = startIndex
@@ -99,13 +119,13 @@ func FindSwingHighLow(candles []model.Candle, lookback int) (high, low float64,
 	return high, low, highIndex, lowIndex
 }
 
-// CalculateFibonacciFromCandles calculates Fibonacci levels from candle data
-func CalculateFibonacciFromCandles(candles []model.Candle, lookback int) *FibonacciLevels {
+// CalculateFibonacciFromCandles calculates Fibonacci levels from the swings within the lookback window
+func CalculateFibonacciFromCandles(candles []model.Candle, swings []model.SwingPoint, lookback int) *FibonacciLevels {
 	if len(candles) < 2 {
 		return nil
 	}
 
-	high, low, highIndex, lowIndex := FindSwingHighLow(candles, lookback)
+	high, low, highIndex, lowIndex := FindSwingHighLow(candles, swings, lookback)
 
 	// Determine trend direction based on which came first
 	isUptrend := lowIndex < highIndex
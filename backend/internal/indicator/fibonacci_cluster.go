@@ -0,0 +1,177 @@
+package indicator
+
+import (
+	"math"
+	"sort"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// fibClusterSwings is how many recent significant swings feed the cluster analysis
+const fibClusterSwings = 6
+
+var fibClusterRetracements = []float64{0.382, 0.5, 0.618, 0.786}
+var fibClusterExtensions = []float64{1.272, 1.618}
+var fibTimeSequence = []int{1, 2, 3, 5, 8, 13, 21, 34, 55, 89}
+
+// CalculateFibonacciConfluence computes Fibonacci retracements and extensions from every pair of
+// opposite swings among the last few swings, clusters levels from different pairs that overlap, and
+// projects Fibonacci time zones from the most recent confirmed swing
+func CalculateFibonacciConfluence(candles []model.Candle, swings []model.SwingPoint) *model.FibonacciConfluence {
+	if len(candles) < 50 {
+		return nil
+	}
+
+	atr := CalculateATR(candles, 14).GetCurrentATR()
+	if atr <= 0 {
+		return nil
+	}
+
+	if len(swings) > fibClusterSwings {
+		swings = swings[len(swings)-fibClusterSwings:]
+	}
+	if len(swings) < 2 {
+		return nil
+	}
+
+	// Every opposite-type swing pair, oldest anchor first
+	pairs := make([]model.FibSwingPair, 0)
+	for i := 0; i < len(swings); i++ {
+		for j := i + 1; j < len(swings); j++ {
+			if swings[i].Type == swings[j].Type {
+				continue
+			}
+			direction := "UP"
+			if swings[j].Type == model.SwingLow {
+				direction = "DOWN"
+			}
+			pairs = append(pairs, model.FibSwingPair{
+				ID:        len(pairs) + 1,
+				From:      swings[i],
+				To:        swings[j],
+				Direction: direction,
+			})
+		}
+	}
+
+	// Levels from each pair: retracements back toward From, extensions beyond To
+	levels := make([]model.FibLevelRef, 0)
+	for _, pair := range pairs {
+		move := pair.To.Price - pair.From.Price
+		for _, ratio := range fibClusterRetracements {
+			levels = append(levels, model.FibLevelRef{
+				PairID: pair.ID,
+				Kind:   "RETRACEMENT",
+				Ratio:  ratio,
+				Price:  pair.To.Price - move*ratio,
+			})
+		}
+		for _, ratio := range fibClusterExtensions {
+			levels = append(levels, model.FibLevelRef{
+				PairID: pair.ID,
+				Kind:   "EXTENSION",
+				Ratio:  ratio,
+				Price:  pair.From.Price + move*ratio,
+			})
+		}
+	}
+
+	currentPrice := candles[len(candles)-1].Close
+	clusters := clusterFibLevels(levels, 0.15*atr, currentPrice)
+
+	return &model.FibonacciConfluence{
+		Pairs:     pairs,
+		Clusters:  clusters,
+		TimeZones: fibTimeZones(candles, swings),
+	}
+}
+
+// clusterFibLevels groups levels within tolerance of each other; a cluster needs levels from at least two swing pairs
+func clusterFibLevels(levels []model.FibLevelRef, tolerance, currentPrice float64) []model.FibCluster {
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].Price < levels[j].Price
+	})
+
+	clusters := make([]model.FibCluster, 0)
+	for i := 0; i < len(levels); {
+		j := i + 1
+		for j < len(levels) && levels[j].Price-levels[i].Price <= tolerance {
+			j++
+		}
+
+		members := levels[i:j]
+		pairIDs := make([]int, 0)
+		seen := make(map[int]bool)
+		sum := 0.0
+		for _, level := range members {
+			sum += level.Price
+			if !seen[level.PairID] {
+				seen[level.PairID] = true
+				pairIDs = append(pairIDs, level.PairID)
+			}
+		}
+
+		if len(pairIDs) >= 2 {
+			sort.Ints(pairIDs)
+			price := sum / float64(len(members))
+			clusterType := "SUPPORT"
+			if price > currentPrice {
+				clusterType = "RESISTANCE"
+			}
+			clusters = append(clusters, model.FibCluster{
+				Price:     price,
+				PriceLow:  members[0].Price,
+				PriceHigh: members[len(members)-1].Price,
+				Levels:    append([]model.FibLevelRef(nil), members...),
+				PairIDs:   pairIDs,
+				Strength:  math.Min(1, float64(len(pairIDs))/5),
+				Type:      clusterType,
+			})
+		}
+
+		i = j
+	}
+
+	// Strongest first, then nearest to price
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if clusters[i].Strength != clusters[j].Strength {
+			return clusters[i].Strength > clusters[j].Strength
+		}
+		return math.Abs(clusters[i].Price-currentPrice) < math.Abs(clusters[j].Price-currentPrice)
+	})
+	if len(clusters) > 8 {
+		clusters = clusters[:8]
+	}
+
+	return clusters
+}
+
+// fibTimeZones projects Fibonacci bar counts forward from the latest confirmed swing
+func fibTimeZones(candles []model.Candle, swings []model.SwingPoint) []model.FibTimeZone {
+	zones := make([]model.FibTimeZone, 0)
+	confirmed := ConfirmedSwings(swings)
+	n := len(candles)
+	if len(confirmed) == 0 || n < 2 {
+		return zones
+	}
+
+	anchor := confirmed[len(confirmed)-1]
+	barDuration := candles[n-1].Timestamp - candles[n-2].Timestamp
+
+	for _, bars := range fibTimeSequence {
+		index := anchor.Index + bars
+		timestamp := anchor.Timestamp + int64(bars)*barDuration
+		if index < n {
+			timestamp = candles[index].Timestamp
+		}
+		zones = append(zones, model.FibTimeZone{
+			Bars:      bars,
+			Index:     index,
+			Timestamp: timestamp,
+			Future:    index >= n,
+			Anchor:    anchor.Timestamp,
+		})
+	}
+
+	return zones
+}
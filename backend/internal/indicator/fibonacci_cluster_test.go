@@ -0,0 +1,67 @@
+package indicator
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+func TestCalculateFibonacciConfluence(t *testing.T) {
+	// 100 -> 200 -> 138.2 -> 150: the 0.382 retracement of the 200 -> 138.2 leg lands on the
+	// 0.382 retracement of the 100 -> 200 leg (161.8), and its 1.272 extension on the 0.786 one (121.4)
+	candles := zigzagCandles([]float64{150, 100, 200, 138.2, 150}, 15)
+	swings := []model.SwingPoint{
+		{Index: 15, Price: 100, Timestamp: candles[15].Timestamp, Type: model.SwingLow, Confirmed: true},
+		{Index: 30, Price: 200, Timestamp: candles[30].Timestamp, Type: model.SwingHigh, Confirmed: true},
+		{Index: 45, Price: 138.2, Timestamp: candles[45].Timestamp, Type: model.SwingLow, Confirmed: true},
+	}
+
+	got := CalculateFibonacciConfluence(candles, swings)
+	if got == nil {
+		t.Fatal("no confluence")
+	}
+
+	// Only the low-high and high-low pairs; two lows do not form a pair
+	if len(got.Pairs) != 2 || got.Pairs[0].Direction != "UP" || got.Pairs[1].Direction != "DOWN" {
+		t.Fatalf("pairs = %+v, want one UP and one DOWN", got.Pairs)
+	}
+
+	want := []struct {
+		price float64
+		kind  string
+	}{
+		{161.8, "RESISTANCE"},
+		{121.4, "SUPPORT"},
+	}
+	if len(got.Clusters) != len(want) {
+		t.Fatalf("clusters = %+v, want %d", got.Clusters, len(want))
+	}
+	for i, w := range want {
+		c := got.Clusters[i]
+		if math.Abs(c.Price-w.price) > 0.1 || c.Type != w.kind {
+			t.Errorf("cluster %d = %.2f %s, want %.1f %s", i, c.Price, c.Type, w.price, w.kind)
+		}
+		if !reflect.DeepEqual(c.PairIDs, []int{1, 2}) {
+			t.Errorf("cluster %d pairs = %v, want [1 2]", i, c.PairIDs)
+		}
+	}
+
+	// Time zones count forward from the last swing; 21 bars and beyond fall after the last candle
+	if len(got.TimeZones) != len(fibTimeSequence) {
+		t.Fatalf("time zones = %d, want %d", len(got.TimeZones), len(fibTimeSequence))
+	}
+	for _, zone := range got.TimeZones {
+		if zone.Index != 45+zone.Bars || zone.Future != (zone.Index >= len(candles)) {
+			t.Errorf("time zone %+v, want index %d counted from the last swing", zone, 45+zone.Bars)
+		}
+		if zone.Timestamp != int64(zone.Index)*hourMs {
+			t.Errorf("time zone %d bars at %d, want %d", zone.Bars, zone.Timestamp, int64(zone.Index)*hourMs)
+		}
+	}
+
+	if CalculateFibonacciConfluence(candles[:49], swings) != nil {
+		t.Error("confluence computed on fewer than 50 candles")
+	}
+}
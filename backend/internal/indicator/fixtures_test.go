@@ -0,0 +1,65 @@
+package indicator
+
+import (
+	"math"
+	"time"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// hourMs is the spacing of the hourly fixture candles
+const hourMs = int64(time.Hour / time.Millisecond)
+
+// waypointCandles draws straight legs of bars(from, to) bars between the waypoints, one hourly doji
+// per bar with the given wick on either side
+func waypointCandles(waypoints []float64, bars func(from, to float64) int, wick float64) []model.Candle {
+	prices := []float64{waypoints[0]}
+	for i := 1; i < len(waypoints); i++ {
+		from, to := waypoints[i-1], waypoints[i]
+		n := bars(from, to)
+		for j := 1; j <= n; j++ {
+			prices = append(prices, from+(to-from)*float64(j)/float64(n))
+		}
+	}
+	candles := make([]model.Candle, len(prices))
+	for i, p := range prices {
+		candles[i] = model.Candle{Timestamp: int64(i) * hourMs, Open: p, High: p + wick, Low: p - wick, Close: p, Volume: 1}
+	}
+	return candles
+}
+
+// zigzagCandles draws legs of step bars with a 0.2 wick, so every inner waypoint is a strict swing
+// high or low
+func zigzagCandles(waypoints []float64, step int) []model.Candle {
+	return waypointCandles(waypoints, func(float64, float64) int { return step }, 0.2)
+}
+
+// closeCandles builds hourly candles with a ±0.5 wick around each close, opening at the previous close
+func closeCandles(closes []float64) []model.Candle {
+	candles := make([]model.Candle, len(closes))
+	for i, c := range closes {
+		open := c
+		if i > 0 {
+			open = closes[i-1]
+		}
+		candles[i] = model.Candle{
+			Timestamp: int64(i) * hourMs,
+			Open:      open,
+			High:      math.Max(open, c) + 0.5,
+			Low:       math.Min(open, c) - 0.5,
+			Close:     c,
+			Volume:    1,
+		}
+	}
+	return candles
+}
+
+// flatCandles returns n hourly candles from start (in ms) opening and closing at price with the given
+// high and low
+func flatCandles(start int64, n int, price, high, low float64) []model.Candle {
+	candles := make([]model.Candle, n)
+	for i := range candles {
+		candles[i] = model.Candle{Timestamp: start + int64(i)*hourMs, Open: price, High: high, Low: low, Close: price, Volume: 1}
+	}
+	return candles
+}
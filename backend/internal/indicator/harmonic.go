@@ -0,0 +1,246 @@
+package indicator
+
+import (
+	"math"
+	"sort"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// harmonicRatioTolerance is the relative tolerance applied to every ratio range
+const harmonicRatioTolerance = 0.05
+
+// ratioRange is an inclusive Fibonacci ratio range
+type ratioRange struct {
+	low  float64
+	high float64
+}
+
+func (r ratioRange) contains(v float64) bool {
+	return v >= r.low*(1-harmonicRatioTolerance) && v <= r.high*(1+harmonicRatioTolerance)
+}
+
+// deviation returns how far v is from the range, relative to the range midpoint (0 inside)
+func (r ratioRange) deviation(v float64) float64 {
+	mid := (r.low + r.high) / 2
+	if v >= r.low && v <= r.high {
+		return 0
+	}
+	return math.Min(math.Abs(v-r.low), math.Abs(v-r.high)) / mid
+}
+
+// harmonicFamily describes the ratio rules of one harmonic pattern
+type harmonicFamily struct {
+	name        string
+	ab          ratioRange // AB retracement of XA
+	bc          ratioRange // BC retracement of AB
+	cd          ratioRange // CD extension of BC
+	xd          ratioRange // AD retracement of XA (D relative to XA)
+	stopBeyondX bool       // Retracement patterns (D inside XA) stop beyond X
+	reliability float64
+}
+
+var harmonicFamilies = []harmonicFamily{
+	{
+		name:        "Gartley",
+		ab:          ratioRange{0.618, 0.618},
+		bc:          ratioRange{0.382, 0.886},
+		cd:          ratioRange{1.272, 1.618},
+		xd:          ratioRange{0.786, 0.786},
+		stopBeyondX: true,
+		reliability: 0.75,
+	},
+	{
+		name:        "Bat",
+		ab:          ratioRange{0.382, 0.50},
+		bc:          ratioRange{0.382, 0.886},
+		cd:          ratioRange{1.618, 2.618},
+		xd:          ratioRange{0.886, 0.886},
+		stopBeyondX: true,
+		reliability: 0.75,
+	},
+	{
+		name:        "Butterfly",
+		ab:          ratioRange{0.786, 0.786},
+		bc:          ratioRange{0.382, 0.886},
+		cd:          ratioRange{1.618, 2.24},
+		xd:          ratioRange{1.27, 1.618},
+		reliability: 0.70,
+	},
+	{
+		name:        "Crab",
+		ab:          ratioRange{0.382, 0.618},
+		bc:          ratioRange{0.382, 0.886},
+		cd:          ratioRange{2.24, 3.618},
+		xd:          ratioRange{1.618, 1.618},
+		reliability: 0.70,
+	},
+}
+
+// DetectHarmonicPatterns walks the confirmed swing sequence looking for XABCD harmonic patterns.
+// Completed patterns have a confirmed D swing inside the potential reversal zone (PRZ);
+// forming patterns have a valid XABC leg and a projected D.
+func DetectHarmonicPatterns(candles []model.Candle, swingPoints []model.SwingPoint) []model.HarmonicPattern {
+	patterns := make([]model.HarmonicPattern, 0)
+	if len(candles) < 30 {
+		return patterns
+	}
+
+	swings := chartSwings(swingPoints)
+	n := len(candles)
+	currentPrice := candles[n-1].Close
+
+	// Completed patterns: any XABCD whose D is recent
+	for i := 0; i+4 < len(swings); i++ {
+		x, a, b, c, d := swings[i], swings[i+1], swings[i+2], swings[i+3], swings[i+4]
+		if d.index < n-50 {
+			continue
+		}
+		for _, family := range harmonicFamilies {
+			if p := matchHarmonic(candles, family, x, a, b, c, &d, currentPrice); p != nil {
+				patterns = append(patterns, *p)
+			}
+		}
+	}
+
+	// Forming patterns: the latest XABC with D still to come
+	if len(swings) >= 4 {
+		x, a, b, c := swings[len(swings)-4], swings[len(swings)-3], swings[len(swings)-2], swings[len(swings)-1]
+		for _, family := range harmonicFamilies {
+			if p := matchHarmonic(candles, family, x, a, b, c, nil, currentPrice); p != nil {
+				patterns = append(patterns, *p)
+			}
+		}
+	}
+
+	// Forming patterns first since their D is still ahead, then best-matching, then most recent
+	sort.SliceStable(patterns, func(i, j int) bool {
+		if patterns[i].Status != patterns[j].Status {
+			return patterns[i].Status == "FORMING"
+		}
+		if patterns[i].Score != patterns[j].Score {
+			return patterns[i].Score > patterns[j].Score
+		}
+		return harmonicLastIndex(patterns[i]) > harmonicLastIndex(patterns[j])
+	})
+
+	if len(patterns) > 5 {
+		patterns = patterns[:5]
+	}
+
+	return patterns
+}
+
+// harmonicLastIndex returns the index of the latest confirmed point (D, or C while forming)
+func harmonicLastIndex(p model.HarmonicPattern) int {
+	last := -1
+	for _, point := range p.Points {
+		last = max(last, point.Index)
+	}
+	return last
+}
+
+// matchHarmonic checks one family against an XABC(D) swing sequence; d is nil for forming patterns
+func matchHarmonic(
+	candles []model.Candle,
+	family harmonicFamily,
+	x, a, b, c chartSwing,
+	d *chartSwing,
+	currentPrice float64,
+) *model.HarmonicPattern {
+	xa := a.price - x.price
+	ab := b.price - a.price
+	bc := c.price - b.price
+	if xa == 0 || ab == 0 || bc == 0 {
+		return nil
+	}
+
+	abRatio := math.Abs(ab / xa)
+	bcRatio := math.Abs(bc / ab)
+	if !family.ab.contains(abRatio) || !family.bc.contains(bcRatio) {
+		return nil
+	}
+
+	// PRZ: overlap of the XA retracement projection and the BC extension projection
+	xdLow, xdHigh := a.price-family.xd.low*xa, a.price-family.xd.high*xa
+	cdLow, cdHigh := c.price-family.cd.low*bc, c.price-family.cd.high*bc
+	przLow := math.Max(math.Min(xdLow, xdHigh), math.Min(cdLow, cdHigh))
+	przHigh := math.Min(math.Max(xdLow, xdHigh), math.Max(cdLow, cdHigh))
+	if przLow > przHigh {
+		// Projections do not overlap; the XA retracement governs the PRZ
+		przLow, przHigh = math.Min(xdLow, xdHigh), math.Max(xdLow, xdHigh)
+	}
+	projectedD := a.price - (family.xd.low+family.xd.high)/2*xa
+
+	bullish := xa > 0
+	ratios := map[string]float64{
+		"AB/XA": abRatio,
+		"BC/AB": bcRatio,
+	}
+
+	status := "FORMING"
+	dPoint := model.PatternPoint{Label: "D", Index: -1, Price: projectedD}
+	score := 1 - (family.ab.deviation(abRatio)+family.bc.deviation(bcRatio))/2
+
+	if d != nil {
+		cdRatio := math.Abs((d.price - c.price) / bc)
+		xdRatio := math.Abs((d.price - a.price) / xa)
+		if !family.cd.contains(cdRatio) || !family.xd.contains(xdRatio) {
+			return nil
+		}
+		ratios["CD/BC"] = cdRatio
+		ratios["XD"] = xdRatio
+		status = "COMPLETED"
+		dPoint = patternPoint(candles, "D", *d)
+		score = 1 - (family.ab.deviation(abRatio)+family.bc.deviation(bcRatio)+
+			family.cd.deviation(cdRatio)+family.xd.deviation(xdRatio))/4
+	} else {
+		// Price must be heading from C toward the PRZ and not have blown through it
+		if bullish && (currentPrice >= c.price || currentPrice < przLow-0.1*math.Abs(xa)) {
+			return nil
+		}
+		if !bullish && (currentPrice <= c.price || currentPrice > przHigh+0.1*math.Abs(xa)) {
+			return nil
+		}
+	}
+
+	// Stop beyond X for retracement patterns, beyond the far PRZ edge otherwise
+	farEdge := przLow
+	if !bullish {
+		farEdge = przHigh
+	}
+	dPrice := dPoint.Price
+	stop := farEdge - 0.1*xa
+	if family.stopBeyondX {
+		stop = x.price - 0.05*xa
+	}
+
+	// Targets: 38.2% and 61.8% retracements of AD
+	ad := a.price - dPrice
+	targets := []float64{dPrice + 0.382*ad, dPrice + 0.618*ad}
+
+	direction := "看涨"
+	if !bullish {
+		direction = "看跌"
+	}
+
+	return &model.HarmonicPattern{
+		Pattern:   family.name,
+		Direction: direction,
+		Status:    status,
+		Points: []model.PatternPoint{
+			patternPoint(candles, "X", x),
+			patternPoint(candles, "A", a),
+			patternPoint(candles, "B", b),
+			patternPoint(candles, "C", c),
+			dPoint,
+		},
+		Ratios:      ratios,
+		PRZ:         [2]float64{przLow, przHigh},
+		ProjectedD:  projectedD,
+		StopLoss:    stop,
+		Targets:     targets,
+		Score:       math.Max(0, score),
+		Reliability: family.reliability,
+	}
+}
@@ -0,0 +1,69 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+func TestDetectHarmonicPatterns(t *testing.T) {
+	tests := []struct {
+		name      string
+		waypoints []float64 // Lead-in, X, A, B, C, D, follow-through
+		pattern   string
+		direction string
+	}{
+		// AB 0.618 of XA, BC 0.618 of AB, D at the 0.786 retracement of XA (CD 1.44 of BC)
+		{"bullish gartley", []float64{130, 100, 200, 138.2, 176.4, 121.4, 140}, "Gartley", "看涨"},
+		// AB 0.45 of XA, BC 0.8 of AB, D at the 0.886 retracement of XA (CD 2.21 of BC)
+		{"bearish bat", []float64{170, 200, 100, 145, 109, 188.6, 170}, "Bat", "看跌"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candles := zigzagCandles(tt.waypoints, 6)
+			var found *model.HarmonicPattern
+			patterns := DetectHarmonicPatterns(candles, FindSwings(candles, DefaultSwingConfig()))
+			for i := range patterns {
+				if patterns[i].Status == "COMPLETED" {
+					if found != nil {
+						t.Fatalf("completed %s and %s, want only %s", found.Pattern, patterns[i].Pattern, tt.pattern)
+					}
+					found = &patterns[i]
+				}
+			}
+			if found == nil {
+				t.Fatalf("no completed pattern among %+v", patterns)
+			}
+			if found.Pattern != tt.pattern || found.Direction != tt.direction {
+				t.Fatalf("got %s %s, want %s %s", found.Direction, found.Pattern, tt.direction, tt.pattern)
+			}
+
+			// Points sit on the waypoints (fractal swings report the wick, 0.2 beyond)
+			for i, point := range found.Points {
+				if point.Index != (i+1)*6 || math.Abs(point.Price-tt.waypoints[i+1]) > 0.2+1e-9 {
+					t.Errorf("%s at %d %v, want %d %v", point.Label, point.Index, point.Price, (i+1)*6, tt.waypoints[i+1])
+				}
+			}
+			if found.Score < 0.99 {
+				t.Errorf("score %v, want a near perfect match", found.Score)
+			}
+
+			// D inside the PRZ, stop beyond X, targets back toward A
+			x, d := found.Points[0].Price, found.Points[4].Price
+			if d < found.PRZ[0]-0.5 || d > found.PRZ[1]+0.5 {
+				t.Errorf("D %v outside the PRZ %v", d, found.PRZ)
+			}
+			bullish := tt.direction == "看涨"
+			if (bullish && found.StopLoss >= x) || (!bullish && found.StopLoss <= x) {
+				t.Errorf("stop %v not beyond X %v", found.StopLoss, x)
+			}
+			for _, target := range found.Targets {
+				if (bullish && target <= d) || (!bullish && target >= d) {
+					t.Errorf("target %v on the wrong side of D %v", target, d)
+				}
+			}
+		})
+	}
+}
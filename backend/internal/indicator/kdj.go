@@ -56,67 +56,16 @@ func CalculateKDJ(candles []model.Candle) model.KDJIndicator {
 }
 
 // CalculateKDJWithHistory calculates KDJ with proper SMA (more accurate)
+// Runs the incremental KDJ over the candles, so it is O(n)
 func CalculateKDJWithHistory(candles []model.Candle) model.KDJIndicator {
 	if len(candles) < 9 {
 		return model.KDJIndicator{}
 	}
 
-	n := len(candles)
-	period := 9
-	smoothK := 3
-	smoothD := 3
-
-	// Calculate RSV values
-	rsvValues := make([]float64, 0)
-	
-	for i := period - 1; i < n; i++ {
-		var low9, high9 float64 = math.MaxFloat64, -math.MaxFloat64
-		
-		for j := i - period + 1; j <= i; j++ {
-			if candles[j].Low < low9 {
-				low9 = candles[j].Low
-			}
-			if candles[j].High > high9 {
-				high9 = candles[j].High
-			}
-		}
-
-		rsv := 0.0
-		if high9 != low9 {
-			rsv = (candles[i].Close - low9) / (high9 - low9) * 100
-		}
-		rsvValues = append(rsvValues, rsv)
-	}
-
-	// Calculate K (SMA of RSV)
-	kValues := sma(rsvValues, smoothK)
-	
-	// Calculate D (SMA of K)
-	dValues := sma(kValues, smoothD)
-
-	// Get latest K and D
-	k := kValues[len(kValues)-1]
-	d := dValues[len(dValues)-1]
-	j := 3*k - 2*d
-
-	return model.KDJIndicator{
-		K: k,
-		D: d,
-		J: j,
+	kdj := NewStreamingKDJ()
+	for _, candle := range candles {
+		kdj.Update(candle)
 	}
-}
 
-// sma calculates simple moving average
-func sma(data []float64, period int) []float64 {
-	result := make([]float64, 0)
-	
-	for i := period - 1; i < len(data); i++ {
-		sum := 0.0
-		for j := i - period + 1; j <= i; j++ {
-			sum += data[j]
-		}
-		result = append(result, sum/float64(period))
-	}
-	
-	return result
+	return kdj.Value()
 }
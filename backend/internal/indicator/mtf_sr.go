@@ -0,0 +1,133 @@
+package indicator
+
+import (
+	"math"
+	"sort"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// maxSRLevelsPerSide is how many levels each side of a merged SR set keeps
+const maxSRLevelsPerSide = 5
+
+// TimeframeLevels holds the SR levels computed on one higher timeframe
+type TimeframeLevels struct {
+	Timeframe string
+	Levels    model.SRLevels
+}
+
+// TimeframeWeight scales the strength of a level from timeframe when merged into interval:
+// 1 + 0.1 per doubling of the bar duration (4h into 1h: 1.2, 1d: ~1.46, 1w: ~1.74)
+func TimeframeWeight(interval, timeframe string) float64 {
+	base, higher := IntervalDuration(interval), IntervalDuration(timeframe)
+	if base <= 0 || higher <= base {
+		return 1
+	}
+	return 1 + 0.1*math.Log2(float64(higher)/float64(base))
+}
+
+// MergeTimeframeLevels merges higher-timeframe SR levels into the interval's levels. Every level is
+// tagged with the timeframe it came from; a higher-timeframe level that lands on an existing level
+// adds its timeframe to that level's agreement list and strengthens it, otherwise it is added with its
+// strength weighted by TimeframeWeight. Levels keep their zone role, or are split around the current
+// price when they have none. Each side keeps its strongest levels, so weighted higher-timeframe levels
+// survive nearer but weaker ones, ordered nearest first.
+func MergeTimeframeLevels(levels model.SRLevels, interval string, higher []TimeframeLevels, currentPrice, atr float64) model.SRLevels {
+	if len(higher) == 0 || currentPrice <= 0 {
+		return levels
+	}
+
+	threshold := clusterThreshold(getTimeframeConfig(interval, 0), currentPrice, atr)
+	bufferZone := currentPrice * 0.002
+
+	merged := make([]model.SRLevel, 0)
+	for _, level := range append(append([]model.SRLevel{}, levels.Resistance...), levels.Support...) {
+		level.Timeframe = interval
+		level.Timeframes = []string{interval}
+		merged = append(merged, level)
+	}
+
+	for _, tf := range higher {
+		weight := TimeframeWeight(interval, tf.Timeframe)
+		for _, level := range append(append([]model.SRLevel{}, tf.Levels.Resistance...), tf.Levels.Support...) {
+			weighted := math.Min(1.0, level.Strength*weight)
+
+			nearest := -1
+			for i := range merged {
+				distance := math.Abs(merged[i].Price - level.Price)
+				if (distance <= threshold || OverlapsSRZone(merged[i], level)) &&
+					(nearest < 0 || distance < math.Abs(merged[nearest].Price-level.Price)) {
+					nearest = i
+				}
+			}
+
+			if nearest >= 0 {
+				// Timeframes agree on the level
+				if !containsString(merged[nearest].Timeframes, tf.Timeframe) {
+					merged[nearest].Timeframes = append(merged[nearest].Timeframes, tf.Timeframe)
+				}
+				merged[nearest].Strength = math.Min(1.0, math.Max(merged[nearest].Strength, weighted)+0.1)
+				continue
+			}
+
+			level.Timeframe = tf.Timeframe
+			level.Timeframes = []string{tf.Timeframe}
+			level.Strength = weighted
+			merged = append(merged, level)
+		}
+	}
+
+	resistance := make([]model.SRLevel, 0)
+	support := make([]model.SRLevel, 0)
+	for _, level := range merged {
+		if level.Role == SRRoleResistance {
+			resistance = append(resistance, level)
+		} else if level.Role == SRRoleSupport {
+			support = append(support, level)
+		} else if level.Price > currentPrice+bufferZone {
+			resistance = append(resistance, level)
+		} else if level.Price < currentPrice-bufferZone {
+			support = append(support, level)
+		}
+	}
+
+	return model.SRLevels{
+		Resistance: strongestNearestFirst(resistance, true),
+		Support:    strongestNearestFirst(support, false),
+	}
+}
+
+// strongestNearestFirst keeps the maxSRLevelsPerSide strongest levels of one side (the nearer on ties)
+// and orders them nearest first: resistance ascending, support descending
+func strongestNearestFirst(levels []model.SRLevel, ascending bool) []model.SRLevel {
+	nearer := func(a, b model.SRLevel) bool {
+		if ascending {
+			return a.Price < b.Price
+		}
+		return a.Price > b.Price
+	}
+
+	sort.SliceStable(levels, func(i, j int) bool {
+		if levels[i].Strength != levels[j].Strength {
+			return levels[i].Strength > levels[j].Strength
+		}
+		return nearer(levels[i], levels[j])
+	})
+	if len(levels) > maxSRLevelsPerSide {
+		levels = levels[:maxSRLevelsPerSide]
+	}
+
+	sort.SliceStable(levels, func(i, j int) bool {
+		return nearer(levels[i], levels[j])
+	})
+	return levels
+}
+
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package indicator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+func TestMergeTimeframeLevels(t *testing.T) {
+	level := func(price, strength float64) model.SRLevel {
+		return model.SRLevel{Price: price, Strength: strength, Lower: price - 0.1, Upper: price + 0.1}
+	}
+	levels := model.SRLevels{
+		Resistance: []model.SRLevel{level(101, 0.5)},
+		Support:    []model.SRLevel{level(99, 0.5), level(98.5, 0.5), level(98, 0.5), level(97.5, 0.5), level(97, 0.5)},
+	}
+	higher := []TimeframeLevels{
+		// 0.8 from the 1h level: beyond the 0.5% cluster threshold but within half an ATR of 2
+		{Timeframe: "4h", Levels: model.SRLevels{Resistance: []model.SRLevel{level(101.8, 0.5)}}},
+		// Further than the five 1h supports but stronger once weighted
+		{Timeframe: "1d", Levels: model.SRLevels{Support: []model.SRLevel{level(90, 0.6)}}},
+	}
+
+	merged := MergeTimeframeLevels(levels, "1h", higher, 100, 2)
+
+	if len(merged.Resistance) != 1 || !reflect.DeepEqual(merged.Resistance[0].Timeframes, []string{"1h", "4h"}) {
+		t.Errorf("resistance = %+v, want the 4h level merged into the 1h one", merged.Resistance)
+	}
+
+	prices := func(levels []model.SRLevel) []float64 {
+		out := make([]float64, len(levels))
+		for i, l := range levels {
+			out[i] = l.Price
+		}
+		return out
+	}
+	// The daily level displaces the farthest of the equally strong 1h levels; the rest stay nearest first
+	want := []float64{99, 98.5, 98, 97.5, 90}
+	if got := prices(merged.Support); !reflect.DeepEqual(got, want) {
+		t.Fatalf("support = %v, want %v", got, want)
+	}
+	if daily := merged.Support[4]; daily.Timeframe != "1d" || daily.Strength <= 0.6 {
+		t.Errorf("daily level = %+v, want weighted above its own 0.6", daily)
+	}
+
+	// A weaker trendline does not push the daily level back out
+	trendlines := model.TrendlineAnalysis{Lines: []model.Trendline{{CurrentPrice: 99.5, Score: 0.3}}}
+	withLines := MergeTrendlineLevels(merged, trendlines, 100)
+	if got := prices(withLines.Support); !reflect.DeepEqual(got, want) {
+		t.Errorf("support with trendline = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,172 @@
+package indicator
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// PivotLevel represents a single named pivot level (e.g. "Classic R1")
+type PivotLevel struct {
+	Name  string
+	Price float64
+}
+
+// PivotTimeframe returns the higher timeframe whose prior candle is used for pivots
+// Daily pivots for intraday intervals, weekly for 4h, monthly for 1d and above
+func PivotTimeframe(interval string) string {
+	switch interval {
+	case "4h", "6h", "8h", "12h":
+		return "1w"
+	case "1d", "3d", "1w":
+		return "1M"
+	default:
+		return "1d"
+	}
+}
+
+// PivotPeriodStart returns the open time (ms) of the pivot timeframe candle containing ts, aligned like
+// Binance candles: UTC days, weeks starting Monday and calendar months
+func PivotPeriodStart(ts int64, timeframe string) int64 {
+	t := time.UnixMilli(ts).UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch timeframe {
+	case "1d":
+		return day.UnixMilli()
+	case "1w":
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7).UnixMilli()
+	case "1M":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	}
+	if d := IntervalDuration(timeframe).Milliseconds(); d > 0 {
+		return ts - ts%d
+	}
+	return ts
+}
+
+// CalculatePivotPoints calculates all pivot families from a completed higher-timeframe candle
+func CalculatePivotPoints(candle model.Candle, timeframe string) *model.PivotPoints {
+	high := candle.High
+	low := candle.Low
+	open := candle.Open
+	close := candle.Close
+	rng := high - low
+
+	if rng <= 0 {
+		return nil
+	}
+
+	// Classic (floor) pivots
+	p := (high + low + close) / 3
+	classic := map[string]float64{
+		"P":  p,
+		"R1": 2*p - low,
+		"S1": 2*p - high,
+		"R2": p + rng,
+		"S2": p - rng,
+		"R3": high + 2*(p-low),
+		"S3": low - 2*(high-p),
+	}
+
+	// Fibonacci pivots
+	fibonacci := map[string]float64{
+		"P":  p,
+		"R1": p + rng*0.382,
+		"S1": p - rng*0.382,
+		"R2": p + rng*0.618,
+		"S2": p - rng*0.618,
+		"R3": p + rng,
+		"S3": p - rng,
+	}
+
+	// Camarilla pivots
+	camarilla := map[string]float64{
+		"P":  p,
+		"R1": close + rng*1.1/12,
+		"S1": close - rng*1.1/12,
+		"R2": close + rng*1.1/6,
+		"S2": close - rng*1.1/6,
+		"R3": close + rng*1.1/4,
+		"S3": close - rng*1.1/4,
+		"R4": close + rng*1.1/2,
+		"S4": close - rng*1.1/2,
+	}
+
+	// Woodie pivots (weights the close twice)
+	wp := (high + low + 2*close) / 4
+	woodie := map[string]float64{
+		"P":  wp,
+		"R1": 2*wp - low,
+		"S1": 2*wp - high,
+		"R2": wp + rng,
+		"S2": wp - rng,
+		"R3": high + 2*(wp-low),
+		"S3": low - 2*(high-wp),
+	}
+
+	// DeMark pivots depend on the candle's direction
+	var x float64
+	if close < open {
+		x = high + 2*low + close
+	} else if close > open {
+		x = 2*high + low + close
+	} else {
+		x = high + low + 2*close
+	}
+	demark := map[string]float64{
+		"P":  x / 4,
+		"R1": x/2 - low,
+		"S1": x/2 - high,
+	}
+
+	return &model.PivotPoints{
+		Timeframe: timeframe,
+		Timestamp: candle.Timestamp,
+		High:      high,
+		Low:       low,
+		Close:     close,
+		Classic:   classic,
+		Fibonacci: fibonacci,
+		Camarilla: camarilla,
+		Woodie:    woodie,
+		DeMark:    demark,
+	}
+}
+
+// ListPivotLevels flattens all pivot families into named levels sorted by price (ascending)
+func ListPivotLevels(pivots *model.PivotPoints) []PivotLevel {
+	if pivots == nil {
+		return []PivotLevel{}
+	}
+
+	families := []struct {
+		name   string
+		levels map[string]float64
+	}{
+		{"Classic", pivots.Classic},
+		{"Fibonacci", pivots.Fibonacci},
+		{"Camarilla", pivots.Camarilla},
+		{"Woodie", pivots.Woodie},
+		{"DeMark", pivots.DeMark},
+	}
+
+	levels := make([]PivotLevel, 0)
+	for _, family := range families {
+		for label, price := range family.levels {
+			levels = append(levels, PivotLevel{
+				Name:  family.name + " " + label,
+				Price: price,
+			})
+		}
+	}
+
+	sort.Slice(levels, func(i, j int) bool {
+		if levels[i].Price == levels[j].Price {
+			return levels[i].Name < levels[j].Name
+		}
+		return levels[i].Price < levels[j].Price
+	})
+
+	return levels
+}
@@ -0,0 +1,123 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+func TestCalculatePivotPoints(t *testing.T) {
+	// H 110, L 90, range 20, bullish candle closing at 105
+	bullish := model.Candle{Open: 95, High: 110, Low: 90, Close: 105}
+	p := 305.0 / 3
+
+	tests := []struct {
+		name   string
+		candle model.Candle
+		family func(*model.PivotPoints) map[string]float64
+		want   map[string]float64
+	}{
+		{
+			name:   "classic",
+			candle: bullish,
+			family: func(pp *model.PivotPoints) map[string]float64 { return pp.Classic },
+			want: map[string]float64{
+				"P": p, "R1": 2*p - 90, "S1": 2*p - 110, "R2": p + 20, "S2": p - 20,
+				"R3": 110 + 2*(p-90), "S3": 90 - 2*(110-p),
+			},
+		},
+		{
+			name:   "fibonacci",
+			candle: bullish,
+			family: func(pp *model.PivotPoints) map[string]float64 { return pp.Fibonacci },
+			want: map[string]float64{
+				"P": p, "R1": p + 7.64, "S1": p - 7.64, "R2": p + 12.36, "S2": p - 12.36, "R3": p + 20, "S3": p - 20,
+			},
+		},
+		{
+			name:   "camarilla",
+			candle: bullish,
+			family: func(pp *model.PivotPoints) map[string]float64 { return pp.Camarilla },
+			want: map[string]float64{
+				"P": p, "R1": 105 + 22.0/12, "S1": 105 - 22.0/12, "R2": 105 + 22.0/6, "S2": 105 - 22.0/6,
+				"R3": 110.5, "S3": 99.5, "R4": 116, "S4": 94,
+			},
+		},
+		{
+			name:   "woodie",
+			candle: bullish,
+			family: func(pp *model.PivotPoints) map[string]float64 { return pp.Woodie },
+			// P = (110 + 90 + 2*105) / 4
+			want: map[string]float64{
+				"P": 102.5, "R1": 115, "S1": 95, "R2": 122.5, "S2": 82.5, "R3": 135, "S3": 75,
+			},
+		},
+		{
+			name:   "demark bullish",
+			candle: bullish,
+			family: func(pp *model.PivotPoints) map[string]float64 { return pp.DeMark },
+			// X = 2H + L + C = 415
+			want: map[string]float64{"P": 103.75, "R1": 117.5, "S1": 97.5},
+		},
+		{
+			name:   "demark bearish",
+			candle: model.Candle{Open: 108, High: 110, Low: 90, Close: 105},
+			family: func(pp *model.PivotPoints) map[string]float64 { return pp.DeMark },
+			// X = H + 2L + C = 395
+			want: map[string]float64{"P": 98.75, "R1": 107.5, "S1": 87.5},
+		},
+		{
+			name:   "demark doji",
+			candle: model.Candle{Open: 105, High: 110, Low: 90, Close: 105},
+			family: func(pp *model.PivotPoints) map[string]float64 { return pp.DeMark },
+			// X = H + L + 2C = 410
+			want: map[string]float64{"P": 102.5, "R1": 115, "S1": 95},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pivots := CalculatePivotPoints(tt.candle, "1d")
+			if pivots == nil {
+				t.Fatal("expected pivots")
+			}
+			got := tt.family(pivots)
+			if len(got) != len(tt.want) {
+				t.Errorf("got %d levels, want %d", len(got), len(tt.want))
+			}
+			for label, want := range tt.want {
+				if math.Abs(got[label]-want) > 1e-9 {
+					t.Errorf("%s: got %v, want %v", label, got[label], want)
+				}
+			}
+		})
+	}
+
+	if CalculatePivotPoints(model.Candle{Open: 100, High: 100, Low: 100, Close: 100}, "1d") != nil {
+		t.Error("expected no pivots for a zero-range candle")
+	}
+}
+
+func TestPivotPeriodStart(t *testing.T) {
+	at := func(y int, m time.Month, d, h int) int64 { return time.Date(y, m, d, h, 0, 0, 0, time.UTC).UnixMilli() }
+
+	tests := []struct {
+		ts        int64
+		timeframe string
+		want      int64
+	}{
+		{at(2024, 1, 3, 15), "1d", at(2024, 1, 3, 0)},
+		{at(2024, 1, 3, 15), "1w", at(2024, 1, 1, 0)}, // Wednesday -> Monday
+		{at(2024, 1, 7, 23), "1w", at(2024, 1, 1, 0)}, // Sunday still belongs to the week
+		{at(2024, 1, 8, 0), "1w", at(2024, 1, 8, 0)},
+		{at(2024, 2, 15, 6), "1M", at(2024, 2, 1, 0)},
+		{at(2024, 2, 15, 6), "4h", at(2024, 2, 15, 4)},
+	}
+	for _, tt := range tests {
+		if got := PivotPeriodStart(tt.ts, tt.timeframe); got != tt.want {
+			t.Errorf("%s of %s: got %s, want %s", tt.timeframe, time.UnixMilli(tt.ts).UTC(), time.UnixMilli(got).UTC(), time.UnixMilli(tt.want).UTC())
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package indicator
+
+import (
+	"math"
+	"sort"
+
+	"github.com/markcheno/go-talib"
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// Market regimes
+const (
+	RegimeTrending = "TRENDING"
+	RegimeRanging  = "RANGING"
+	RegimeVolatile = "VOLATILE"
+	RegimeQuiet    = "QUIET"
+	// Too little history to classify
+	RegimeUnknown = "UNKNOWN"
+)
+
+// ClassifyRegime combines ADX, the choppiness index, the Hurst exponent (from the variance ratio)
+// and the ATR percentile into a market regime with probabilities. Fewer than 50 candles yield RegimeUnknown.
+func ClassifyRegime(candles []model.Candle) model.MarketRegime {
+	regime := model.MarketRegime{
+		Regime: RegimeUnknown,
+		Probabilities: map[string]float64{
+			RegimeTrending: 0.25,
+			RegimeRanging:  0.25,
+			RegimeVolatile: 0.25,
+			RegimeQuiet:    0.25,
+		},
+		Hurst:         0.5,
+		VarianceRatio: 1,
+		ATRPercentile: 50,
+	}
+	if len(candles) < 50 {
+		return regime
+	}
+
+	regime.ADX = CalculateADX(candles, 14)
+	regime.Choppiness = CalculateChoppiness(candles, 14)
+	regime.VarianceRatio = CalculateVarianceRatio(candles, 4, 100)
+	regime.Hurst = 0.5 + math.Log(regime.VarianceRatio)/(2*math.Log(4))
+	regime.ATRPercentile = CalculateATRPercentile(candles, 14)
+
+	// Trend character: strong ADX, low choppiness, persistent returns
+	trendScore := (clamp01((regime.ADX-15)/20) +
+		clamp01((61.8-regime.Choppiness)/(61.8-38.2)) +
+		clamp01((regime.Hurst-0.45)/0.2)) / 3
+	rangeScore := (clamp01((25-regime.ADX)/15) +
+		clamp01((regime.Choppiness-38.2)/(61.8-38.2)) +
+		clamp01((0.55-regime.Hurst)/0.2)) / 3
+
+	// Volatility character from the ATR percentile
+	pct := regime.ATRPercentile / 100
+	volatileScore := clamp01((pct - 0.6) / 0.35)
+	quietScore := clamp01((0.4 - pct) / 0.35)
+
+	// A volatile or quiet trend is still a trend; volatility extremes without direction are their own regimes
+	scores := map[string]float64{
+		RegimeTrending: trendScore,
+		RegimeRanging:  rangeScore * (1 - volatileScore),
+		RegimeVolatile: volatileScore * (1 - trendScore),
+		RegimeQuiet:    quietScore * (1 - trendScore),
+	}
+
+	total := 0.0
+	for _, v := range scores {
+		total += v + 0.01
+	}
+	best := ""
+	for _, name := range []string{RegimeTrending, RegimeRanging, RegimeVolatile, RegimeQuiet} {
+		regime.Probabilities[name] = (scores[name] + 0.01) / total
+		if best == "" || regime.Probabilities[name] > regime.Probabilities[best] {
+			best = name
+		}
+	}
+	regime.Regime = best
+
+	return regime
+}
+
+// CalculateADX returns the latest Average Directional Index
+func CalculateADX(candles []model.Candle, period int) float64 {
+	if len(candles) < period*2+1 {
+		return 0
+	}
+
+	highs := make([]float64, len(candles))
+	lows := make([]float64, len(candles))
+	closes := make([]float64, len(candles))
+	for i, c := range candles {
+		highs[i] = c.High
+		lows[i] = c.Low
+		closes[i] = c.Close
+	}
+
+	adx := talib.Adx(highs, lows, closes, period)
+	return adx[len(adx)-1]
+}
+
+// CalculateChoppiness returns the latest choppiness index (0-100).
+// CHOP = 100 × log10(ΣTR / (highest high − lowest low)) / log10(period); above 61.8 is choppy, below 38.2 trending.
+func CalculateChoppiness(candles []model.Candle, period int) float64 {
+	n := len(candles)
+	if n < period+1 {
+		return 50
+	}
+
+	sumTR := 0.0
+	highest, lowest := candles[n-period].High, candles[n-period].Low
+	for i := n - period; i < n; i++ {
+		c := candles[i]
+		prevClose := candles[i-1].Close
+		sumTR += math.Max(c.High, prevClose) - math.Min(c.Low, prevClose)
+		highest = math.Max(highest, c.High)
+		lowest = math.Min(lowest, c.Low)
+	}
+
+	if highest <= lowest || sumTR <= 0 {
+		return 50
+	}
+	return 100 * math.Log10(sumTR/(highest-lowest)) / math.Log10(float64(period))
+}
+
+// CalculateVarianceRatio returns Var(q-bar log returns) / (q × Var(1-bar log returns)) over the
+// last lookback candles. Above 1 returns trend (persist), below 1 they mean-revert.
+func CalculateVarianceRatio(candles []model.Candle, q, lookback int) float64 {
+	start := max(1, len(candles)-lookback)
+	if len(candles)-start < q*4 {
+		return 1
+	}
+
+	returns := make([]float64, 0, len(candles)-start)
+	for i := start; i < len(candles); i++ {
+		if candles[i-1].Close <= 0 || candles[i].Close <= 0 {
+			continue
+		}
+		returns = append(returns, math.Log(candles[i].Close/candles[i-1].Close))
+	}
+
+	// Overlapping q-bar returns
+	multi := make([]float64, 0, len(returns))
+	for i := q - 1; i < len(returns); i++ {
+		sum := 0.0
+		for j := i - q + 1; j <= i; j++ {
+			sum += returns[j]
+		}
+		multi = append(multi, sum)
+	}
+
+	single := variance(returns)
+	if single <= 0 {
+		return 1
+	}
+	ratio := variance(multi) / (float64(q) * single)
+	if ratio <= 0 || math.IsNaN(ratio) {
+		return 1
+	}
+	return ratio
+}
+
+// CalculateATRPercentile returns the percentile rank (0-100) of the current ATR% within the series' own history
+func CalculateATRPercentile(candles []model.Candle, period int) float64 {
+	atrValues := CalculateATR(candles, period).Values
+	history := make([]float64, 0, len(atrValues))
+	for i := period - 1; i < len(atrValues); i++ {
+		if atrValues[i] > 0 && candles[i].Close > 0 {
+			history = append(history, atrValues[i]/candles[i].Close)
+		}
+	}
+	if len(history) < 2 {
+		return 50
+	}
+
+	return percentileRank(history, history[len(history)-1])
+}
+
+// percentileRank returns the share (0-100) of values at or below v
+func percentileRank(values []float64, v float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	below := sort.Search(len(sorted), func(i int) bool { return sorted[i] > v })
+	return float64(below) / float64(len(sorted)) * 100
+}
+
+func variance(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+	return sum / float64(len(values)-1)
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
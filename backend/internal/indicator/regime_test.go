@@ -0,0 +1,47 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+func TestClassifyRegime(t *testing.T) {
+	trending := make([]float64, 200)
+	ranging := make([]float64, 200)
+	for i := range trending {
+		// Steady climb with a small pullback every fifth bar
+		trending[i] = 100 + float64(i) - 0.8*float64(i%5/4)
+		// Bar-to-bar reversals around 100 whose size swings on a 50-bar cycle, ending mid-cycle
+		// so the latest ATR sits in the middle of its history
+		swing := 2 + 0.5*math.Sin(float64(i-199)*2*math.Pi/50)
+		ranging[i] = 100 + swing*float64(1-2*(i%2))
+	}
+
+	tests := []struct {
+		name    string
+		candles []model.Candle
+		want    string
+	}{
+		{"trending", closeCandles(trending), RegimeTrending},
+		{"ranging", closeCandles(ranging), RegimeRanging},
+		{"too short", closeCandles(trending[:49]), RegimeUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClassifyRegime(tt.candles)
+			if got.Regime != tt.want {
+				t.Fatalf("regime = %s, want %s (adx %.1f, chop %.1f, hurst %.2f, atr pct %.0f)",
+					got.Regime, tt.want, got.ADX, got.Choppiness, got.Hurst, got.ATRPercentile)
+			}
+			sum := 0.0
+			for _, p := range got.Probabilities {
+				sum += p
+			}
+			if math.Abs(sum-1) > 1e-9 {
+				t.Errorf("probabilities sum to %.6f, want 1", sum)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package indicator
+
+import (
+	"math"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// rsHorizons are the ratio lookbacks, in bars, blended into the RS score, and rsWeights their weights
+var (
+	rsHorizons = []int{10, 30, 90}
+	rsWeights  = []float64{0.2, 0.3, 0.5}
+)
+
+// RatioCandles divides asset candles by benchmark candles on shared timestamps. Open and close are
+// the price ratios; the high and low bound the open, close and the same-extreme ratios (asset high
+// over benchmark high, and low over low), since the true intrabar ratio extremes are unknown.
+// Volume stays the asset's own.
+func RatioCandles(asset, benchmark []model.Candle) []model.Candle {
+	byTime := make(map[int64]model.Candle, len(benchmark))
+	for _, c := range benchmark {
+		byTime[c.Timestamp] = c
+	}
+
+	ratio := make([]model.Candle, 0, len(asset))
+	for _, a := range asset {
+		b, ok := byTime[a.Timestamp]
+		if !ok || b.Open <= 0 || b.High <= 0 || b.Low <= 0 || b.Close <= 0 {
+			continue
+		}
+		open, close := a.Open/b.Open, a.Close/b.Close
+		ratio = append(ratio, model.Candle{
+			Timestamp: a.Timestamp,
+			Open:      open,
+			High:      math.Max(math.Max(open, close), a.High/b.High),
+			Low:       math.Min(math.Min(open, close), a.Low/b.Low),
+			Close:     close,
+			Volume:    a.Volume,
+		})
+	}
+
+	return ratio
+}
+
+// RelativeStrengthScore blends the ratio's percentage change over 10, 30 and 90 bars (weighted
+// 0.2/0.3/0.5, each horizon capped to the available history). Positive means outperforming.
+func RelativeStrengthScore(ratio []model.Candle) float64 {
+	n := len(ratio)
+	if n < 2 {
+		return 0
+	}
+
+	score, totalWeight := 0.0, 0.0
+	for i, horizon := range rsHorizons {
+		horizon = min(horizon, n-1)
+		base := ratio[n-1-horizon].Close
+		if base <= 0 {
+			continue
+		}
+		score += rsWeights[i] * (ratio[n-1].Close/base - 1) * 100
+		totalWeight += rsWeights[i]
+	}
+	if totalWeight == 0 {
+		return 0
+	}
+
+	return score / totalWeight
+}
@@ -0,0 +1,54 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+func TestRatioCandles(t *testing.T) {
+	asset := []model.Candle{
+		{Timestamp: 1, Open: 10, High: 12, Low: 9, Close: 11, Volume: 5},
+		{Timestamp: 2, Open: 11, High: 13, Low: 10, Close: 12, Volume: 6},
+	}
+	bench := []model.Candle{
+		{Timestamp: 2, Open: 100, High: 110, Low: 90, Close: 100, Volume: 1},
+		{Timestamp: 3, Open: 100, High: 110, Low: 90, Close: 100, Volume: 1},
+	}
+
+	ratio := RatioCandles(asset, bench)
+	if len(ratio) != 1 || ratio[0].Timestamp != 2 {
+		t.Fatalf("ratio %v, want the shared timestamp only", ratio)
+	}
+	r := ratio[0]
+	if math.Abs(r.Open-0.11) > 1e-9 || math.Abs(r.Close-0.12) > 1e-9 || r.Volume != 6 {
+		t.Errorf("ratio candle %+v", r)
+	}
+	if r.High < math.Max(r.Open, r.Close) || r.Low > math.Min(r.Open, r.Close) {
+		t.Errorf("high/low %v/%v do not bound open/close", r.High, r.Low)
+	}
+}
+
+func TestRelativeStrengthScore(t *testing.T) {
+	bench := randomWalkCandles(120, 5)
+
+	// An asset that gains 0.5% a bar on top of the benchmark outperforms; its mirror underperforms
+	leader := make([]model.Candle, len(bench))
+	laggard := make([]model.Candle, len(bench))
+	for i, c := range bench {
+		up, down := math.Pow(1.005, float64(i)), math.Pow(0.995, float64(i))
+		leader[i] = model.Candle{Timestamp: c.Timestamp, Open: c.Open * up, High: c.High * up, Low: c.Low * up, Close: c.Close * up}
+		laggard[i] = model.Candle{Timestamp: c.Timestamp, Open: c.Open * down, High: c.High * down, Low: c.Low * down, Close: c.Close * down}
+	}
+
+	if s := RelativeStrengthScore(RatioCandles(leader, bench)); s <= 0 {
+		t.Errorf("leader score %v, want positive", s)
+	}
+	if s := RelativeStrengthScore(RatioCandles(laggard, bench)); s >= 0 {
+		t.Errorf("laggard score %v, want negative", s)
+	}
+	if s := RelativeStrengthScore(RatioCandles(bench, bench)); math.Abs(s) > 1e-9 {
+		t.Errorf("benchmark against itself %v, want 0", s)
+	}
+}
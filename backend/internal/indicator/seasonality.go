@@ -0,0 +1,118 @@
+package indicator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// DeadBucketRatio is the relative range and relative volume a bucket must both fall below to be dead
+const DeadBucketRatio = 0.6
+
+// MinSeasonalitySamples is the number of candles a bucket needs before it can be judged dead
+const MinSeasonalitySamples = 10
+
+// seasonalityAccumulator sums one bucket's candles
+type seasonalityAccumulator struct {
+	samples                     int
+	wins                        int
+	returnSum, rangeSum, volSum float64
+}
+
+func (a *seasonalityAccumulator) add(c model.Candle) {
+	a.samples++
+	if c.Close > c.Open {
+		a.wins++
+	}
+	a.returnSum += (c.Close/c.Open - 1) * 100
+	a.rangeSum += (c.High - c.Low) / c.Open * 100
+	a.volSum += c.Volume
+}
+
+// CalculateSeasonality groups candles by the hour of day, day of week and month their open falls in,
+// in loc, and reports each bucket's average return, win rate, range and volume. Range and volume are
+// also given relative to the average candle; buckets well below average on both are marked dead.
+func CalculateSeasonality(candles []model.Candle, loc *time.Location) (hours, weekdays, months []model.SeasonalityBucket) {
+	var total seasonalityAccumulator
+	var byHour [24]seasonalityAccumulator
+	var byWeekday [7]seasonalityAccumulator
+	var byMonth [12]seasonalityAccumulator
+
+	for _, c := range candles {
+		if c.Open <= 0 {
+			continue
+		}
+		t := time.UnixMilli(c.Timestamp).In(loc)
+		total.add(c)
+		byHour[t.Hour()].add(c)
+		byWeekday[t.Weekday()].add(c)
+		byMonth[t.Month()-1].add(c)
+	}
+
+	hours = make([]model.SeasonalityBucket, 0, 24)
+	for h := range byHour {
+		if byHour[h].samples > 0 {
+			hours = append(hours, seasonalityBucket(h, fmt.Sprintf("%02d:00", h), byHour[h], total))
+		}
+	}
+	weekdays = make([]model.SeasonalityBucket, 0, 7)
+	for d := range byWeekday {
+		if byWeekday[d].samples > 0 {
+			weekdays = append(weekdays, seasonalityBucket(d, time.Weekday(d).String()[:3], byWeekday[d], total))
+		}
+	}
+	months = make([]model.SeasonalityBucket, 0, 12)
+	for m := range byMonth {
+		if byMonth[m].samples > 0 {
+			months = append(months, seasonalityBucket(m+1, time.Month(m + 1).String()[:3], byMonth[m], total))
+		}
+	}
+
+	return hours, weekdays, months
+}
+
+func seasonalityBucket(key int, label string, a, total seasonalityAccumulator) model.SeasonalityBucket {
+	n := float64(a.samples)
+	bucket := model.SeasonalityBucket{
+		Key:       key,
+		Label:     label,
+		Samples:   a.samples,
+		AvgReturn: a.returnSum / n,
+		WinRate:   float64(a.wins) / n,
+		AvgRange:  a.rangeSum / n,
+		AvgVolume: a.volSum / n,
+	}
+
+	totalN := float64(total.samples)
+	if total.rangeSum > 0 {
+		bucket.RelativeRange = bucket.AvgRange / (total.rangeSum / totalN)
+	}
+	if total.volSum > 0 {
+		bucket.RelativeVolume = bucket.AvgVolume / (total.volSum / totalN)
+	}
+	bucket.Dead = a.samples >= MinSeasonalitySamples &&
+		bucket.RelativeRange < DeadBucketRatio && bucket.RelativeVolume < DeadBucketRatio
+
+	return bucket
+}
+
+// IsDeadHour reports whether the hour of day timestamp falls in (in the seasonality's timezone) is a
+// dead bucket. Unknown timezones and hours without statistics are never dead.
+func IsDeadHour(seasonality *model.Seasonality, timestamp int64) bool {
+	if seasonality == nil {
+		return false
+	}
+	loc, err := time.LoadLocation(seasonality.Timezone)
+	if err != nil {
+		return false
+	}
+
+	hour := time.UnixMilli(timestamp).In(loc).Hour()
+	for _, bucket := range seasonality.Hours {
+		if bucket.Key == hour {
+			return bucket.Dead
+		}
+	}
+	return false
+}
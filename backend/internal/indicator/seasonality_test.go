@@ -0,0 +1,58 @@
+package indicator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+func TestCalculateSeasonality(t *testing.T) {
+	// 30 days of hourly candles from a Monday; 02:00-04:59 UTC is quiet, 14:00 UTC always rallies
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	candles := make([]model.Candle, 0, 30*24)
+	for i := 0; i < 30*24; i++ {
+		ts := start.Add(time.Duration(i) * time.Hour)
+		c := model.Candle{Timestamp: ts.UnixMilli(), Open: 100, High: 101, Low: 99, Close: 99.9, Volume: 1000}
+		switch ts.Hour() {
+		case 2, 3, 4:
+			c.High, c.Low, c.Volume = 100.2, 99.8, 200
+		case 14:
+			c.Close = 100.8
+		}
+		candles = append(candles, c)
+	}
+
+	hours, weekdays, months := CalculateSeasonality(candles, time.UTC)
+	if len(hours) != 24 || len(weekdays) != 7 || len(months) != 1 {
+		t.Fatalf("got %d hours, %d weekdays, %d months", len(hours), len(weekdays), len(months))
+	}
+	for _, h := range hours {
+		quiet := h.Key >= 2 && h.Key <= 4
+		if h.Dead != quiet {
+			t.Errorf("hour %s dead = %v", h.Label, h.Dead)
+		}
+	}
+	if hours[14].WinRate != 1 || hours[14].AvgReturn <= 0 {
+		t.Errorf("14:00 bucket %+v, want every candle up", hours[14])
+	}
+	if weekdays[1].Label != "Mon" || months[0].Label != "Jan" {
+		t.Errorf("labels %q %q", weekdays[1].Label, months[0].Label)
+	}
+
+	// The same quiet hours read as 10:00-12:59 in UTC+8
+	utc8 := time.FixedZone("UTC+8", 8*3600)
+	hours, _, _ = CalculateSeasonality(candles, utc8)
+	if !hours[10].Dead || hours[2].Dead {
+		t.Errorf("UTC+8 dead hours not shifted: 10:00 %v, 02:00 %v", hours[10].Dead, hours[2].Dead)
+	}
+
+	seasonality := &model.Seasonality{Timezone: "UTC"}
+	seasonality.Hours, _, _ = CalculateSeasonality(candles, time.UTC)
+	if !IsDeadHour(seasonality, start.Add(3*time.Hour).UnixMilli()) {
+		t.Error("03:00 UTC should be dead")
+	}
+	if IsDeadHour(seasonality, start.Add(14*time.Hour).UnixMilli()) {
+		t.Error("14:00 UTC should not be dead")
+	}
+}
@@ -0,0 +1,254 @@
+package indicator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// SessionTimeframe is the candle interval sessions are built from when the analysis interval is too coarse
+const SessionTimeframe = "1h"
+
+// sessionAverageCount is the number of completed instances averaged for a session's typical range
+const sessionAverageCount = 20
+
+// sessionSweepLookback is the number of completed instances whose extremes are checked for sweeps
+const sessionSweepLookback = 3
+
+// SessionDefinition is a recurring trading session in local time. Start and End are "HH:MM" in the
+// IANA Timezone, so the session follows that zone's daylight saving; an End before Start spans midnight.
+type SessionDefinition struct {
+	Name     string
+	Timezone string
+	Start    string
+	End      string
+}
+
+// DefaultSessions returns the Asia (Tokyo), London and New York sessions
+func DefaultSessions() []SessionDefinition {
+	return []SessionDefinition{
+		{Name: "ASIA", Timezone: "Asia/Tokyo", Start: "09:00", End: "18:00"},
+		{Name: "LONDON", Timezone: "Europe/London", Start: "08:00", End: "17:00"},
+		{Name: "NEW_YORK", Timezone: "America/New_York", Start: "08:00", End: "17:00"},
+	}
+}
+
+// ParseSessions parses comma-separated "NAME@Timezone@HH:MM-HH:MM" session definitions,
+// e.g. "ASIA@Asia/Tokyo@09:00-18:00,LONDON@Europe/London@08:00-17:00"
+func ParseSessions(spec string) ([]SessionDefinition, error) {
+	sessions := make([]SessionDefinition, 0)
+	for _, item := range strings.Split(spec, ",") {
+		parts := strings.Split(strings.TrimSpace(item), "@")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid session %q: want NAME@Timezone@HH:MM-HH:MM", item)
+		}
+		hours := strings.Split(parts[2], "-")
+		if len(hours) != 2 {
+			return nil, fmt.Errorf("invalid session hours %q: want HH:MM-HH:MM", parts[2])
+		}
+		def := SessionDefinition{Name: parts[0], Timezone: parts[1], Start: hours[0], End: hours[1]}
+		if _, _, _, err := def.resolve(); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, def)
+	}
+	return sessions, nil
+}
+
+// resolve loads the definition's timezone and converts Start and End to minutes of the day
+func (d SessionDefinition) resolve() (*time.Location, int, int, error) {
+	loc, err := time.LoadLocation(d.Timezone)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("invalid session timezone %q: %w", d.Timezone, err)
+	}
+	start, err := time.Parse("15:04", d.Start)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("invalid session start %q: %w", d.Start, err)
+	}
+	end, err := time.Parse("15:04", d.End)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("invalid session end %q: %w", d.End, err)
+	}
+	startMin, endMin := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+	if startMin == endMin {
+		return nil, 0, 0, fmt.Errorf("session %s starts and ends at %s", d.Name, d.Start)
+	}
+	return loc, startMin, endMin, nil
+}
+
+// sessionInstance collects the candles opening inside one occurrence of a session
+type sessionInstance struct {
+	rng     model.SessionRange
+	partial bool // Candles covering the session open are missing
+}
+
+// CalculateSessions splits intraday candles into session instances by their open time and reports, per
+// session, the instance in progress, the latest completed instance, the average range of completed
+// instances and sweeps of recent session highs and lows. Returns nil for intervals above one hour.
+func CalculateSessions(candles []model.Candle, interval string, defs []SessionDefinition) *model.SessionAnalysis {
+	duration := IntervalDuration(interval)
+	if len(candles) == 0 || duration <= 0 || duration > time.Hour {
+		return nil
+	}
+
+	result := &model.SessionAnalysis{
+		Timeframe: interval,
+		Active:    []string{},
+		Sessions:  []model.TradingSession{},
+	}
+	for _, def := range defs {
+		loc, startMin, endMin, err := def.resolve()
+		if err != nil {
+			continue
+		}
+
+		instances := sessionInstances(candles, duration, loc, startMin, endMin)
+		session := model.TradingSession{
+			Name:     def.Name,
+			Timezone: def.Timezone,
+			Start:    def.Start,
+			End:      def.End,
+			Sweeps:   []model.SessionSweep{},
+		}
+
+		completed := make([]sessionInstance, 0)
+		for _, inst := range instances {
+			if inst.rng.Complete && !inst.partial {
+				completed = append(completed, inst)
+			}
+		}
+		if len(completed) > 0 {
+			previous := completed[len(completed)-1].rng
+			session.Previous = &previous
+
+			recent := completed[max(0, len(completed)-sessionAverageCount):]
+			total := 0.0
+			for _, inst := range recent {
+				total += inst.rng.High - inst.rng.Low
+			}
+			session.AvgRange = total / float64(len(recent))
+		}
+
+		if len(instances) > 0 && !instances[len(instances)-1].rng.Complete {
+			current := instances[len(instances)-1].rng
+			session.Active = true
+			session.Current = &current
+			if session.AvgRange > 0 {
+				session.RangeRatio = (current.High - current.Low) / session.AvgRange
+			}
+			result.Active = append(result.Active, def.Name)
+		}
+
+		for _, inst := range completed[max(0, len(completed)-sessionSweepLookback):] {
+			session.Sweeps = append(session.Sweeps, sessionSweeps(candles, def.Name, inst.rng)...)
+		}
+		sort.Slice(session.Sweeps, func(i, j int) bool {
+			return session.Sweeps[i].Timestamp < session.Sweeps[j].Timestamp
+		})
+
+		result.Sessions = append(result.Sessions, session)
+	}
+
+	return result
+}
+
+// sessionInstances groups the candles into session occurrences, oldest first. An occurrence is complete
+// once a candle opening at or after its end exists. It is partial when its first candle opens a full bar
+// after the session start, or opens after the start without the candle before it, so the open is missing.
+// A session starting mid-bar (09:30 on hourly candles) is whole when its first candle opens at 10:00.
+func sessionInstances(candles []model.Candle, bar time.Duration, loc *time.Location, startMin, endMin int) []sessionInstance {
+	instances := make([]sessionInstance, 0)
+	last := candles[len(candles)-1].Timestamp
+	barMs := bar.Milliseconds()
+
+	for i, c := range candles {
+		t := time.UnixMilli(c.Timestamp).In(loc)
+		minute := t.Hour()*60 + t.Minute()
+		year, month, day := t.Date()
+
+		if startMin < endMin {
+			if minute < startMin || minute >= endMin {
+				continue
+			}
+		} else if minute < endMin {
+			// Spans midnight: the early hours belong to the session that opened the day before
+			day--
+		} else if minute < startMin {
+			continue
+		}
+
+		start := time.Date(year, month, day, startMin/60, startMin%60, 0, 0, loc)
+		endDay := day
+		if startMin > endMin {
+			endDay++
+		}
+		end := time.Date(year, month, endDay, endMin/60, endMin%60, 0, 0, loc)
+
+		n := len(instances)
+		if n == 0 || instances[n-1].rng.Start != start.UnixMilli() {
+			previousMissing := i == 0 || candles[i-1].Timestamp != c.Timestamp-barMs
+			instances = append(instances, sessionInstance{
+				rng: model.SessionRange{
+					Start:    start.UnixMilli(),
+					End:      end.UnixMilli(),
+					Open:     c.Open,
+					High:     c.High,
+					Low:      c.Low,
+					Complete: last >= end.UnixMilli(),
+				},
+				partial: c.Timestamp >= start.UnixMilli()+barMs || (c.Timestamp > start.UnixMilli() && previousMissing),
+			})
+			n++
+		}
+
+		rng := &instances[n-1].rng
+		if c.High > rng.High {
+			rng.High = c.High
+		}
+		if c.Low < rng.Low {
+			rng.Low = c.Low
+		}
+		rng.Close = c.Close
+	}
+
+	return instances
+}
+
+// sessionSweeps finds, for each side of a completed session, the first candle after it that traded
+// beyond the extreme. It is a sweep when that candle closed back inside; a close beyond is a break.
+func sessionSweeps(candles []model.Candle, name string, rng model.SessionRange) []model.SessionSweep {
+	sweeps := make([]model.SessionSweep, 0)
+	highDone, lowDone := false, false
+
+	for _, c := range candles {
+		if c.Timestamp < rng.End {
+			continue
+		}
+		if !highDone && c.High > rng.High {
+			highDone = true
+			if c.Close < rng.High {
+				sweeps = append(sweeps, model.SessionSweep{
+					Session: name, Side: "HIGH", Price: rng.High, Extreme: c.High,
+					SessionStart: rng.Start, Timestamp: c.Timestamp,
+				})
+			}
+		}
+		if !lowDone && c.Low < rng.Low {
+			lowDone = true
+			if c.Close > rng.Low {
+				sweeps = append(sweeps, model.SessionSweep{
+					Session: name, Side: "LOW", Price: rng.Low, Extreme: c.Low,
+					SessionStart: rng.Start, Timestamp: c.Timestamp,
+				})
+			}
+		}
+		if highDone && lowDone {
+			break
+		}
+	}
+
+	return sweeps
+}
@@ -0,0 +1,132 @@
+package indicator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalculateSessionsFollowsDST(t *testing.T) {
+	london := []SessionDefinition{{Name: "LONDON", Timezone: "Europe/London", Start: "08:00", End: "17:00"}}
+
+	// 08:00 London is 08:00 UTC in winter and 07:00 UTC in summer
+	for _, tc := range []struct {
+		start   time.Time
+		openUTC int
+	}{
+		{time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), 8},
+		{time.Date(2024, 7, 8, 0, 0, 0, 0, time.UTC), 7},
+	} {
+		sessions := CalculateSessions(flatCandles(tc.start.UnixMilli(), 72, 100, 101, 99), "1h", london)
+		if sessions == nil || len(sessions.Sessions) != 1 {
+			t.Fatalf("sessions %+v", sessions)
+		}
+		previous := sessions.Sessions[0].Previous
+		if previous == nil {
+			t.Fatal("no completed London session")
+		}
+		if h := time.UnixMilli(previous.Start).UTC().Hour(); h != tc.openUTC {
+			t.Errorf("%s: London opened at %02d:00 UTC, want %02d:00", tc.start.Month(), h, tc.openUTC)
+		}
+		if sessions.Sessions[0].AvgRange != 2 {
+			t.Errorf("average range %v, want 2", sessions.Sessions[0].AvgRange)
+		}
+	}
+}
+
+func TestCalculateSessionsSweepAndActive(t *testing.T) {
+	def := []SessionDefinition{{Name: "ASIA", Timezone: "UTC", Start: "00:00", End: "09:00"}}
+	start := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	candles := flatCandles(start.UnixMilli(), 27, 100, 101, 99)
+
+	// 12:00 wicks above the Asia high and closes back below: a sweep. The low is broken, not swept.
+	candles[12].High, candles[12].Close = 102, 100.5
+	candles[14].Low, candles[14].Close = 97, 98
+
+	sessions := CalculateSessions(candles, "1h", def)
+	asia := sessions.Sessions[0]
+	if len(asia.Sweeps) != 1 || asia.Sweeps[0].Side != "HIGH" || asia.Sweeps[0].Price != 101 || asia.Sweeps[0].Extreme != 102 {
+		t.Fatalf("sweeps %+v, want one sweep of the 101 high", asia.Sweeps)
+	}
+
+	// The last candle (02:00 next day) is inside the next Asia session
+	if !asia.Active || asia.Current == nil || len(sessions.Active) != 1 {
+		t.Fatalf("Asia should be active: %+v", asia)
+	}
+	if asia.RangeRatio != 1 {
+		t.Errorf("range ratio %v, want 1", asia.RangeRatio)
+	}
+
+	if CalculateSessions(candles, "4h", def) != nil {
+		t.Error("sessions need intraday candles")
+	}
+}
+
+func TestCalculateSessionsAcrossMidnight(t *testing.T) {
+	def := []SessionDefinition{{Name: "NIGHT", Timezone: "UTC", Start: "22:00", End: "02:00"}}
+	start := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	sessions := CalculateSessions(flatCandles(start.UnixMilli(), 48, 100, 101, 99), "1h", def)
+	previous := sessions.Sessions[0].Previous
+	if previous == nil {
+		t.Fatal("no completed overnight session")
+	}
+	if got := time.UnixMilli(previous.Start).UTC(); got.Day() != 4 || got.Hour() != 22 {
+		t.Errorf("overnight session opened %v, want Mar 4 22:00", got)
+	}
+	if previous.End-previous.Start != (4 * time.Hour).Milliseconds() {
+		t.Errorf("overnight session lasts %d ms", previous.End-previous.Start)
+	}
+}
+
+func TestSessionInstancesHalfHourOpen(t *testing.T) {
+	ny := SessionDefinition{Name: "NY", Timezone: "America/New_York", Start: "09:30", End: "16:00"}
+	loc, startMin, endMin, err := ny.resolve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 09:30 New York is 14:30 UTC in March before DST, so the first whole hourly candle opens at 15:00
+	open := time.Date(2024, 3, 4, 14, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		from        time.Time
+		wantPartial bool
+	}{
+		{"candle before the open present", open.Add(-30 * time.Minute), false},
+		{"first candle after the open without the one before", open.Add(30 * time.Minute), true},
+		{"a full bar after the open", open.Add(90 * time.Minute), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instances := sessionInstances(flatCandles(tt.from.UnixMilli(), 30, 100, 101, 99), time.Hour, loc, startMin, endMin)
+			if len(instances) != 2 {
+				t.Fatalf("got %d instances, want 2", len(instances))
+			}
+			if instances[0].rng.Start != open.UnixMilli() || instances[0].partial != tt.wantPartial {
+				t.Errorf("first instance opened %v partial=%v, want %v partial=%v",
+					time.UnixMilli(instances[0].rng.Start).UTC(), instances[0].partial, open, tt.wantPartial)
+			}
+			if instances[1].partial {
+				t.Error("second day partial although its candles are all there")
+			}
+		})
+	}
+
+	// The completed New York session is reported even though no candle opens exactly at 09:30
+	sessions := CalculateSessions(flatCandles(open.Add(-30*time.Minute).UnixMilli(), 30, 100, 101, 99), "1h", []SessionDefinition{ny})
+	if previous := sessions.Sessions[0].Previous; previous == nil || previous.Start != open.UnixMilli() {
+		t.Errorf("previous session = %+v, want the one opening %v", previous, open)
+	}
+}
+
+func TestParseSessions(t *testing.T) {
+	sessions, err := ParseSessions("ASIA@Asia/Tokyo@09:00-18:00,NY@America/New_York@08:00-17:00")
+	if err != nil || len(sessions) != 2 || sessions[1].Timezone != "America/New_York" || sessions[1].End != "17:00" {
+		t.Fatalf("sessions %+v, err %v", sessions, err)
+	}
+	for _, spec := range []string{"ASIA@Asia/Tokyo", "ASIA@Nowhere/City@09:00-18:00", "ASIA@UTC@9-18", "ASIA@UTC@09:00-09:00"} {
+		if _, err := ParseSessions(spec); err == nil {
+			t.Errorf("%q should not parse", spec)
+		}
+	}
+}
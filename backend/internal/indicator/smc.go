@@ -0,0 +1,325 @@
+package indicator
+
+import (
+	"math"
+	"sort"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// smcScanBars limits how far back smart-money structures are reported
+const smcScanBars = 100
+
+// DetectSmartMoney detects smart-money concepts: order blocks and equal highs/lows liquidity pools
+// on the confirmed swings, fair value gaps, and liquidity sweeps of the given SR levels
+func DetectSmartMoney(candles []model.Candle, swings []model.SwingPoint, srLevels model.SRLevels) model.SmartMoney {
+	result := model.SmartMoney{
+		OrderBlocks:     make([]model.OrderBlock, 0),
+		FairValueGaps:   make([]model.FairValueGap, 0),
+		LiquidityPools:  make([]model.LiquidityPool, 0),
+		LiquiditySweeps: make([]model.LiquiditySweep, 0),
+	}
+	if len(candles) < 20 {
+		return result
+	}
+
+	atr := CalculateATR(candles, 14).GetCurrentATR()
+	if atr <= 0 {
+		return result
+	}
+
+	swings = ConfirmedSwings(swings)
+
+	result.OrderBlocks = detectOrderBlocks(candles, swings, atr)
+	result.FairValueGaps = detectFairValueGaps(candles, atr)
+	result.LiquidityPools = detectLiquidityPools(candles, swings, atr)
+	result.LiquiditySweeps = detectLiquiditySweeps(candles, srLevels)
+
+	return result
+}
+
+// detectOrderBlocks finds the last opposite-colored candle before a displacement move
+// that breaks the prior swing. Blocks closed through are invalidated and dropped.
+func detectOrderBlocks(candles []model.Candle, swings []model.SwingPoint, atr float64) []model.OrderBlock {
+	blocks := make([]model.OrderBlock, 0)
+	n := len(candles)
+	start := max(1, n-smcScanBars)
+
+	for i := start; i < n-3; i++ {
+		c := candles[i]
+		bullish := c.Close < c.Open
+		bearish := c.Close > c.Open
+		if !bullish && !bearish {
+			continue
+		}
+
+		// Displacement: the following three candles travel at least 1.5 ATR beyond the block
+		displacementEnd := min(n-1, i+3)
+		broken := false
+		if bullish {
+			highestClose := candles[i+1].Close
+			for j := i + 1; j <= displacementEnd; j++ {
+				highestClose = math.Max(highestClose, candles[j].Close)
+			}
+			if highestClose-c.High < 1.5*atr {
+				continue
+			}
+			// The move must break the most recent swing high before the block
+			if prior := lastSwingBefore(swings, i, model.SwingHigh); prior != nil && highestClose > prior.Price {
+				broken = true
+			}
+		} else {
+			lowestClose := candles[i+1].Close
+			for j := i + 1; j <= displacementEnd; j++ {
+				lowestClose = math.Min(lowestClose, candles[j].Close)
+			}
+			if c.Low-lowestClose < 1.5*atr {
+				continue
+			}
+			if prior := lastSwingBefore(swings, i, model.SwingLow); prior != nil && lowestClose < prior.Price {
+				broken = true
+			}
+		}
+		if !broken {
+			continue
+		}
+
+		block := model.OrderBlock{
+			Type:      "BULLISH",
+			High:      c.High,
+			Low:       c.Low,
+			Index:     i,
+			Timestamp: c.Timestamp,
+		}
+		if !bullish {
+			block.Type = "BEARISH"
+		}
+
+		// Track mitigation (price returns into the block) and invalidation (close through it)
+		invalidated := false
+		for j := displacementEnd + 1; j < n; j++ {
+			if bullish {
+				if candles[j].Close < block.Low {
+					invalidated = true
+					break
+				}
+				if candles[j].Low <= block.High {
+					block.Mitigated = true
+				}
+			} else {
+				if candles[j].Close > block.High {
+					invalidated = true
+					break
+				}
+				if candles[j].High >= block.Low {
+					block.Mitigated = true
+				}
+			}
+		}
+		if invalidated {
+			continue
+		}
+
+		block.Strength = math.Min(1, (c.High-c.Low)/atr*0.5)
+		if block.Mitigated {
+			block.Strength *= 0.6
+		}
+		blocks = append(blocks, block)
+	}
+
+	// Most recent first, three per side
+	sort.SliceStable(blocks, func(i, j int) bool {
+		return blocks[i].Index > blocks[j].Index
+	})
+	return limitPerType(blocks, 3, func(b model.OrderBlock) string { return b.Type })
+}
+
+// detectFairValueGaps finds three-candle imbalances where the first and third candles do not
+// overlap, and tracks how much of each gap has been filled since
+func detectFairValueGaps(candles []model.Candle, atr float64) []model.FairValueGap {
+	gaps := make([]model.FairValueGap, 0)
+	n := len(candles)
+	start := max(1, n-smcScanBars)
+
+	for i := start; i < n-1; i++ {
+		prev, next := candles[i-1], candles[i+1]
+
+		var gap model.FairValueGap
+		switch {
+		case next.Low > prev.High:
+			gap = model.FairValueGap{Type: "BULLISH", High: next.Low, Low: prev.High}
+		case next.High < prev.Low:
+			gap = model.FairValueGap{Type: "BEARISH", High: prev.Low, Low: next.High}
+		default:
+			continue
+		}
+		if gap.High-gap.Low < 0.1*atr {
+			continue
+		}
+		gap.Index = i
+		gap.Timestamp = candles[i].Timestamp
+
+		// Bullish gaps fill from the top down, bearish gaps from the bottom up
+		size := gap.High - gap.Low
+		filled := 0.0
+		for j := i + 2; j < n; j++ {
+			if gap.Type == "BULLISH" {
+				filled = math.Max(filled, gap.High-candles[j].Low)
+			} else {
+				filled = math.Max(filled, candles[j].High-gap.Low)
+			}
+		}
+		gap.FillPercent = math.Max(0, math.Min(100, filled/size*100))
+		gap.Filled = gap.FillPercent >= 100
+
+		gaps = append(gaps, gap)
+	}
+
+	// Open gaps first, then most recent
+	sort.SliceStable(gaps, func(i, j int) bool {
+		if gaps[i].Filled != gaps[j].Filled {
+			return !gaps[i].Filled
+		}
+		return gaps[i].Index > gaps[j].Index
+	})
+	if len(gaps) > 10 {
+		gaps = gaps[:10]
+	}
+	return gaps
+}
+
+// detectLiquidityPools groups swing highs (lows) within 0.1 ATR of each other into
+// equal highs (lows); resting stops sit just beyond them
+func detectLiquidityPools(candles []model.Candle, swings []model.SwingPoint, atr float64) []model.LiquidityPool {
+	pools := make([]model.LiquidityPool, 0)
+	n := len(candles)
+	tolerance := 0.1 * atr
+
+	for _, swingType := range []string{model.SwingHigh, model.SwingLow} {
+		points := make([]model.SwingPoint, 0)
+		for _, s := range swings {
+			if s.Type == swingType && s.Index >= n-smcScanBars {
+				points = append(points, s)
+			}
+		}
+
+		used := make([]bool, len(points))
+		for i := range points {
+			if used[i] {
+				continue
+			}
+			members := []model.SwingPoint{points[i]}
+			for j := i + 1; j < len(points); j++ {
+				if !used[j] && math.Abs(points[j].Price-points[i].Price) <= tolerance {
+					members = append(members, points[j])
+					used[j] = true
+				}
+			}
+			if len(members) < 2 {
+				continue
+			}
+
+			pool := model.LiquidityPool{
+				Type:    "EQUAL_HIGHS",
+				Touches: len(members),
+				Indices: make([]int, 0, len(members)),
+			}
+			if swingType == model.SwingLow {
+				pool.Type = "EQUAL_LOWS"
+			}
+			pool.Price = members[0].Price
+			lastIdx := members[0].Index
+			for _, m := range members {
+				pool.Indices = append(pool.Indices, m.Index)
+				lastIdx = max(lastIdx, m.Index)
+				if swingType == model.SwingHigh {
+					pool.Price = math.Max(pool.Price, m.Price)
+				} else {
+					pool.Price = math.Min(pool.Price, m.Price)
+				}
+			}
+
+			// Swept once price trades beyond the pool after its last touch
+			for j := lastIdx + 1; j < n; j++ {
+				if (swingType == model.SwingHigh && candles[j].High > pool.Price) ||
+					(swingType == model.SwingLow && candles[j].Low < pool.Price) {
+					pool.Swept = true
+					break
+				}
+			}
+
+			pools = append(pools, pool)
+		}
+	}
+
+	return pools
+}
+
+// detectLiquiditySweeps finds recent candles that wick through an SR level and close back inside
+func detectLiquiditySweeps(candles []model.Candle, srLevels model.SRLevels) []model.LiquiditySweep {
+	sweeps := make([]model.LiquiditySweep, 0)
+	n := len(candles)
+
+	for i := max(0, n-20); i < n; i++ {
+		c := candles[i]
+		for _, level := range srLevels.Resistance {
+			if c.High > level.Price && c.Close < level.Price {
+				sweeps = append(sweeps, model.LiquiditySweep{
+					Type:        "BUY_SIDE",
+					Level:       level.Price,
+					LevelSource: level.Source,
+					Index:       i,
+					Timestamp:   c.Timestamp,
+					WickPrice:   c.High,
+					Close:       c.Close,
+				})
+			}
+		}
+		for _, level := range srLevels.Support {
+			if c.Low < level.Price && c.Close > level.Price {
+				sweeps = append(sweeps, model.LiquiditySweep{
+					Type:        "SELL_SIDE",
+					Level:       level.Price,
+					LevelSource: level.Source,
+					Index:       i,
+					Timestamp:   c.Timestamp,
+					WickPrice:   c.Low,
+					Close:       c.Close,
+				})
+			}
+		}
+	}
+
+	// Most recent first
+	sort.SliceStable(sweeps, func(i, j int) bool {
+		return sweeps[i].Index > sweeps[j].Index
+	})
+	if len(sweeps) > 5 {
+		sweeps = sweeps[:5]
+	}
+	return sweeps
+}
+
+// lastSwingBefore returns the most recent swing of the given type before index
+func lastSwingBefore(swings []model.SwingPoint, index int, swingType string) *model.SwingPoint {
+	for i := len(swings) - 1; i >= 0; i-- {
+		if swings[i].Index < index && swings[i].Type == swingType {
+			return &swings[i]
+		}
+	}
+	return nil
+}
+
+// limitPerType keeps at most limit items of each type, preserving order
+func limitPerType[T any](items []T, limit int, typeOf func(T) string) []T {
+	counts := make(map[string]int)
+	result := make([]T, 0, len(items))
+	for _, item := range items {
+		t := typeOf(item)
+		if counts[t] < limit {
+			counts[t]++
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,176 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+func TestDetectOrderBlocks(t *testing.T) {
+	// A down candle at 2 (the block, 99.4-100.6) before a displacement to 102.5 that breaks the 101 swing high
+	base := []model.Candle{
+		{Open: 100, High: 100.5, Low: 99.5, Close: 100},
+		{Open: 100, High: 101, Low: 99.8, Close: 100.5},
+		{Open: 100.5, High: 100.6, Low: 99.4, Close: 99.5},
+		{Open: 99.5, High: 101.5, Low: 99.4, Close: 101.3},
+		{Open: 101.3, High: 102.6, Low: 101.2, Close: 102.5},
+		{Open: 102.5, High: 103.2, Low: 102.4, Close: 103},
+	}
+	swings := []model.SwingPoint{{Index: 1, Price: 101, Type: model.SwingHigh, Confirmed: true}}
+
+	tests := []struct {
+		name          string
+		tail          model.Candle
+		wantBlock     bool
+		wantMitigated bool
+	}{
+		{"untouched", model.Candle{Open: 103, High: 103.5, Low: 102.8, Close: 103.2}, true, false},
+		{"mitigated by a wick into the block", model.Candle{Open: 103, High: 103.1, Low: 100.4, Close: 101}, true, true},
+		{"invalidated by a close below it", model.Candle{Open: 103, High: 103.1, Low: 98.8, Close: 99}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candles := append(append([]model.Candle{}, base...), tt.tail)
+			for i := range candles {
+				candles[i].Timestamp = int64(i) * hourMs
+			}
+
+			check := func(blocks []model.OrderBlock, wantType string, high, low float64) {
+				t.Helper()
+				if !tt.wantBlock {
+					if len(blocks) != 0 {
+						t.Fatalf("got %+v, want no blocks", blocks)
+					}
+					return
+				}
+				if len(blocks) != 1 {
+					t.Fatalf("got %d blocks %+v, want 1", len(blocks), blocks)
+				}
+				b := blocks[0]
+				if b.Type != wantType || b.Index != 2 || math.Abs(b.High-high) > 1e-9 || math.Abs(b.Low-low) > 1e-9 {
+					t.Errorf("got %s block at %d %v-%v, want %s at 2 %v-%v", b.Type, b.Index, b.Low, b.High, wantType, low, high)
+				}
+				// Strength is half the block's range in ATRs, cut by 40% once mitigated
+				wantStrength := 0.6
+				if tt.wantMitigated {
+					wantStrength *= 0.6
+				}
+				if b.Mitigated != tt.wantMitigated || math.Abs(b.Strength-wantStrength) > 1e-9 {
+					t.Errorf("mitigated %v strength %v, want %v and %v", b.Mitigated, b.Strength, tt.wantMitigated, wantStrength)
+				}
+			}
+
+			check(detectOrderBlocks(candles, swings, 1), "BULLISH", 100.6, 99.4)
+
+			// The mirrored market has a bearish block at the same bar
+			mirroredSwings := []model.SwingPoint{{Index: 1, Price: -101, Type: model.SwingLow, Confirmed: true}}
+			check(detectOrderBlocks(mirrorCandles(candles), mirroredSwings, 1), "BEARISH", -99.4, -100.6)
+		})
+	}
+}
+
+func TestDetectFairValueGaps(t *testing.T) {
+	// Candle 1 leaves a 100-101 gap between candle 0's high and candle 2's low
+	base := []model.Candle{
+		{Open: 99.5, High: 100, Low: 99, Close: 99.8},
+		{Open: 100, High: 103.2, Low: 99.8, Close: 103},
+		{Open: 103, High: 104, Low: 101, Close: 103.5},
+	}
+
+	tests := []struct {
+		name       string
+		low        float64 // Lowest later low
+		wantFill   float64
+		wantFilled bool
+	}{
+		{"open", 102, 0, false},
+		{"half filled", 100.5, 50, false},
+		{"filled", 99.9, 100, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candles := append(append([]model.Candle{}, base...),
+				model.Candle{Open: 103.5, High: 104, Low: 103, Close: 103.2},
+				model.Candle{Open: 103.2, High: 103.6, Low: tt.low, Close: 103},
+			)
+			gaps := detectFairValueGaps(candles, 1)
+			if len(gaps) != 1 {
+				t.Fatalf("got %d gaps %+v, want 1", len(gaps), gaps)
+			}
+			g := gaps[0]
+			if g.Type != "BULLISH" || g.Index != 1 || g.Low != 100 || g.High != 101 {
+				t.Errorf("got %s gap at %d %v-%v, want BULLISH at 1 100-101", g.Type, g.Index, g.Low, g.High)
+			}
+			if math.Abs(g.FillPercent-tt.wantFill) > 1e-9 || g.Filled != tt.wantFilled {
+				t.Errorf("fill %v%% filled=%v, want %v%% filled=%v", g.FillPercent, g.Filled, tt.wantFill, tt.wantFilled)
+			}
+
+			// Mirrored, the gap is bearish and fills from the bottom up by the same amount
+			mirrored := detectFairValueGaps(mirrorCandles(candles), 1)
+			if len(mirrored) != 1 || mirrored[0].Type != "BEARISH" || math.Abs(mirrored[0].FillPercent-tt.wantFill) > 1e-9 {
+				t.Errorf("mirrored gaps %+v, want one BEARISH gap filled %v%%", mirrored, tt.wantFill)
+			}
+		})
+	}
+
+	// Gaps under a tenth of an ATR are noise
+	if gaps := detectFairValueGaps(base, 20); len(gaps) != 0 {
+		t.Errorf("got %+v, want gaps smaller than 0.1 ATR ignored", gaps)
+	}
+}
+
+func TestDetectLiquidityPools(t *testing.T) {
+	swings := []model.SwingPoint{
+		{Index: 5, Price: 110, Type: model.SwingHigh},
+		{Index: 10, Price: 100, Type: model.SwingLow},
+		{Index: 15, Price: 110.05, Type: model.SwingHigh},
+		{Index: 20, Price: 95, Type: model.SwingLow},
+		{Index: 25, Price: 108, Type: model.SwingHigh},
+	}
+
+	for _, swept := range []bool{false, true} {
+		candles := flatCandles(0, 30, 105, 105.5, 104.5)
+		if swept {
+			candles[28].High = 110.3
+		}
+
+		pools := detectLiquidityPools(candles, swings, 1)
+		if len(pools) != 1 {
+			t.Fatalf("swept=%v: got %d pools %+v, want only the equal highs", swept, len(pools), pools)
+		}
+		p := pools[0]
+		if p.Type != "EQUAL_HIGHS" || p.Price != 110.05 || p.Touches != 2 || len(p.Indices) != 2 || p.Indices[0] != 5 || p.Indices[1] != 15 {
+			t.Errorf("swept=%v: got %+v, want EQUAL_HIGHS at 110.05 touched at 5 and 15", swept, p)
+		}
+		if p.Swept != swept {
+			t.Errorf("swept=%v: pool swept %v", swept, p.Swept)
+		}
+	}
+}
+
+func TestDetectLiquiditySweeps(t *testing.T) {
+	candles := flatCandles(0, 30, 105, 105.5, 104.5)
+	candles[24] = model.Candle{Timestamp: candles[24].Timestamp, Open: 105, High: 111, Low: 104.5, Close: 110.5} // Breakout, not a sweep
+	candles[26] = model.Candle{Timestamp: candles[26].Timestamp, Open: 105, High: 110.5, Low: 104.5, Close: 109.5}
+	candles[28] = model.Candle{Timestamp: candles[28].Timestamp, Open: 101, High: 101.5, Low: 99.5, Close: 100.5}
+	candles[5] = model.Candle{Timestamp: candles[5].Timestamp, Open: 105, High: 110.5, Low: 104.5, Close: 109.5} // Too old
+	levels := model.SRLevels{
+		Resistance: []model.SRLevel{{Price: 110, Source: "PRICE_CLUSTER"}},
+		Support:    []model.SRLevel{{Price: 100, Source: "SWING"}},
+	}
+
+	sweeps := detectLiquiditySweeps(candles, levels)
+	if len(sweeps) != 2 {
+		t.Fatalf("got %d sweeps %+v, want 2", len(sweeps), sweeps)
+	}
+	sell, buy := sweeps[0], sweeps[1]
+	if sell.Type != "SELL_SIDE" || sell.Index != 28 || sell.Level != 100 || sell.WickPrice != 99.5 || sell.LevelSource != "SWING" {
+		t.Errorf("got %+v, want the sell-side sweep of 100 at 28", sell)
+	}
+	if buy.Type != "BUY_SIDE" || buy.Index != 26 || buy.Level != 110 || buy.WickPrice != 110.5 || buy.Close != 109.5 {
+		t.Errorf("got %+v, want the buy-side sweep of 110 at 26", buy)
+	}
+}
@@ -72,10 +72,11 @@ type PricePoint struct {
 
 // PriceCluster represents a cluster of prices
 type PriceCluster struct {
-	Price    float64
-	Strength float64
-	Count    int
-	Volume   float64
+	Price  float64
+	Lower  float64
+	Upper  float64
+	Count  int
+	Volume float64
 }
 
 // CalculateSRLevels calculates support and resistance levels with timeframe awareness
@@ -85,6 +86,12 @@ func CalculateSRLevels(candles []model.Candle, lookback int) model.SRLevels {
 
 // CalculateSRLevelsWithInterval calculates SR levels with interval-specific parameters
 func CalculateSRLevelsWithInterval(candles []model.Candle, lookback int, interval string) model.SRLevels {
+	return CalculateSRLevelsWithSwings(candles, FindSwings(candles, DefaultSwingConfig()), lookback, interval)
+}
+
+// CalculateSRLevelsWithSwings calculates SR levels with interval-specific parameters, topping up sides
+// with too few levels from the given swings
+func CalculateSRLevelsWithSwings(candles []model.Candle, swings []model.SwingPoint, lookback int, interval string) model.SRLevels {
 	if len(candles) < 10 {
 		return model.SRLevels{
 			Resistance: []model.SRLevel{},
@@ -126,14 +133,8 @@ func CalculateSRLevelsWithInterval(candles []model.Candle, lookback int, interva
 		return pricePoints[i].Price < pricePoints[j].Price
 	})
 
-	// Dynamic threshold based on ATR and config
-	dynamicThreshold := math.Max(
-		currentPrice*config.ClusterThreshold,
-		atr*0.5, // At least half ATR
-	)
-
 	// Cluster prices
-	clusters := clusterPricesAdvanced(pricePoints, dynamicThreshold, config.MinClusterSize)
+	clusters := clusterPricesAdvanced(pricePoints, clusterThreshold(config, currentPrice, atr), config.MinClusterSize)
 
 	// Separate into support and resistance
 	resistance := make([]model.SRLevel, 0)
@@ -141,8 +142,10 @@ func CalculateSRLevelsWithInterval(candles []model.Candle, lookback int, interva
 
 	for _, cluster := range clusters {
 		level := model.SRLevel{
-			Price:    cluster.Price,
-			Strength: cluster.Strength,
+			Price:  cluster.Price,
+			Lower:  cluster.Lower,
+			Upper:  cluster.Upper,
+			Source: "PRICE_CLUSTER",
 		}
 
 		// Add buffer zone around current price
@@ -166,8 +169,12 @@ func CalculateSRLevelsWithInterval(candles []model.Candle, lookback int, interva
 	})
 
 	// Ensure minimum levels - add recent highs/lows if needed
-	resistance = ensureMinimumLevels(resistance, candles, currentPrice, true, config.MinLevels, config.LookbackPeriod)
-	support = ensureMinimumLevels(support, candles, currentPrice, false, config.MinLevels, config.LookbackPeriod)
+	resistance = ensureMinimumLevels(resistance, swings, currentPrice, true, config.MinLevels, startIdx)
+	support = ensureMinimumLevels(support, swings, currentPrice, false, config.MinLevels, startIdx)
+
+	// Score every level as a zone: touches, relative volume and recency over the whole window
+	describeSRLevels(resistance, candles, atr)
+	describeSRLevels(support, candles, atr)
 
 	// Limit to top 5
 	if len(resistance) > 5 {
@@ -183,7 +190,13 @@ func CalculateSRLevelsWithInterval(candles []model.Candle, lookback int, interva
 	}
 }
 
-// clusterPricesAdvanced uses volume-weighted clustering
+// clusterThreshold is the price distance within which prices belong to the same level:
+// the timeframe's percentage of price, but at least half an ATR
+func clusterThreshold(config TimeframeConfig, price, atr float64) float64 {
+	return math.Max(price*config.ClusterThreshold, atr*0.5)
+}
+
+// clusterPricesAdvanced groups nearby prices into clusters spanning their lowest and highest member
 func clusterPricesAdvanced(points []PricePoint, threshold float64, minSize int) []PriceCluster {
 	if len(points) == 0 {
 		return []PriceCluster{}
@@ -206,17 +219,12 @@ func clusterPricesAdvanced(points []PricePoint, threshold float64, minSize int)
 
 		// Only create cluster if meets minimum size
 		if len(clusterPrices) >= minSize {
-			avgPrice := average(clusterPrices)
-			
-			// Calculate strength based on count and volume
-			countStrength := float64(len(clusterPrices)) / float64(len(points))
-			volumeStrength := math.Min(1.0, totalVolume/100000) // Normalize volume
-			
 			clusters = append(clusters, PriceCluster{
-				Price:    avgPrice,
-				Count:    len(clusterPrices),
-				Volume:   totalVolume,
-				Strength: (countStrength*0.6 + volumeStrength*0.4), // Weighted combination
+				Price:  average(clusterPrices),
+				Lower:  clusterPrices[0],
+				Upper:  clusterPrices[len(clusterPrices)-1],
+				Count:  len(clusterPrices),
+				Volume: totalVolume,
 			})
 		}
 
@@ -227,13 +235,13 @@ func clusterPricesAdvanced(points []PricePoint, threshold float64, minSize int)
 }
 
 // ensureMinimumLevels adds recent swing highs/lows if not enough levels found
-func ensureMinimumLevels(levels []model.SRLevel, candles []model.Candle, currentPrice float64, isResistance bool, minLevels int, lookback int) []model.SRLevel {
+func ensureMinimumLevels(levels []model.SRLevel, swings []model.SwingPoint, currentPrice float64, isResistance bool, minLevels int, startIdx int) []model.SRLevel {
 	if len(levels) >= minLevels {
 		return levels
 	}
 
 	// Find swing points
-	swingPoints := findSwingPoints(candles, lookback, isResistance)
+	swingPoints := findSwingPoints(swings, startIdx, isResistance)
 	
 	// Filter by direction
 	for _, swing := range swingPoints {
@@ -272,47 +280,100 @@ func ensureMinimumLevels(levels []model.SRLevel, candles []model.Candle, current
 	return levels
 }
 
-// findSwingPoints identifies local highs and lows
-func findSwingPoints(candles []model.Candle, lookback int, findHighs bool) []model.SRLevel {
-	swings := make([]model.SRLevel, 0)
-	
-	startIdx := len(candles) - lookback
-	if startIdx < 2 {
-		startIdx = 2
+// findSwingPoints returns the confirmed swing highs or lows from startIdx on
+func findSwingPoints(swings []model.SwingPoint, startIdx int, findHighs bool) []model.SRLevel {
+	levels := make([]model.SRLevel, 0)
+
+	swingType := model.SwingLow
+	if findHighs {
+		swingType = model.SwingHigh
 	}
 
-	for i := startIdx; i < len(candles)-2; i++ {
-		if findHighs {
-			// Check if local high
-			if candles[i].High > candles[i-1].High && 
-			   candles[i].High > candles[i-2].High &&
-			   candles[i].High > candles[i+1].High &&
-			   candles[i].High > candles[i+2].High {
-				swings = append(swings, model.SRLevel{
-					Price:    candles[i].High,
-					Strength: 0.3, // Lower strength for swing points
-				})
-			}
-		} else {
-			// Check if local low
-			if candles[i].Low < candles[i-1].Low && 
-			   candles[i].Low < candles[i-2].Low &&
-			   candles[i].Low < candles[i+1].Low &&
-			   candles[i].Low < candles[i+2].Low {
-				swings = append(swings, model.SRLevel{
-					Price:    candles[i].Low,
-					Strength: 0.3,
-				})
-			}
+	for _, swing := range ConfirmedSwings(swings) {
+		if swing.Type != swingType || swing.Index < startIdx {
+			continue
 		}
+		levels = append(levels, model.SRLevel{
+			Price:    swing.Price,
+			Strength: 0.3, // Lower strength for swing points
+			Source:   "SWING",
+		})
 	}
 
-	// Sort by strength
-	sort.Slice(swings, func(i, j int) bool {
-		return swings[i].Strength > swings[j].Strength
+	return levels
+}
+
+// MergeVolumeProfileLevels feeds volume profile nodes into the SR levels.
+// Existing levels near the POC or an HVN are strengthened; nodes with no matching level are added
+// as zones scored over the candles.
+func MergeVolumeProfileLevels(levels model.SRLevels, profile *model.VolumeProfile, candles []model.Candle, interval string) model.SRLevels {
+	if profile == nil || len(candles) == 0 {
+		return levels
+	}
+	currentPrice := candles[len(candles)-1].Close
+	atr := calculateATR(candles, 14)
+
+	threshold := clusterThreshold(getTimeframeConfig(interval, 0), currentPrice, atr)
+	bufferZone := currentPrice * 0.002
+
+	nodes := []model.SRLevel{{Price: profile.POC, Strength: 0.8, Source: "VOLUME_POC"}}
+	for _, price := range profile.HighVolumeNodes {
+		nodes = append(nodes, model.SRLevel{Price: price, Strength: 0.6, Source: "VOLUME_HVN"})
+	}
+
+	resistance := append([]model.SRLevel{}, levels.Resistance...)
+	support := append([]model.SRLevel{}, levels.Support...)
+
+	for _, node := range nodes {
+		if node.Price > currentPrice+bufferZone {
+			resistance = mergeVolumeNode(resistance, node, threshold, candles, atr)
+		} else if node.Price < currentPrice-bufferZone {
+			support = mergeVolumeNode(support, node, threshold, candles, atr)
+		}
+	}
+
+	sort.Slice(resistance, func(i, j int) bool {
+		return resistance[i].Price < resistance[j].Price
+	})
+	sort.Slice(support, func(i, j int) bool {
+		return support[i].Price > support[j].Price
 	})
 
-	return swings
+	if len(resistance) > 5 {
+		resistance = resistance[:5]
+	}
+	if len(support) > 5 {
+		support = support[:5]
+	}
+
+	return model.SRLevels{
+		Resistance: resistance,
+		Support:    support,
+	}
+}
+
+// mergeVolumeNode boosts the nearest level within threshold or appends the node as a new zone
+func mergeVolumeNode(levels []model.SRLevel, node model.SRLevel, threshold float64, candles []model.Candle, atr float64) []model.SRLevel {
+	nearest := -1
+	for i, level := range levels {
+		distance := math.Abs(level.Price - node.Price)
+		if distance <= threshold && (nearest < 0 || distance < math.Abs(levels[nearest].Price-node.Price)) {
+			nearest = i
+		}
+	}
+
+	if nearest >= 0 {
+		// Volume confirms the level
+		levels[nearest].Strength = math.Min(1.0, levels[nearest].Strength+node.Strength*0.25)
+		return levels
+	}
+
+	zone := NewSRZone(node, atr)
+	UpdateSRZone(&zone, candles)
+	ScoreSRZone(&zone, candles)
+	// The node's own volume weight still counts
+	zone.Strength = math.Min(1.0, zone.Strength+node.Strength*0.25)
+	return append(levels, zone.SRLevel)
 }
 
 // calculateATR calculates Average True Range
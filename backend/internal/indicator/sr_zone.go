@@ -0,0 +1,213 @@
+package indicator
+
+import (
+	"math"
+	"sort"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// SR zone roles
+const (
+	SRRoleSupport    = "SUPPORT"
+	SRRoleResistance = "RESISTANCE"
+)
+
+// srZoneMinHalfWidthATR is the narrowest a zone may be on each side of its price, in ATR
+const srZoneMinHalfWidthATR = 0.25
+
+// SRZoneHalfLife is the number of bars without a touch after which a zone's recency weight halves
+const SRZoneHalfLife = 50
+
+// srZoneMaxBreaks invalidates a zone: the first break flips its role, the next one breaks the flipped role too
+const srZoneMaxBreaks = 2
+
+// NewSRZone turns a level into a zone. Bounds already set on the level are kept and widened to at
+// least ±0.25 ATR around its price.
+func NewSRZone(level model.SRLevel, atr float64) model.SRZone {
+	halfWidth := atr * srZoneMinHalfWidthATR
+	if level.Lower == 0 || level.Lower > level.Price-halfWidth {
+		level.Lower = level.Price - halfWidth
+	}
+	if level.Upper == 0 || level.Upper < level.Price+halfWidth {
+		level.Upper = level.Price + halfWidth
+	}
+
+	// Reset history; it is rebuilt by replaying candles
+	level.Touches, level.FirstTouch, level.LastTouch = 0, 0, 0
+	level.OriginRole, level.Breaks, level.BrokenAt, level.Flipped = "", 0, 0, false
+
+	return model.SRZone{SRLevel: level}
+}
+
+// UpdateSRZone replays the candles after zone.Through into the zone. A touch is a run of consecutive
+// candles overlapping the zone; a break is a close beyond the zone on the opposite side from the
+// previous close outside it. The origin role is the side price approached from on the first touch.
+func UpdateSRZone(zone *model.SRZone, candles []model.Candle) {
+	for _, c := range candles {
+		if c.Timestamp <= zone.Through {
+			continue
+		}
+
+		if c.Low <= zone.Upper && c.High >= zone.Lower {
+			if !zone.InZone {
+				zone.Touches++
+				if zone.FirstTouch == 0 {
+					zone.FirstTouch = c.Timestamp
+					zone.OriginRole = roleFromSide(zone.Side)
+				}
+			}
+			zone.InZone = true
+			zone.LastTouch = c.Timestamp
+			zone.TouchVolume += c.Volume
+			zone.TouchBars++
+		} else {
+			zone.InZone = false
+		}
+
+		side := 0
+		if c.Close > zone.Upper {
+			side = 1
+		} else if c.Close < zone.Lower {
+			side = -1
+		}
+		if side != 0 {
+			if zone.Side == -side {
+				zone.Breaks++
+				zone.BrokenAt = c.Timestamp
+			}
+			// First touched from inside the window: the first exit shows which side held
+			if zone.OriginRole == "" && zone.FirstTouch != 0 {
+				zone.OriginRole = roleFromSide(side)
+			}
+			zone.Side = side
+		}
+
+		zone.Through = c.Timestamp
+	}
+}
+
+// ScoreSRZone sets the zone's current role, age, recency, volume score and strength as of the last candle.
+// Volume is ranked against the candles' own volume distribution rather than a fixed scale, and the
+// strength blends touches, volume and a recency decay with a bonus for a confirmed role flip.
+func ScoreSRZone(zone *model.SRZone, candles []model.Candle) {
+	n := len(candles)
+	if n == 0 {
+		return
+	}
+	last := candles[n-1]
+
+	switch {
+	case last.Close > zone.Upper:
+		zone.Role = SRRoleSupport
+	case last.Close < zone.Lower:
+		zone.Role = SRRoleResistance
+	case zone.Price <= last.Close:
+		zone.Role = SRRoleSupport
+	default:
+		zone.Role = SRRoleResistance
+	}
+	zone.Flipped = zone.Breaks > 0 && zone.OriginRole != "" && zone.OriginRole != zone.Role
+
+	barDuration := int64(1)
+	if n >= 2 && last.Timestamp > candles[n-2].Timestamp {
+		barDuration = last.Timestamp - candles[n-2].Timestamp
+	}
+	zone.AgeBars, zone.BarsSinceTouch = 0, 0
+	if zone.FirstTouch > 0 {
+		zone.AgeBars = int((last.Timestamp - zone.FirstTouch) / barDuration)
+		zone.BarsSinceTouch = int((last.Timestamp - zone.LastTouch) / barDuration)
+	}
+
+	zone.VolumeScore = 0
+	if zone.TouchBars > 0 {
+		volumes := make([]float64, n)
+		for i, c := range candles {
+			volumes[i] = c.Volume
+		}
+		zone.VolumeScore = percentileRank(volumes, zone.TouchVolume/float64(zone.TouchBars)) / 100
+	}
+
+	touchScore := math.Min(1, float64(zone.Touches)/4)
+	recency := 0.0
+	if zone.Touches > 0 {
+		recency = math.Pow(0.5, float64(zone.BarsSinceTouch)/SRZoneHalfLife)
+	}
+	strength := touchScore*0.35 + zone.VolumeScore*0.25 + recency*0.4
+	if zone.Flipped {
+		strength += 0.1
+	}
+	zone.Strength = math.Min(1, strength)
+}
+
+// OverlapsSRZone reports whether two zones share any price
+func OverlapsSRZone(a, b model.SRLevel) bool {
+	return a.Lower <= b.Upper && b.Lower <= a.Upper
+}
+
+// IsSRZoneBroken reports whether price has broken through the zone in both directions, so neither role held
+func IsSRZoneBroken(zone model.SRZone) bool {
+	return zone.Breaks >= srZoneMaxBreaks
+}
+
+// MergeSRZones collapses overlapping zones. Each overlapping group keeps the zone with the most touches
+// (the earliest first touch on ties), widened to the group's bounds and spanning its earliest and latest
+// touches. It returns the kept zones, lowest first, and the zones merged away.
+func MergeSRZones(zones []model.SRZone) (kept, merged []model.SRZone) {
+	sorted := append([]model.SRZone(nil), zones...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Lower < sorted[j].Lower
+	})
+
+	for _, zone := range sorted {
+		if len(kept) == 0 || !OverlapsSRZone(kept[len(kept)-1].SRLevel, zone.SRLevel) {
+			kept = append(kept, zone)
+			continue
+		}
+
+		last := &kept[len(kept)-1]
+		survivor, absorbed := *last, zone
+		if zone.Touches > last.Touches || zone.Touches == last.Touches && earlierTouch(zone.FirstTouch, last.FirstTouch) {
+			survivor, absorbed = zone, *last
+		}
+		survivor.Lower = math.Min(survivor.Lower, absorbed.Lower)
+		survivor.Upper = math.Max(survivor.Upper, absorbed.Upper)
+		if earlierTouch(absorbed.FirstTouch, survivor.FirstTouch) {
+			survivor.FirstTouch = absorbed.FirstTouch
+		}
+		if absorbed.LastTouch > survivor.LastTouch {
+			survivor.LastTouch = absorbed.LastTouch
+		}
+
+		*last = survivor
+		merged = append(merged, absorbed)
+	}
+
+	return kept, merged
+}
+
+// earlierTouch reports whether touch a happened before b; 0 means never touched
+func earlierTouch(a, b int64) bool {
+	return a != 0 && (b == 0 || a < b)
+}
+
+// roleFromSide maps the side price sits on to the role the zone plays for it
+func roleFromSide(side int) string {
+	switch side {
+	case 1:
+		return SRRoleSupport
+	case -1:
+		return SRRoleResistance
+	}
+	return ""
+}
+
+// describeSRLevels replaces each level's strength with its zone score over the candles
+func describeSRLevels(levels []model.SRLevel, candles []model.Candle, atr float64) {
+	for i := range levels {
+		zone := NewSRZone(levels[i], atr)
+		UpdateSRZone(&zone, candles)
+		ScoreSRZone(&zone, candles)
+		levels[i] = zone.SRLevel
+	}
+}
@@ -0,0 +1,95 @@
+package indicator
+
+import (
+	"testing"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+func TestSRZoneTouchesAndFlip(t *testing.T) {
+	bar := func(i int, low, high, close float64) model.Candle {
+		return model.Candle{Timestamp: int64(i) * 60000, Open: close, High: high, Low: low, Close: close, Volume: float64(1000 + i)}
+	}
+	candles := []model.Candle{
+		bar(1, 90, 94, 92),      // Below the zone
+		bar(2, 95, 99.5, 96),    // Touch 1 from below
+		bar(3, 97, 100.2, 97),   // Still in the zone
+		bar(4, 93, 97, 94),      // Away
+		bar(5, 96, 100, 98),     // Touch 2
+		bar(6, 99, 105, 104),    // Break above, same visit as touch 2
+		bar(7, 102, 106, 105),   // Away above
+		bar(8, 100.5, 104, 103), // Retest from above: touch 3
+	}
+
+	zone := NewSRZone(model.SRLevel{Price: 100, Lower: 99, Upper: 101}, 1)
+	UpdateSRZone(&zone, candles[:5])
+	if zone.Touches != 2 || zone.FirstTouch != candles[1].Timestamp || zone.LastTouch != candles[4].Timestamp {
+		t.Fatalf("touches %d first %d last %d", zone.Touches, zone.FirstTouch, zone.LastTouch)
+	}
+	if zone.OriginRole != SRRoleResistance || zone.Breaks != 0 {
+		t.Fatalf("origin %q breaks %d", zone.OriginRole, zone.Breaks)
+	}
+
+	// Replaying again must not double count
+	UpdateSRZone(&zone, candles)
+	UpdateSRZone(&zone, candles)
+	if zone.Touches != 3 || zone.Breaks != 1 || zone.BrokenAt != candles[5].Timestamp {
+		t.Fatalf("touches %d breaks %d broken at %d", zone.Touches, zone.Breaks, zone.BrokenAt)
+	}
+
+	ScoreSRZone(&zone, candles)
+	if zone.Role != SRRoleSupport || !zone.Flipped {
+		t.Errorf("role %q flipped %v", zone.Role, zone.Flipped)
+	}
+	if zone.AgeBars != 6 || zone.BarsSinceTouch != 0 {
+		t.Errorf("age %d since touch %d", zone.AgeBars, zone.BarsSinceTouch)
+	}
+	if zone.Strength <= 0 || zone.Strength > 1 || zone.VolumeScore <= 0 || zone.VolumeScore > 1 {
+		t.Errorf("strength %v volume score %v", zone.Strength, zone.VolumeScore)
+	}
+}
+
+func TestMergeSRZones(t *testing.T) {
+	zone := func(id int64, lower, upper float64, touches int, first, last int64) model.SRZone {
+		return model.SRZone{ID: id, SRLevel: model.SRLevel{
+			Price: (lower + upper) / 2, Lower: lower, Upper: upper, Touches: touches, FirstTouch: first, LastTouch: last,
+		}}
+	}
+	zones := []model.SRZone{
+		zone(1, 110, 112, 1, 500, 600),
+		zone(2, 99, 101, 3, 200, 300),
+		zone(3, 100.5, 102, 1, 100, 400), // Overlaps 2, fewer touches
+		zone(4, 101.8, 103, 1, 0, 0),     // Overlaps 3, so it joins the same group
+		zone(5, 120, 121, 2, 50, 60),
+	}
+
+	kept, merged := MergeSRZones(zones)
+	if len(kept) != 3 || len(merged) != 2 {
+		t.Fatalf("kept %d merged %d, want 3 and 2", len(kept), len(merged))
+	}
+
+	got := kept[0]
+	if got.ID != 2 || got.Lower != 99 || got.Upper != 103 || got.FirstTouch != 100 || got.LastTouch != 400 || got.Touches != 3 {
+		t.Errorf("merged zone = %+v, want zone 2 widened to 99-103, touched 100-400", got)
+	}
+	if kept[1].ID != 1 || kept[2].ID != 5 {
+		t.Errorf("kept %d and %d, want the untouched zones 1 and 5", kept[1].ID, kept[2].ID)
+	}
+	if merged[0].ID != 3 || merged[1].ID != 4 {
+		t.Errorf("merged away %d and %d, want 3 and 4", merged[0].ID, merged[1].ID)
+	}
+
+	// Ties on touches keep the earliest touched zone
+	kept, _ = MergeSRZones([]model.SRZone{zone(1, 100, 102, 2, 300, 300), zone(2, 101, 103, 2, 200, 200)})
+	if len(kept) != 1 || kept[0].ID != 2 {
+		t.Errorf("tie kept %+v, want zone 2", kept)
+	}
+}
+
+func TestIsSRZoneBroken(t *testing.T) {
+	for breaks, want := range map[int]bool{0: false, 1: false, 2: true} {
+		if got := IsSRZoneBroken(model.SRZone{SRLevel: model.SRLevel{Breaks: breaks}}); got != want {
+			t.Errorf("%d breaks: broken = %v, want %v", breaks, got, want)
+		}
+	}
+}
@@ -0,0 +1,434 @@
+package indicator
+
+import (
+	"math"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// Streaming indicators update in O(1) per new candle and produce the same values as
+// their batch counterparts. All state is held in exported fields so an indicator can be
+// snapshotted (copied or JSON-encoded) and restored later.
+
+// StreamingEMA is an incremental EMA seeded with the SMA of the first Period prices
+type StreamingEMA struct {
+	Period  int     `json:"period"`
+	Count   int     `json:"count"`
+	SeedSum float64 `json:"seed_sum"`
+	Current float64 `json:"current"`
+}
+
+// NewStreamingEMA creates an incremental EMA
+func NewStreamingEMA(period int) *StreamingEMA {
+	return &StreamingEMA{Period: period}
+}
+
+// Update adds a new price and returns the current EMA (0 until Period prices are seen)
+func (e *StreamingEMA) Update(price float64) float64 {
+	e.Count++
+	if e.Count < e.Period {
+		e.SeedSum += price
+		return 0
+	}
+	if e.Count == e.Period {
+		e.SeedSum += price
+		e.Current = e.SeedSum / float64(e.Period)
+		return e.Current
+	}
+
+	multiplier := 2.0 / float64(e.Period+1)
+	e.Current = (price-e.Current)*multiplier + e.Current
+	return e.Current
+}
+
+// Ready reports whether the EMA has enough data
+func (e *StreamingEMA) Ready() bool {
+	return e.Count >= e.Period
+}
+
+// Value returns the current EMA value
+func (e *StreamingEMA) Value() float64 {
+	if !e.Ready() {
+		return 0
+	}
+	return e.Current
+}
+
+// StreamingATR is an incremental Wilder ATR matching CalculateATR
+type StreamingATR struct {
+	Period    int     `json:"period"`
+	Count     int     `json:"count"`
+	PrevClose float64 `json:"prev_close"`
+	SeedSum   float64 `json:"seed_sum"`
+	Current   float64 `json:"current"`
+}
+
+// NewStreamingATR creates an incremental ATR
+func NewStreamingATR(period int) *StreamingATR {
+	return &StreamingATR{Period: period}
+}
+
+// Update adds a new candle and returns the current ATR (0 until Period candles are seen)
+func (a *StreamingATR) Update(candle model.Candle) float64 {
+	tr := candle.High - candle.Low
+	if a.Count > 0 {
+		tr = math.Max(tr, math.Max(math.Abs(candle.High-a.PrevClose), math.Abs(candle.Low-a.PrevClose)))
+	}
+	a.PrevClose = candle.Close
+	a.Count++
+
+	if a.Count < a.Period {
+		a.SeedSum += tr
+		return 0
+	}
+	if a.Count == a.Period {
+		a.SeedSum += tr
+		a.Current = a.SeedSum / float64(a.Period)
+		return a.Current
+	}
+
+	a.Current = (a.Current*float64(a.Period-1) + tr) / float64(a.Period)
+	return a.Current
+}
+
+// Ready reports whether the ATR has enough data
+func (a *StreamingATR) Ready() bool {
+	return a.Count >= a.Period
+}
+
+// Value returns the current ATR value
+func (a *StreamingATR) Value() float64 {
+	if !a.Ready() {
+		return 0
+	}
+	return a.Current
+}
+
+// StreamingRSI is an incremental Wilder RSI matching talib.Rsi
+type StreamingRSI struct {
+	Period    int     `json:"period"`
+	Count     int     `json:"count"`
+	PrevClose float64 `json:"prev_close"`
+	AvgGain   float64 `json:"avg_gain"`
+	AvgLoss   float64 `json:"avg_loss"`
+	Current   float64 `json:"current"`
+}
+
+// NewStreamingRSI creates an incremental RSI
+func NewStreamingRSI(period int) *StreamingRSI {
+	return &StreamingRSI{Period: period}
+}
+
+// Update adds a new close and returns the current RSI (0 until Period+1 closes are seen)
+func (r *StreamingRSI) Update(price float64) float64 {
+	r.Count++
+	if r.Count == 1 {
+		r.PrevClose = price
+		return 0
+	}
+
+	change := price - r.PrevClose
+	r.PrevClose = price
+	gain, loss := 0.0, 0.0
+	if change < 0 {
+		loss = -change
+	} else {
+		gain = change
+	}
+
+	period := float64(r.Period)
+	if r.Count <= r.Period {
+		// Accumulate the seed averages
+		r.AvgGain += gain
+		r.AvgLoss += loss
+		return 0
+	}
+	if r.Count == r.Period+1 {
+		r.AvgGain = (r.AvgGain + gain) / period
+		r.AvgLoss = (r.AvgLoss + loss) / period
+	} else {
+		r.AvgGain = (r.AvgGain*(period-1) + gain) / period
+		r.AvgLoss = (r.AvgLoss*(period-1) + loss) / period
+	}
+
+	total := r.AvgGain + r.AvgLoss
+	if math.Abs(total) > 1e-14 {
+		r.Current = 100 * r.AvgGain / total
+	} else {
+		r.Current = 0
+	}
+	return r.Current
+}
+
+// Ready reports whether the RSI has enough data
+func (r *StreamingRSI) Ready() bool {
+	return r.Count > r.Period
+}
+
+// Value returns the current RSI value
+func (r *StreamingRSI) Value() float64 {
+	if !r.Ready() {
+		return 0
+	}
+	return r.Current
+}
+
+// StreamingMACD is an incremental MACD (12, 26, 9) matching CalculateMACD.
+// Like TA-Lib, the DIF series is zero until the slow EMA and signal lookback are
+// filled, and DEA is an EMA over that series including the leading zeros.
+type StreamingMACD struct {
+	Count  int           `json:"count"`
+	Fast   *StreamingEMA `json:"fast"`
+	Slow   *StreamingEMA `json:"slow"`
+	Signal *StreamingEMA `json:"signal"`
+	DIF    float64       `json:"dif"`
+	DEA    float64       `json:"dea"`
+	Hist   float64       `json:"histogram"`
+}
+
+// NewStreamingMACD creates an incremental MACD with the standard 12/26/9 periods
+func NewStreamingMACD() *StreamingMACD {
+	return &StreamingMACD{
+		Fast:   NewStreamingEMA(12),
+		Slow:   NewStreamingEMA(26),
+		Signal: NewStreamingEMA(9),
+	}
+}
+
+// Update adds a new close and returns the current MACD values
+func (m *StreamingMACD) Update(price float64) model.MACDIndicator {
+	idx := m.Count
+	m.Count++
+
+	fast := m.Fast.Update(price)
+	slow := m.Slow.Update(price)
+
+	lookbackTotal := (m.Signal.Period - 1) + (m.Slow.Period - 1)
+	dif := 0.0
+	if idx >= lookbackTotal-1 {
+		dif = fast - slow
+	}
+	dea := m.Signal.Update(dif)
+
+	m.DIF = dif
+	m.DEA = dea
+	m.Hist = 0
+	if idx >= lookbackTotal {
+		m.Hist = dif - dea
+	}
+
+	return m.Value()
+}
+
+// Ready reports whether the MACD has enough data
+func (m *StreamingMACD) Ready() bool {
+	return m.Count >= 26
+}
+
+// Value returns the current MACD values
+func (m *StreamingMACD) Value() model.MACDIndicator {
+	if !m.Ready() {
+		return model.MACDIndicator{}
+	}
+	return model.MACDIndicator{
+		DIF:       m.DIF,
+		DEA:       m.DEA,
+		Histogram: m.Hist,
+	}
+}
+
+// Snapshot returns a deep copy of the MACD state
+func (m *StreamingMACD) Snapshot() *StreamingMACD {
+	fast, slow, signal := *m.Fast, *m.Slow, *m.Signal
+	snapshot := *m
+	snapshot.Fast, snapshot.Slow, snapshot.Signal = &fast, &slow, &signal
+	return &snapshot
+}
+
+// StreamingKDJ is an incremental KDJ matching CalculateKDJWithHistory
+// RSV over Period candles, K = SMA(RSV, SmoothK), D = SMA(K, SmoothD)
+type StreamingKDJ struct {
+	Period  int       `json:"period"`
+	SmoothK int       `json:"smooth_k"`
+	SmoothD int       `json:"smooth_d"`
+	Count   int       `json:"count"`
+	Highs   []float64 `json:"highs"`    // Ring buffer of the last Period highs
+	Lows    []float64 `json:"lows"`     // Ring buffer of the last Period lows
+	RSVs    []float64 `json:"rsvs"`     // Ring buffer of the last SmoothK RSV values
+	Ks      []float64 `json:"ks"`       // Ring buffer of the last SmoothD K values
+	RSVSeen int       `json:"rsv_seen"` // RSV values produced so far
+	KSeen   int       `json:"k_seen"`   // K values produced so far
+	K       float64   `json:"k"`
+	D       float64   `json:"d"`
+}
+
+// NewStreamingKDJ creates an incremental KDJ with the standard 9/3/3 periods
+func NewStreamingKDJ() *StreamingKDJ {
+	return &StreamingKDJ{
+		Period:  9,
+		SmoothK: 3,
+		SmoothD: 3,
+		Highs:   make([]float64, 9),
+		Lows:    make([]float64, 9),
+		RSVs:    make([]float64, 3),
+		Ks:      make([]float64, 3),
+	}
+}
+
+// Update adds a new candle and returns the current KDJ values
+func (k *StreamingKDJ) Update(candle model.Candle) model.KDJIndicator {
+	k.Highs[k.Count%k.Period] = candle.High
+	k.Lows[k.Count%k.Period] = candle.Low
+	k.Count++
+
+	if k.Count < k.Period {
+		return model.KDJIndicator{}
+	}
+
+	// Window extremes over the ring buffer: O(Period) with a fixed period is O(1) per update
+	high, low := -math.MaxFloat64, math.MaxFloat64
+	for i := 0; i < k.Period; i++ {
+		high = math.Max(high, k.Highs[i])
+		low = math.Min(low, k.Lows[i])
+	}
+
+	rsv := 0.0
+	if high != low {
+		rsv = (candle.Close - low) / (high - low) * 100
+	}
+
+	// K = rolling SMA of RSV. The smoothing windows are a few values, so they are summed afresh
+	// rather than kept as running sums that drift over long streams.
+	k.RSVs[k.RSVSeen%k.SmoothK] = rsv
+	k.RSVSeen++
+	if k.RSVSeen < k.SmoothK {
+		return model.KDJIndicator{}
+	}
+	k.K = average(k.RSVs)
+
+	// D = rolling SMA of K
+	k.Ks[k.KSeen%k.SmoothD] = k.K
+	k.KSeen++
+	if k.KSeen < k.SmoothD {
+		return model.KDJIndicator{}
+	}
+	k.D = average(k.Ks)
+
+	return k.Value()
+}
+
+// Ready reports whether K and D are both available
+func (k *StreamingKDJ) Ready() bool {
+	return k.KSeen >= k.SmoothD
+}
+
+// Value returns the current KDJ values
+func (k *StreamingKDJ) Value() model.KDJIndicator {
+	if !k.Ready() {
+		return model.KDJIndicator{}
+	}
+	return model.KDJIndicator{
+		K: k.K,
+		D: k.D,
+		J: 3*k.K - 2*k.D,
+	}
+}
+
+// Snapshot returns a deep copy of the KDJ state
+func (k *StreamingKDJ) Snapshot() *StreamingKDJ {
+	snapshot := *k
+	snapshot.Highs = append([]float64(nil), k.Highs...)
+	snapshot.Lows = append([]float64(nil), k.Lows...)
+	snapshot.RSVs = append([]float64(nil), k.RSVs...)
+	snapshot.Ks = append([]float64(nil), k.Ks...)
+	return &snapshot
+}
+
+// StreamingVolumeProfile keeps the candles of the profile window. Update is amortised O(1); Value
+// bins the window on demand, since the bins follow the window's high and low.
+type StreamingVolumeProfile struct {
+	Config  VolumeProfileConfig `json:"config"`
+	Window  int                 `json:"window"` // Candles a "visible" profile covers
+	Candles []model.Candle      `json:"candles"`
+}
+
+// NewStreamingVolumeProfile creates an incremental volume profile; window bounds the visible range
+func NewStreamingVolumeProfile(config VolumeProfileConfig, window int) *StreamingVolumeProfile {
+	return &StreamingVolumeProfile{Config: config, Window: window}
+}
+
+// Update adds a closed candle and drops the candles that left the window
+func (v *StreamingVolumeProfile) Update(candle model.Candle) {
+	v.Candles = append(v.Candles, candle)
+
+	keep := v.Window
+	switch v.Config.Mode {
+	case "fixed":
+		keep = v.Config.Lookback
+	case "session":
+		// Keep the candles since 00:00 UTC of the new candle's day
+		sessionStart := PivotPeriodStart(candle.Timestamp, "1d")
+		drop := 0
+		for drop < len(v.Candles) && v.Candles[drop].Timestamp < sessionStart {
+			drop++
+		}
+		v.Candles = v.Candles[drop:]
+	}
+	if keep > 0 && len(v.Candles) > keep {
+		v.Candles = v.Candles[len(v.Candles)-keep:]
+	}
+}
+
+// Value returns the profile over the window, nil when it cannot be built
+func (v *StreamingVolumeProfile) Value() *model.VolumeProfile {
+	return CalculateVolumeProfile(v.Candles, v.Config)
+}
+
+// Snapshot returns a deep copy of the volume profile state
+func (v *StreamingVolumeProfile) Snapshot() *StreamingVolumeProfile {
+	snapshot := *v
+	snapshot.Candles = append([]model.Candle(nil), v.Candles...)
+	return &snapshot
+}
+
+// StreamingPivots aggregates the interval's candles into the pivot timeframe candle and publishes the
+// pivots of the last completed one, matching CalculatePivotPoints on the prior higher-timeframe candle
+type StreamingPivots struct {
+	Timeframe string       `json:"timeframe"`
+	Count     int          `json:"count"`
+	Current   model.Candle `json:"current"`  // Higher-timeframe candle being built
+	Complete  bool         `json:"complete"` // Whether Current has been seen from its period start
+	// Pivots of the last completed candle; never mutated, so snapshots share it
+	Last *model.PivotPoints `json:"last"`
+}
+
+// NewStreamingPivots creates incremental pivots on the given higher timeframe (see PivotTimeframe)
+func NewStreamingPivots(timeframe string) *StreamingPivots {
+	return &StreamingPivots{Timeframe: timeframe}
+}
+
+// Update adds a closed candle of the lower interval
+func (p *StreamingPivots) Update(candle model.Candle) {
+	start := PivotPeriodStart(candle.Timestamp, p.Timeframe)
+	p.Count++
+	if p.Count > 1 && start == p.Current.Timestamp {
+		p.Current.High = math.Max(p.Current.High, candle.High)
+		p.Current.Low = math.Min(p.Current.Low, candle.Low)
+		p.Current.Close = candle.Close
+		p.Current.Volume += candle.Volume
+		return
+	}
+
+	// A new period closes the previous one; a period joined midway has an incomplete range
+	if p.Count > 1 && p.Complete {
+		p.Last = CalculatePivotPoints(p.Current, p.Timeframe)
+	}
+	p.Current = candle
+	p.Current.Timestamp = start
+	p.Complete = candle.Timestamp == start
+}
+
+// Value returns the pivots of the last completed higher-timeframe candle, nil before one completes
+func (p *StreamingPivots) Value() *model.PivotPoints {
+	return p.Last
+}
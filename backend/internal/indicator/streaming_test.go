@@ -0,0 +1,312 @@
+package indicator
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/markcheno/go-talib"
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+const streamingTolerance = 1e-9
+
+var testEngineConfig = EngineConfig{Interval: "1h", VolumeProfile: DefaultVolumeProfileConfig(), Window: 100}
+
+// randomWalkCandles generates deterministic synthetic candles
+func randomWalkCandles(n int, seed int64) []model.Candle {
+	rng := rand.New(rand.NewSource(seed))
+	candles := make([]model.Candle, n)
+	price := 2000.0
+
+	for i := 0; i < n; i++ {
+		open := price
+		close := open * (1 + (rng.Float64()-0.5)*0.04)
+		high := math.Max(open, close) * (1 + rng.Float64()*0.01)
+		low := math.Min(open, close) * (1 - rng.Float64()*0.01)
+		candles[i] = model.Candle{
+			Timestamp: int64(i) * 3600000,
+			Open:      open,
+			High:      high,
+			Low:       low,
+			Close:     close,
+			Volume:    1000 + rng.Float64()*500,
+		}
+		price = close
+	}
+
+	return candles
+}
+
+func closesOf(candles []model.Candle) []float64 {
+	closes := make([]float64, len(candles))
+	for i, c := range candles {
+		closes[i] = c.Close
+	}
+	return closes
+}
+
+func assertClose(t *testing.T, name string, idx int, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > streamingTolerance*math.Max(1, math.Abs(want)) {
+		t.Fatalf("%s at %d: got %.10f, want %.10f", name, idx, got, want)
+	}
+}
+
+// referenceKDJ is the original O(n*period) KDJ implementation
+func referenceKDJ(candles []model.Candle) model.KDJIndicator {
+	rsvValues := make([]float64, 0)
+	for i := 8; i < len(candles); i++ {
+		low9, high9 := math.MaxFloat64, -math.MaxFloat64
+		for j := i - 8; j <= i; j++ {
+			low9 = math.Min(low9, candles[j].Low)
+			high9 = math.Max(high9, candles[j].High)
+		}
+		rsv := 0.0
+		if high9 != low9 {
+			rsv = (candles[i].Close - low9) / (high9 - low9) * 100
+		}
+		rsvValues = append(rsvValues, rsv)
+	}
+
+	sma := func(data []float64, period int) []float64 {
+		result := make([]float64, 0)
+		for i := period - 1; i < len(data); i++ {
+			sum := 0.0
+			for j := i - period + 1; j <= i; j++ {
+				sum += data[j]
+			}
+			result = append(result, sum/float64(period))
+		}
+		return result
+	}
+
+	kValues := sma(rsvValues, 3)
+	dValues := sma(kValues, 3)
+	k := kValues[len(kValues)-1]
+	d := dValues[len(dValues)-1]
+	return model.KDJIndicator{K: k, D: d, J: 3*k - 2*d}
+}
+
+func TestStreamingEMAMatchesBatch(t *testing.T) {
+	candles := randomWalkCandles(400, 1)
+	closes := closesOf(candles)
+
+	for _, period := range []int{9, 21, 50, 200} {
+		batch := CalculateEMA(closes, period)
+		stream := NewStreamingEMA(period)
+		for i, price := range closes {
+			got := stream.Update(price)
+			if i >= period-1 {
+				assertClose(t, "EMA", i, got, batch.Values[i])
+			}
+		}
+	}
+}
+
+func TestStreamingATRMatchesBatch(t *testing.T) {
+	candles := randomWalkCandles(300, 2)
+	batch := CalculateATR(candles, 14)
+	stream := NewStreamingATR(14)
+
+	for i, candle := range candles {
+		got := stream.Update(candle)
+		if i >= 13 {
+			assertClose(t, "ATR", i, got, batch.Values[i])
+		}
+	}
+}
+
+func TestStreamingRSIMatchesBatch(t *testing.T) {
+	candles := randomWalkCandles(300, 3)
+	closes := closesOf(candles)
+
+	for _, period := range []int{6, 14} {
+		batch := talib.Rsi(closes, period)
+		stream := NewStreamingRSI(period)
+		for i, price := range closes {
+			got := stream.Update(price)
+			if i >= period {
+				assertClose(t, "RSI", i, got, batch[i])
+			}
+		}
+	}
+}
+
+func TestStreamingMACDMatchesBatch(t *testing.T) {
+	candles := randomWalkCandles(200, 4)
+	stream := NewStreamingMACD()
+
+	for i, candle := range candles {
+		got := stream.Update(candle.Close)
+		if i < 25 {
+			continue
+		}
+		want := CalculateMACD(candles[:i+1])
+		assertClose(t, "MACD DIF", i, got.DIF, want.DIF)
+		assertClose(t, "MACD DEA", i, got.DEA, want.DEA)
+		assertClose(t, "MACD Histogram", i, got.Histogram, want.Histogram)
+	}
+}
+
+func TestStreamingKDJMatchesReference(t *testing.T) {
+	candles := randomWalkCandles(200, 5)
+	stream := NewStreamingKDJ()
+
+	for i, candle := range candles {
+		got := stream.Update(candle)
+		if i < 12 {
+			continue
+		}
+		want := referenceKDJ(candles[:i+1])
+		assertClose(t, "KDJ K", i, got.K, want.K)
+		assertClose(t, "KDJ D", i, got.D, want.D)
+		assertClose(t, "KDJ J", i, got.J, want.J)
+	}
+
+	batch := CalculateKDJWithHistory(candles)
+	want := referenceKDJ(candles)
+	assertClose(t, "KDJ batch K", len(candles)-1, batch.K, want.K)
+	assertClose(t, "KDJ batch D", len(candles)-1, batch.D, want.D)
+}
+
+func TestIndicatorEngineSnapshotRestore(t *testing.T) {
+	candles := randomWalkCandles(300, 6)
+	engine := NewIndicatorEngineFromCandles(testEngineConfig, candles[:250])
+
+	// Snapshot survives a JSON round trip
+	data, err := json.Marshal(engine.Snapshot())
+	if err != nil {
+		t.Fatalf("marshal snapshot: %v", err)
+	}
+	var restored IndicatorEngine
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("unmarshal snapshot: %v", err)
+	}
+
+	// Advancing the original must not affect the snapshot
+	snapshot := engine.Snapshot()
+	for _, candle := range candles[250:] {
+		engine.Update(candle)
+		restored.Update(candle)
+	}
+
+	full := NewIndicatorEngineFromCandles(testEngineConfig, candles)
+	if !reflect.DeepEqual(engine.Indicators(), full.Indicators()) {
+		t.Fatalf("engine diverged from full replay: %+v vs %+v", engine.Indicators(), full.Indicators())
+	}
+	if !reflect.DeepEqual(restored.Indicators(), full.Indicators()) {
+		t.Fatalf("restored engine diverged: %+v vs %+v", restored.Indicators(), full.Indicators())
+	}
+
+	engine.Restore(snapshot)
+	if engine.Count != 250 || !reflect.DeepEqual(engine.Indicators(), NewIndicatorEngineFromCandles(testEngineConfig, candles[:250]).Indicators()) {
+		t.Fatalf("restore did not rewind engine state")
+	}
+}
+
+func TestIndicatorEnginePreviewDoesNotMutate(t *testing.T) {
+	candles := randomWalkCandles(100, 7)
+	engine := NewIndicatorEngineFromCandles(testEngineConfig, candles[:99])
+	before := engine.Indicators()
+
+	preview := engine.Preview(candles[99])
+	if !reflect.DeepEqual(engine.Indicators(), before) || engine.Count != 99 {
+		t.Fatalf("preview mutated engine state")
+	}
+
+	engine.Update(candles[99])
+	if !reflect.DeepEqual(engine.Indicators(), preview) {
+		t.Fatalf("preview %+v does not match update %+v", preview, engine.Indicators())
+	}
+
+	// Stale candles are ignored
+	if engine.Update(candles[50]) {
+		t.Fatalf("expected stale candle to be rejected")
+	}
+}
+
+func TestIndicatorEngineMatchesBatchIndicators(t *testing.T) {
+	candles := randomWalkCandles(500, 8)
+	engine := NewIndicatorEngineFromCandles(testEngineConfig, candles)
+	got := engine.Indicators()
+	closes := closesOf(candles)
+
+	assertClose(t, "EMA200", len(candles)-1, got.EMA.EMA200, CalculateEMA(closes, 200).GetCurrentEMA())
+	assertClose(t, "ATR", len(candles)-1, got.ATR.Value, CalculateATR(candles, 14).GetCurrentATR())
+
+	rsi := CalculateRSI(candles)
+	assertClose(t, "RSI6", len(candles)-1, got.RSI.RSI6, rsi.RSI6)
+	assertClose(t, "RSI14", len(candles)-1, got.RSI.RSI14, rsi.RSI14)
+
+	macd := CalculateMACD(candles)
+	assertClose(t, "MACD DIF", len(candles)-1, got.MACD.DIF, macd.DIF)
+	assertClose(t, "MACD DEA", len(candles)-1, got.MACD.DEA, macd.DEA)
+}
+
+func TestStreamingKDJLongStream(t *testing.T) {
+	candles := randomWalkCandles(200000, 9)
+	stream := NewStreamingKDJ()
+	for _, candle := range candles {
+		stream.Update(candle)
+	}
+
+	// KDJ only depends on the last 13 candles, so the tail recomputed from scratch must agree exactly
+	want := referenceKDJ(candles[len(candles)-20:])
+	got := stream.Value()
+	assertClose(t, "KDJ K", len(candles)-1, got.K, want.K)
+	assertClose(t, "KDJ D", len(candles)-1, got.D, want.D)
+}
+
+func TestStreamingVolumeProfileMatchesBatch(t *testing.T) {
+	candles := randomWalkCandles(300, 10)
+
+	for _, mode := range []string{"visible", "fixed", "session"} {
+		config := DefaultVolumeProfileConfig()
+		config.Mode = mode
+		config.Lookback = 40
+		stream := NewStreamingVolumeProfile(config, 100)
+		for _, candle := range candles {
+			stream.Update(candle)
+		}
+
+		// The batch profile of the last 100 candles covers the same visible, fixed and session windows
+		want := CalculateVolumeProfile(candles[len(candles)-100:], config)
+		if got := stream.Value(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: streaming profile %+v, want %+v", mode, got, want)
+		}
+	}
+}
+
+func TestStreamingPivotsMatchBatch(t *testing.T) {
+	candles := randomWalkCandles(100, 11) // Hourly from 00:00 UTC: days 0-3 complete, day 4 forming
+
+	// The prior completed day is built from candles 72-95
+	day := candles[72]
+	for _, c := range candles[73:96] {
+		day.High = math.Max(day.High, c.High)
+		day.Low = math.Min(day.Low, c.Low)
+		day.Close = c.Close
+		day.Volume += c.Volume
+	}
+	want := CalculatePivotPoints(day, "1d")
+
+	stream := NewStreamingPivots("1d")
+	for _, candle := range candles {
+		stream.Update(candle)
+	}
+	if got := stream.Value(); !reflect.DeepEqual(got, want) {
+		t.Errorf("streaming pivots %+v, want %+v", got, want)
+	}
+
+	// A day joined midway has an incomplete range and yields no pivots
+	partial := NewStreamingPivots("1d")
+	for _, candle := range candles[5:30] {
+		partial.Update(candle)
+	}
+	if partial.Value() != nil {
+		t.Errorf("expected no pivots from a partial day, got %+v", partial.Value())
+	}
+}
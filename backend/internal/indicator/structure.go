@@ -0,0 +1,101 @@
+package indicator
+
+import (
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// LabelSwings labels each swing against the previous swing of the same type:
+// highs as HH/LH and lows as HL/LL. The first high and first low stay unlabeled.
+func LabelSwings(swings []model.SwingPoint) []model.SwingPoint {
+	labeled := make([]model.SwingPoint, len(swings))
+	copy(labeled, swings)
+
+	var lastHigh, lastLow *model.SwingPoint
+	for i := range labeled {
+		s := &labeled[i]
+		if s.Type == model.SwingHigh {
+			if lastHigh != nil {
+				s.Label = "LH"
+				if s.Price > lastHigh.Price {
+					s.Label = "HH"
+				}
+			}
+			lastHigh = s
+		} else {
+			if lastLow != nil {
+				s.Label = "LL"
+				if s.Price > lastLow.Price {
+					s.Label = "HL"
+				}
+			}
+			lastLow = s
+		}
+	}
+
+	return labeled
+}
+
+// DetectStructureEvents walks the candles and records every close beyond the latest swing high or low.
+// A break in the direction of the prevailing structure is a BOS (break of structure);
+// a break against it is a CHoCH (change of character). Every confirmed pivot counts, including highs
+// that a later higher high replaces in FindSwings, and becomes breakable on the bar that confirms it
+// (strength bars after a fractal, the reversal bar of a ZigZag), so the timeline has no lookahead.
+func DetectStructureEvents(candles []model.Candle, config SwingConfig) []model.StructureEvent {
+	events := make([]model.StructureEvent, 0)
+
+	pivots := findPivots(candles, config)
+	points := make([]model.SwingPoint, len(pivots))
+	for i, p := range pivots {
+		points[i] = p.SwingPoint
+	}
+	points = LabelSwings(points)
+
+	var activeHigh, activeLow *model.SwingPoint
+	bias := "" // "BULLISH", "BEARISH" or undecided
+	next := 0
+
+	for i := range candles {
+		// Pivots confirmed by this bar become the active levels
+		for next < len(pivots) && pivots[next].confirmedAt <= i {
+			s := points[next]
+			if s.Type == model.SwingHigh {
+				activeHigh = &s
+			} else {
+				activeLow = &s
+			}
+			next++
+		}
+
+		c := candles[i]
+		if activeHigh != nil && c.Close > activeHigh.Price {
+			events = append(events, newStructureEvent(bias, "BULLISH", *activeHigh, i, c))
+			bias = "BULLISH"
+			activeHigh = nil
+		}
+		if activeLow != nil && c.Close < activeLow.Price {
+			events = append(events, newStructureEvent(bias, "BEARISH", *activeLow, i, c))
+			bias = "BEARISH"
+			activeLow = nil
+		}
+	}
+
+	return events
+}
+
+func newStructureEvent(bias, direction string, swing model.SwingPoint, index int, c model.Candle) model.StructureEvent {
+	eventType := "BOS"
+	if bias != "" && bias != direction {
+		eventType = "CHOCH"
+	}
+	return model.StructureEvent{
+		Type:           eventType,
+		Direction:      direction,
+		Level:          swing.Price,
+		SwingLabel:     swing.Label,
+		SwingIndex:     swing.Index,
+		SwingTimestamp: swing.Timestamp,
+		BreakIndex:     index,
+		BreakTimestamp: c.Timestamp,
+		BreakClose:     c.Close,
+	}
+}
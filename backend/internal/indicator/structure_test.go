@@ -0,0 +1,94 @@
+package indicator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+func TestDetectStructureEventsConsecutiveHigherHighs(t *testing.T) {
+	// Two 3-bar fractal highs (110 at 4, 120 at 10) with flat lows between them, so FindSwings collapses
+	// them into one; each is broken by a close after its confirmation
+	bars := [][3]float64{ // High, low, close
+		{100, 99, 99.5}, {101, 100, 100.5}, {102, 101, 101.5}, {103, 102, 102.5},
+		{110, 103, 109}, {108, 104, 105}, {107, 104, 104.5}, {106, 104, 105},
+		{111, 104, 110.5}, // Breaks 110, confirmed at 7
+		{115, 104, 114}, {120, 110, 119}, {118, 112, 113}, {117, 112, 112.5}, {116, 112, 113},
+		{122, 112, 121}, // Breaks 120, confirmed at 13
+		{123, 112, 122},
+	}
+	candles := make([]model.Candle, len(bars))
+	for i, b := range bars {
+		candles[i] = model.Candle{Timestamp: int64(i) * 3600000, Open: b[2], High: b[0], Low: b[1], Close: b[2]}
+	}
+
+	config := DefaultSwingConfig()
+	if highs := SwingHighs(ConfirmedSwings(FindSwings(candles, config))); len(highs) != 1 || highs[0] != 120 {
+		t.Fatalf("collapsed swing highs %v, want only 120", highs)
+	}
+
+	events := DetectStructureEvents(candles, config)
+	want := []struct {
+		level      float64
+		swingIndex int
+		breakIndex int
+		label      string
+	}{
+		{110, 4, 8, ""},
+		{120, 10, 14, "HH"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events %+v, want %d", len(events), events, len(want))
+	}
+	for i, w := range want {
+		e := events[i]
+		if e.Type != "BOS" || e.Direction != "BULLISH" || e.Level != w.level || e.SwingIndex != w.swingIndex ||
+			e.BreakIndex != w.breakIndex || e.SwingLabel != w.label {
+			t.Errorf("event %d: got %+v, want a bullish BOS of %v (%d, %q) at %d", i, e, w.level, w.swingIndex, w.label, w.breakIndex)
+		}
+	}
+}
+
+func TestDetectStructureEventsChoch(t *testing.T) {
+	// Up to 110, down to 100, up to 115 (BOS of 110), then down through 100 against the bullish structure
+	candles := zigzagCandles([]float64{95, 110, 100, 115, 90}, 6)
+	events := DetectStructureEvents(candles, DefaultSwingConfig())
+	if len(events) != 2 {
+		t.Fatalf("got %d events %+v, want 2", len(events), events)
+	}
+	if events[0].Type != "BOS" || events[0].Direction != "BULLISH" || events[0].SwingIndex != 6 {
+		t.Errorf("got %+v, want a bullish BOS of the high at 6", events[0])
+	}
+	if events[1].Type != "CHOCH" || events[1].Direction != "BEARISH" || events[1].SwingIndex != 12 {
+		t.Errorf("got %+v, want a bearish CHoCH of the low at 12", events[1])
+	}
+}
+
+func TestDetectStructureEventsNoLookahead(t *testing.T) {
+	candles := randomWalkCandles(300, 11)
+	percent := DefaultSwingConfig()
+	percent.Mode = SwingModePercent
+	atr := DefaultSwingConfig()
+	atr.Mode = SwingModeATR
+
+	for _, config := range []SwingConfig{DefaultSwingConfig(), percent, atr} {
+		full := DetectStructureEvents(candles, config)
+		if len(full) == 0 {
+			t.Fatalf("%s: no events on a 300-bar random walk", config.Mode)
+		}
+		// Events up to any bar must not change when later bars arrive
+		for n := 50; n <= len(candles); n += 25 {
+			var want []model.StructureEvent
+			for _, e := range full {
+				if e.BreakIndex < n {
+					want = append(want, e)
+				}
+			}
+			got := DetectStructureEvents(candles[:n], config)
+			if len(got) != len(want) || (len(want) > 0 && !reflect.DeepEqual(got, want)) {
+				t.Errorf("%s: %d events on the first %d bars, want the %d the full series has by then", config.Mode, len(got), n, len(want))
+			}
+		}
+	}
+}
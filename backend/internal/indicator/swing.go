@@ -0,0 +1,257 @@
+package indicator
+
+import (
+	"math"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// SwingMode selects how swing points are detected
+type SwingMode string
+
+const (
+	// SwingModeFractal marks a bar whose high (low) is strictly above (below) Strength bars on each side
+	SwingModeFractal SwingMode = "FRACTAL"
+	// SwingModePercent is a ZigZag that confirms a swing after a reversal of Percent from the extreme
+	SwingModePercent SwingMode = "PERCENT"
+	// SwingModeATR is a ZigZag that confirms a swing after a reversal of ATRMultiple × ATR
+	SwingModeATR SwingMode = "ATR"
+)
+
+// SwingConfig configures the swing engine
+type SwingConfig struct {
+	Mode        SwingMode
+	Strength    int     // Fractal: bars required on each side
+	Percent     float64 // Percent ZigZag: reversal threshold, e.g. 0.03 = 3%
+	ATRMultiple float64 // ATR ZigZag: reversal threshold in ATRs
+	ATRPeriod   int     // ATR ZigZag: ATR period
+}
+
+// DefaultSwingConfig returns a 3-bar fractal configuration
+func DefaultSwingConfig() SwingConfig {
+	return SwingConfig{
+		Mode:        SwingModeFractal,
+		Strength:    3,
+		Percent:     0.03,
+		ATRMultiple: 2.0,
+		ATRPeriod:   14,
+	}
+}
+
+// FindSwings returns an ordered, alternating list of swing highs and lows.
+// Consecutive swings of the same type are collapsed into the more extreme one.
+// The final element may be an unconfirmed swing (the extreme of the open leg); it has Confirmed set to false.
+func FindSwings(candles []model.Candle, config SwingConfig) []model.SwingPoint {
+	swings := make([]model.SwingPoint, 0)
+	for _, p := range findPivots(candles, config) {
+		swings = appendSwing(swings, p.SwingPoint)
+	}
+	return appendOpenLeg(swings, candles)
+}
+
+// pivot is a confirmed swing point before collapsing, with the bar whose close confirms it
+type pivot struct {
+	model.SwingPoint
+	confirmedAt int
+}
+
+// findPivots returns every confirmed swing point in order of confirmation, without collapsing
+// consecutive swings of the same type
+func findPivots(candles []model.Candle, config SwingConfig) []pivot {
+	switch config.Mode {
+	case SwingModePercent:
+		return zigZagPivots(candles, func(i int, extreme float64) float64 {
+			return extreme * config.Percent
+		})
+	case SwingModeATR:
+		atrValues := CalculateATR(candles, config.ATRPeriod).Values
+		return zigZagPivots(candles, func(i int, extreme float64) float64 {
+			if i >= len(atrValues) || math.IsNaN(atrValues[i]) || atrValues[i] <= 0 {
+				return math.Inf(1)
+			}
+			return atrValues[i] * config.ATRMultiple
+		})
+	default:
+		return fractalPivots(candles, config.Strength)
+	}
+}
+
+// ConfirmedSwings drops the trailing unconfirmed swing, if any
+func ConfirmedSwings(swings []model.SwingPoint) []model.SwingPoint {
+	if len(swings) > 0 && !swings[len(swings)-1].Confirmed {
+		return swings[:len(swings)-1]
+	}
+	return swings
+}
+
+// SwingHighs returns the prices of the swing highs in order
+func SwingHighs(swings []model.SwingPoint) []float64 {
+	return swingPrices(swings, model.SwingHigh)
+}
+
+// SwingLows returns the prices of the swing lows in order
+func SwingLows(swings []model.SwingPoint) []float64 {
+	return swingPrices(swings, model.SwingLow)
+}
+
+func swingPrices(swings []model.SwingPoint, swingType string) []float64 {
+	prices := make([]float64, 0, len(swings)/2+1)
+	for _, s := range swings {
+		if s.Type == swingType {
+			prices = append(prices, s.Price)
+		}
+	}
+	return prices
+}
+
+// fractalPivots returns the bars whose high (low) is strictly above (below) strength bars on each side,
+// confirmed strength bars later
+func fractalPivots(candles []model.Candle, strength int) []pivot {
+	pivots := make([]pivot, 0)
+	if strength < 1 {
+		strength = 1
+	}
+
+	for i := strength; i < len(candles)-strength; i++ {
+		isHigh, isLow := true, true
+		for j := i - strength; j <= i+strength; j++ {
+			if j == i {
+				continue
+			}
+			if candles[j].High >= candles[i].High {
+				isHigh = false
+			}
+			if candles[j].Low <= candles[i].Low {
+				isLow = false
+			}
+		}
+
+		if isHigh {
+			pivots = append(pivots, pivot{newSwing(candles, i, model.SwingHigh, true), i + strength})
+		}
+		if isLow {
+			pivots = append(pivots, pivot{newSwing(candles, i, model.SwingLow, true), i + strength})
+		}
+	}
+
+	return pivots
+}
+
+// zigZagPivots runs a ZigZag where threshold(i, extreme) is the reversal distance
+// required at bar i to confirm the running extreme as a swing
+func zigZagPivots(candles []model.Candle, threshold func(i int, extreme float64) float64) []pivot {
+	pivots := make([]pivot, 0)
+	if len(candles) < 2 {
+		return pivots
+	}
+
+	highIdx, lowIdx := 0, 0
+	direction := 0 // 1 = tracking a high, -1 = tracking a low, 0 = undecided
+
+	for i := 1; i < len(candles); i++ {
+		c := candles[i]
+
+		switch direction {
+		case 0:
+			if c.High > candles[highIdx].High {
+				highIdx = i
+			}
+			if c.Low < candles[lowIdx].Low {
+				lowIdx = i
+			}
+			if candles[highIdx].High-candles[lowIdx].Low >= threshold(i, candles[highIdx].High) {
+				if lowIdx < highIdx {
+					pivots = append(pivots, pivot{newSwing(candles, lowIdx, model.SwingLow, true), i})
+					direction = 1
+				} else {
+					pivots = append(pivots, pivot{newSwing(candles, highIdx, model.SwingHigh, true), i})
+					direction = -1
+				}
+			}
+
+		case 1:
+			if c.High > candles[highIdx].High {
+				highIdx = i
+			} else if candles[highIdx].High-c.Low >= threshold(i, candles[highIdx].High) {
+				pivots = append(pivots, pivot{newSwing(candles, highIdx, model.SwingHigh, true), i})
+				lowIdx = i
+				direction = -1
+			}
+
+		case -1:
+			if c.Low < candles[lowIdx].Low {
+				lowIdx = i
+			} else if c.High-candles[lowIdx].Low >= threshold(i, candles[lowIdx].Low) {
+				pivots = append(pivots, pivot{newSwing(candles, lowIdx, model.SwingLow, true), i})
+				highIdx = i
+				direction = 1
+			}
+		}
+	}
+
+	return pivots
+}
+
+// appendOpenLeg adds the extreme after the last confirmed swing as an unconfirmed swing
+func appendOpenLeg(swings []model.SwingPoint, candles []model.Candle) []model.SwingPoint {
+	if len(swings) == 0 {
+		return swings
+	}
+
+	last := swings[len(swings)-1]
+	extremeIdx := -1
+	for i := last.Index + 1; i < len(candles); i++ {
+		if last.Type == model.SwingLow {
+			if extremeIdx < 0 || candles[i].High > candles[extremeIdx].High {
+				extremeIdx = i
+			}
+		} else if extremeIdx < 0 || candles[i].Low < candles[extremeIdx].Low {
+			extremeIdx = i
+		}
+	}
+	if extremeIdx < 0 {
+		return swings
+	}
+
+	swingType := model.SwingHigh
+	if last.Type == model.SwingHigh {
+		swingType = model.SwingLow
+	}
+	open := newSwing(candles, extremeIdx, swingType, false)
+	if (swingType == model.SwingHigh && open.Price <= last.Price) || (swingType == model.SwingLow && open.Price >= last.Price) {
+		return swings
+	}
+	return append(swings, open)
+}
+
+func newSwing(candles []model.Candle, index int, swingType string, confirmed bool) model.SwingPoint {
+	price := candles[index].Low
+	if swingType == model.SwingHigh {
+		price = candles[index].High
+	}
+	return model.SwingPoint{
+		Index:     index,
+		Timestamp: candles[index].Timestamp,
+		Price:     price,
+		Type:      swingType,
+		Confirmed: confirmed,
+	}
+}
+
+// appendSwing keeps the sequence alternating: a second swing of the same type replaces the
+// previous one only if it is more extreme
+func appendSwing(swings []model.SwingPoint, s model.SwingPoint) []model.SwingPoint {
+	if len(swings) == 0 {
+		return append(swings, s)
+	}
+
+	last := &swings[len(swings)-1]
+	if last.Type != s.Type {
+		return append(swings, s)
+	}
+
+	if (s.Type == model.SwingHigh && s.Price > last.Price) || (s.Type == model.SwingLow && s.Price < last.Price) {
+		*last = s
+	}
+	return swings
+}
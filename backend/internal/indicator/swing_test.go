@@ -0,0 +1,120 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+func TestFindSwings(t *testing.T) {
+	halfPointSteps := func(from, to float64) int { return int(math.Abs(to-from) / 0.5) }
+	type want struct {
+		index     int
+		price     float64
+		swingType string
+		confirmed bool
+	}
+	fractal := DefaultSwingConfig()
+	percent := DefaultSwingConfig()
+	percent.Mode = SwingModePercent
+	atr := DefaultSwingConfig()
+	atr.Mode = SwingModeATR
+	atr.ATRMultiple = 3.5
+	atr.ATRPeriod = 3
+
+	tests := []struct {
+		name    string
+		candles []model.Candle
+		config  SwingConfig
+		want    []want
+	}{
+		{
+			// Strict 3-bar fractals at the turns; the last leg is still open
+			name:    "fractal",
+			candles: zigzagCandles([]float64{100, 110, 95, 120, 90}, 4),
+			config:  fractal,
+			want: []want{
+				{4, 110.2, model.SwingHigh, true},
+				{8, 94.8, model.SwingLow, true},
+				{12, 120.2, model.SwingHigh, true},
+				{16, 89.8, model.SwingLow, false},
+			},
+		},
+		{
+			// The 104-106 wiggle never reverses 3%, so the 110 high leads straight to the 95 low
+			name:    "percent",
+			candles: zigzagCandles([]float64{100, 110, 104, 106, 95, 101}, 2),
+			config:  percent,
+			want: []want{
+				{0, 99.8, model.SwingLow, true},
+				{2, 110.2, model.SwingHigh, true},
+				{8, 94.8, model.SwingLow, true},
+				{10, 101.2, model.SwingHigh, false},
+			},
+		},
+		{
+			// Half a point per bar with a one point range keeps the ATR at 1, so reversals need 3.5:
+			// the 105-103 dip and the final 99-101 bounce (3 with the wicks) do not count
+			name:    "atr",
+			candles: waypointCandles([]float64{100, 105, 103, 104, 99, 101}, halfPointSteps, 0.5),
+			config:  atr,
+			want: []want{
+				{0, 99.5, model.SwingLow, true},
+				{10, 105.5, model.SwingHigh, true},
+				{26, 98.5, model.SwingLow, false},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			swings := FindSwings(tt.candles, tt.config)
+			if len(swings) != len(tt.want) {
+				t.Fatalf("got %d swings %+v, want %d", len(swings), swings, len(tt.want))
+			}
+			for i, w := range tt.want {
+				s := swings[i]
+				if s.Index != w.index || math.Abs(s.Price-w.price) > 1e-9 || s.Type != w.swingType || s.Confirmed != w.confirmed {
+					t.Errorf("swing %d: got %d %v %s confirmed=%v, want %d %v %s confirmed=%v",
+						i, s.Index, s.Price, s.Type, s.Confirmed, w.index, w.price, w.swingType, w.confirmed)
+				}
+				if s.Timestamp != tt.candles[s.Index].Timestamp {
+					t.Errorf("swing %d: timestamp %d, want %d", i, s.Timestamp, tt.candles[s.Index].Timestamp)
+				}
+			}
+			if confirmed := ConfirmedSwings(swings); len(confirmed) != len(swings)-1 {
+				t.Errorf("ConfirmedSwings kept %d of %d swings, want the open leg dropped", len(confirmed), len(swings))
+			}
+		})
+	}
+}
+
+func TestAppendSwingCollapses(t *testing.T) {
+	swing := func(index int, price float64, swingType string) model.SwingPoint {
+		return model.SwingPoint{Index: index, Price: price, Type: swingType, Confirmed: true}
+	}
+
+	var swings []model.SwingPoint
+	for _, s := range []model.SwingPoint{
+		swing(1, 10, model.SwingHigh),
+		swing(3, 12, model.SwingHigh), // Higher: replaces the first high
+		swing(5, 11, model.SwingHigh), // Lower: dropped
+		swing(7, 5, model.SwingLow),
+		swing(9, 6, model.SwingLow),  // Higher low: dropped
+		swing(11, 4, model.SwingLow), // Lower: replaces
+		swing(13, 9, model.SwingHigh),
+	} {
+		swings = appendSwing(swings, s)
+	}
+
+	want := []model.SwingPoint{swing(3, 12, model.SwingHigh), swing(11, 4, model.SwingLow), swing(13, 9, model.SwingHigh)}
+	if len(swings) != len(want) {
+		t.Fatalf("got %+v, want %+v", swings, want)
+	}
+	for i := range want {
+		if swings[i] != want[i] {
+			t.Errorf("swing %d: got %+v, want %+v", i, swings[i], want[i])
+		}
+	}
+}
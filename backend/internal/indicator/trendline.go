@@ -0,0 +1,285 @@
+package indicator
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// MinTrendlineTouches is the number of swings a line must pass through to count as a trendline
+const MinTrendlineTouches = 3
+
+// trendlineSwings limits the candidate swings per side, keeping the pair search small
+const trendlineSwings = 12
+
+// recentTrendlineBreak is how many bars a broken line stays ranked alongside unbroken ones
+const recentTrendlineBreak = 20
+
+// DetectTrendlines fits lines through every pair of recent confirmed swing highs and swing lows, keeps the lines
+// touched by at least MinTrendlineTouches swings, scores them by touches, span and violations, pairs
+// roughly parallel support and resistance lines into channels, and reports closes of closed candles
+// through a line after its last touch as breaks
+func DetectTrendlines(candles []model.Candle, swings []model.SwingPoint) model.TrendlineAnalysis {
+	result := model.TrendlineAnalysis{
+		Lines:       []model.Trendline{},
+		Channels:    []model.TrendChannel{},
+		BreakEvents: []model.TrendlineBreak{},
+	}
+	if len(candles) < 30 {
+		return result
+	}
+
+	atr := CalculateATR(candles, 14).GetCurrentATR()
+	if atr <= 0 {
+		return result
+	}
+
+	var highs, lows []chartSwing
+	for _, s := range chartSwings(swings) {
+		if s.isHigh {
+			highs = append(highs, s)
+		} else {
+			lows = append(lows, s)
+		}
+	}
+	if len(highs) > trendlineSwings {
+		highs = highs[len(highs)-trendlineSwings:]
+	}
+	if len(lows) > trendlineSwings {
+		lows = lows[len(lows)-trendlineSwings:]
+	}
+
+	tolerance := atr * 0.3
+	support := bestTrendlines(fitTrendlines(candles, lows, false, tolerance), len(candles), 3)
+	resistance := bestTrendlines(fitTrendlines(candles, highs, true, tolerance), len(candles), 3)
+
+	result.Lines = append(append(result.Lines, support...), resistance...)
+	result.Channels = detectTrendChannels(candles, support, resistance, atr)
+
+	for _, line := range result.Lines {
+		if line.Break != nil {
+			result.BreakEvents = append(result.BreakEvents, *line.Break)
+		}
+	}
+	sort.Slice(result.BreakEvents, func(i, j int) bool {
+		return result.BreakEvents[i].Index < result.BreakEvents[j].Index
+	})
+
+	return result
+}
+
+// fitTrendlines builds a candidate line through every pair of swings and evaluates it
+func fitTrendlines(candles []model.Candle, swings []chartSwing, isHigh bool, tolerance float64) []model.Trendline {
+	lines := make([]model.Trendline, 0)
+	n := len(candles)
+
+	lineType := "SUPPORT"
+	sign := 1.0 // Support holds while closes stay above the line
+	if isHigh {
+		lineType = "RESISTANCE"
+		sign = -1
+	}
+
+	for i := 0; i < len(swings); i++ {
+		for j := i + 1; j < len(swings); j++ {
+			line := lineThrough(swings[i], swings[j])
+
+			touches := make([]chartSwing, 0)
+			for k := i; k < len(swings); k++ {
+				if math.Abs(swings[k].price-line.at(swings[k].index)) <= tolerance {
+					touches = append(touches, swings[k])
+				}
+			}
+			if len(touches) < MinTrendlineTouches {
+				continue
+			}
+
+			// Violations: closes through the line while it was being formed
+			first, last := touches[0].index, touches[len(touches)-1].index
+			violations := 0
+			for k := first; k <= last; k++ {
+				if sign*(candles[k].Close-line.at(k)) < -tolerance {
+					violations++
+				}
+			}
+			// More closes through the line than swings on it: not a line price respects
+			if violations > len(touches) {
+				continue
+			}
+
+			trendline := model.Trendline{
+				Type:         lineType,
+				Direction:    slopeDirection(line.slope, tolerance),
+				Touches:      make([]model.PatternPoint, len(touches)),
+				Slope:        line.slope,
+				StartIndex:   first,
+				Violations:   violations,
+				CurrentPrice: line.at(n - 1),
+			}
+			for k, t := range touches {
+				trendline.Touches[k] = patternPoint(candles, "T"+strconv.Itoa(k+1), t)
+			}
+
+			// First close through the line after the last touch; the last candle is still forming
+			for k := last + 1; k < n-1; k++ {
+				if sign*(candles[k].Close-line.at(k)) < -tolerance {
+					direction := "DOWN"
+					if isHigh {
+						direction = "UP"
+					}
+					trendline.Broken = true
+					trendline.Break = &model.TrendlineBreak{
+						LineType:  lineType,
+						Direction: direction,
+						Index:     k,
+						Timestamp: candles[k].Timestamp,
+						Close:     candles[k].Close,
+						LinePrice: line.at(k),
+					}
+					break
+				}
+			}
+
+			span := float64(last-first) / float64(n)
+			trendline.Score = clamp01(0.3 + 0.15*float64(len(touches)-MinTrendlineTouches+1) + 0.3*span - 0.1*float64(violations))
+			lines = append(lines, trendline)
+		}
+	}
+
+	return lines
+}
+
+// bestTrendlines keeps the highest scoring lines, dropping any line that shares two touches with a
+// better one. Lines broken more than recentTrendlineBreak bars ago rank last.
+func bestTrendlines(lines []model.Trendline, n, limit int) []model.Trendline {
+	stale := func(line model.Trendline) bool {
+		return line.Broken && line.Break.Index < n-recentTrendlineBreak
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		if stale(lines[i]) != stale(lines[j]) {
+			return !stale(lines[i])
+		}
+		if lines[i].Score != lines[j].Score {
+			return lines[i].Score > lines[j].Score
+		}
+		return len(lines[i].Touches) > len(lines[j].Touches)
+	})
+
+	kept := make([]model.Trendline, 0, limit)
+	for _, line := range lines {
+		duplicate := false
+		for _, k := range kept {
+			if sharedTouches(line, k) >= 2 {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			kept = append(kept, line)
+			if len(kept) == limit {
+				break
+			}
+		}
+	}
+
+	return kept
+}
+
+func sharedTouches(a, b model.Trendline) int {
+	shared := 0
+	for _, ta := range a.Touches {
+		for _, tb := range b.Touches {
+			if ta.Index == tb.Index {
+				shared++
+			}
+		}
+	}
+	return shared
+}
+
+// detectTrendChannels pairs unbroken support and resistance lines whose drift over the window differs
+// by less than one ATR and whose resistance stays above support
+func detectTrendChannels(candles []model.Candle, support, resistance []model.Trendline, atr float64) []model.TrendChannel {
+	channels := make([]model.TrendChannel, 0)
+	n := len(candles)
+	currentPrice := candles[n-1].Close
+
+	for _, lower := range support {
+		for _, upper := range resistance {
+			if lower.Broken || upper.Broken {
+				continue
+			}
+			if math.Abs(upper.Slope-lower.Slope)*float64(n) > atr {
+				continue
+			}
+			width := upper.CurrentPrice - lower.CurrentPrice
+			if width <= 0 {
+				continue
+			}
+
+			slope := (upper.Slope + lower.Slope) / 2
+			channels = append(channels, model.TrendChannel{
+				Direction: slopeDirection(slope, atr*0.3),
+				Upper:     upper,
+				Lower:     lower,
+				Width:     width,
+				Position:  (currentPrice - lower.CurrentPrice) / width,
+				Score:     (upper.Score + lower.Score) / 2,
+			})
+		}
+	}
+
+	sort.SliceStable(channels, func(i, j int) bool {
+		return channels[i].Score > channels[j].Score
+	})
+	if len(channels) > 2 {
+		channels = channels[:2]
+	}
+
+	return channels
+}
+
+// slopeDirection classifies a slope as flat when it drifts less than tolerance over 10 bars
+func slopeDirection(slope, tolerance float64) string {
+	if math.Abs(slope)*10 < tolerance {
+		return "FLAT"
+	}
+	if slope > 0 {
+		return "UP"
+	}
+	return "DOWN"
+}
+
+// MergeTrendlineLevels adds the current projection of every unbroken trendline to the SR levels as a
+// dynamic level (source "TRENDLINE"), on the side of the current price it sits on. Each side keeps its
+// strongest levels, nearest first.
+func MergeTrendlineLevels(levels model.SRLevels, trendlines model.TrendlineAnalysis, currentPrice float64) model.SRLevels {
+	resistance := append([]model.SRLevel{}, levels.Resistance...)
+	support := append([]model.SRLevel{}, levels.Support...)
+
+	for _, line := range trendlines.Lines {
+		if line.Broken {
+			continue
+		}
+		level := model.SRLevel{
+			Price:    line.CurrentPrice,
+			Strength: line.Score,
+			Source:   "TRENDLINE",
+			Touches:  len(line.Touches),
+		}
+		if line.CurrentPrice > currentPrice {
+			level.Role = SRRoleResistance
+			resistance = append(resistance, level)
+		} else {
+			level.Role = SRRoleSupport
+			support = append(support, level)
+		}
+	}
+
+	return model.SRLevels{
+		Resistance: strongestNearestFirst(resistance, true),
+		Support:    strongestNearestFirst(support, false),
+	}
+}
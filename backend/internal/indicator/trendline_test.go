@@ -0,0 +1,153 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// channelCandles rises in a channel: lows on 100 + 0.5x at bars 0, 12 and 24, highs on 110 + 0.5x at
+// bars 6, 18 and 30, then a pullback to 120 at bar 36 that stays inside
+func channelCandles() ([]model.Candle, []model.SwingPoint) {
+	candles := zigzagCandles([]float64{100, 113, 106, 119, 112, 125, 120}, 6)
+	swings := make([]model.SwingPoint, 0, 6)
+	for i := 0; i <= 30; i += 6 {
+		swing := model.SwingPoint{Index: i, Price: candles[i].Low, Type: model.SwingLow, Confirmed: true}
+		if i%12 != 0 {
+			swing.Price, swing.Type = candles[i].High, model.SwingHigh
+		}
+		swings = append(swings, swing)
+	}
+	return candles, swings
+}
+
+func TestDetectTrendlinesChannel(t *testing.T) {
+	candles, swings := channelCandles()
+	got := DetectTrendlines(candles, swings)
+
+	// The pairs (0, 12) and (0, 24) fit the same line; the duplicate is dropped
+	if len(got.Lines) != 2 {
+		t.Fatalf("lines = %+v, want one support and one resistance", got.Lines)
+	}
+	for i, want := range []struct {
+		kind    string
+		current float64
+	}{{"SUPPORT", 117.8}, {"RESISTANCE", 128.2}} {
+		line := got.Lines[i]
+		if line.Type != want.kind || len(line.Touches) != 3 || line.Violations != 0 || line.Direction != "UP" {
+			t.Errorf("line %d = %+v, want a rising %s line with 3 touches", i, line, want.kind)
+		}
+		if math.Abs(line.Slope-0.5) > 1e-9 || math.Abs(line.CurrentPrice-want.current) > 1e-9 || line.Broken {
+			t.Errorf("line %d slope %.2f at %.2f broken=%v, want 0.5 at %.1f unbroken", i, line.Slope, line.CurrentPrice, line.Broken, want.current)
+		}
+	}
+
+	if len(got.Channels) != 1 {
+		t.Fatalf("channels = %+v, want one", got.Channels)
+	}
+	channel := got.Channels[0]
+	if channel.Direction != "UP" || math.Abs(channel.Width-10.4) > 1e-9 || math.Abs(channel.Position-2.2/10.4) > 1e-9 {
+		t.Errorf("channel = %s width %.2f position %.3f, want UP 10.4 wide at %.3f", channel.Direction, channel.Width, channel.Position, 2.2/10.4)
+	}
+	if len(got.BreakEvents) != 0 {
+		t.Errorf("break events = %+v, want none", got.BreakEvents)
+	}
+}
+
+func TestDetectTrendlinesBreak(t *testing.T) {
+	candles, swings := channelCandles()
+	// Bar 37 closes at 131, above the resistance at 128.7
+	breakout := model.Candle{Timestamp: 37 * hourMs, Open: 120, High: 131.2, Low: 119.8, Close: 131, Volume: 1}
+
+	// While the breakout candle is still forming it is not a break
+	forming := DetectTrendlines(append(candles, breakout), swings)
+	if len(forming.BreakEvents) != 0 || forming.Lines[1].Broken {
+		t.Errorf("break events = %+v, want none while the candle is forming", forming.BreakEvents)
+	}
+
+	next := breakout
+	next.Timestamp += hourMs
+	got := DetectTrendlines(append(candles, breakout, next), swings)
+	if len(got.BreakEvents) != 1 {
+		t.Fatalf("break events = %+v, want the resistance break", got.BreakEvents)
+	}
+	b := got.BreakEvents[0]
+	if b.LineType != "RESISTANCE" || b.Direction != "UP" || b.Index != 37 || b.Close != 131 || math.Abs(b.LinePrice-128.7) > 1e-9 {
+		t.Errorf("break = %+v, want an UP break of the resistance at bar 37", b)
+	}
+	// A broken side no longer frames a channel
+	if len(got.Channels) != 0 {
+		t.Errorf("channels = %+v, want none after the break", got.Channels)
+	}
+}
+
+func TestFitTrendlinesViolations(t *testing.T) {
+	candles, swings := channelCandles()
+	var lows []chartSwing
+	for _, s := range chartSwings(swings) {
+		if !s.isHigh {
+			lows = append(lows, s)
+		}
+	}
+	line := lineThrough(lows[0], lows[2])
+
+	tests := []struct {
+		name       string
+		violations int
+		wantLines  int
+	}{
+		{"respected", 0, 2},
+		{"fewer violations than touches", 2, 2},
+		// More closes through the line than swings on it
+		{"rejected", 4, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violated := append([]model.Candle{}, candles...)
+			for k := 2; k < 2+tt.violations; k++ {
+				violated[k].Close = line.at(k) - 2
+			}
+			lines := fitTrendlines(violated, lows, false, 0.5)
+			if len(lines) != tt.wantLines {
+				t.Fatalf("got %d lines, want %d", len(lines), tt.wantLines)
+			}
+			for _, l := range lines {
+				if l.Violations != tt.violations {
+					t.Errorf("violations = %d, want %d", l.Violations, tt.violations)
+				}
+			}
+		})
+	}
+}
+
+func TestBestTrendlines(t *testing.T) {
+	touches := func(indices ...int) []model.PatternPoint {
+		points := make([]model.PatternPoint, len(indices))
+		for i, index := range indices {
+			points[i] = model.PatternPoint{Index: index}
+		}
+		return points
+	}
+	broken := func(index int) *model.TrendlineBreak { return &model.TrendlineBreak{Index: index} }
+
+	lines := []model.Trendline{
+		{Score: 0.9, Touches: touches(10, 20, 30), Broken: true, Break: broken(50)},
+		{Score: 0.5, Touches: touches(40, 50, 60)},
+		{Score: 0.6, Touches: touches(15, 25, 35), Broken: true, Break: broken(90)},
+		// Shares two touches with the better 0.6 line
+		{Score: 0.55, Touches: touches(25, 35, 45)},
+	}
+
+	// Broken 50 bars ago the best scoring line is stale and ranks last; the one broken 10 bars ago does not
+	got := bestTrendlines(lines, 100, 3)
+	want := []float64{0.6, 0.5, 0.9}
+	if len(got) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(got), len(want))
+	}
+	for i, score := range want {
+		if got[i].Score != score {
+			t.Errorf("line %d score = %.2f, want %.2f", i, got[i].Score, score)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package indicator
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// RealizedVolatilityWindow is the default number of bars per realized volatility estimate
+const RealizedVolatilityWindow = 20
+
+// IntervalDuration parses a Binance kline interval ("15m", "4h", "1d", "1w", "1M") into a duration.
+// Months count as 30 days. Unknown intervals return 0.
+func IntervalDuration(interval string) time.Duration {
+	if len(interval) < 2 {
+		return 0
+	}
+
+	n, err := strconv.Atoi(interval[:len(interval)-1])
+	if err != nil || n <= 0 {
+		return 0
+	}
+
+	switch interval[len(interval)-1] {
+	case 'm':
+		return time.Duration(n) * time.Minute
+	case 'h':
+		return time.Duration(n) * time.Hour
+	case 'd':
+		return time.Duration(n) * 24 * time.Hour
+	case 'w':
+		return time.Duration(n) * 7 * 24 * time.Hour
+	case 'M':
+		return time.Duration(n) * 30 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// PeriodsPerYear returns how many bars of the interval fit in a year of continuous (24/7) trading
+func PeriodsPerYear(interval string) float64 {
+	d := IntervalDuration(interval)
+	if d <= 0 {
+		return 365
+	}
+	return float64(365*24*time.Hour) / float64(d)
+}
+
+// CalculateRealizedVolatility computes close-to-close, Parkinson, Garman-Klass and Yang-Zhang
+// realized volatility over the last window bars, annualized for the interval and expressed in percent.
+// The Yang-Zhang estimate is also computed on every rolling window to rank the current value
+// against the series' own history.
+func CalculateRealizedVolatility(candles []model.Candle, interval string, window int) *model.RealizedVolatility {
+	return CalculateRealizedVolatilityWithHistory(candles, nil, interval, window)
+}
+
+// CalculateRealizedVolatilityWithHistory is CalculateRealizedVolatility with the percentile also ranked
+// against rolling windows of stored history. Stored candles at or after the first live candle are ignored,
+// and no window spans the gap between the two series.
+func CalculateRealizedVolatilityWithHistory(candles, stored []model.Candle, interval string, window int) *model.RealizedVolatility {
+	if window < 2 || len(candles) < window+1 {
+		return nil
+	}
+
+	annualize := math.Sqrt(PeriodsPerYear(interval)) * 100
+	n := len(candles)
+
+	older := len(stored)
+	for older > 0 && stored[older-1].Timestamp >= candles[0].Timestamp {
+		older--
+	}
+	history := rollingYangZhang(stored[:older], window, annualize)
+	history = append(history, rollingYangZhang(candles, window, annualize)...)
+
+	recent := candles[n-window-1:]
+	result := &model.RealizedVolatility{
+		Interval:     interval,
+		Window:       window,
+		CloseToClose: closeToClose(recent) * annualize,
+		Parkinson:    parkinson(recent[1:]) * annualize,
+		GarmanKlass:  garmanKlass(recent[1:]) * annualize,
+		YangZhang:    yangZhang(recent) * annualize,
+		HistorySize:  len(history),
+		Percentile:   50,
+	}
+	if len(history) >= 2 {
+		result.Percentile = percentileRank(history, result.YangZhang)
+	}
+
+	return result
+}
+
+// rollingYangZhang returns the annualized Yang-Zhang estimate of every window-bar window in candles
+func rollingYangZhang(candles []model.Candle, window int, annualize float64) []float64 {
+	history := make([]float64, 0, max(0, len(candles)-window))
+	for end := window + 1; end <= len(candles); end++ {
+		if v := yangZhang(candles[end-window-1 : end]); v > 0 {
+			history = append(history, v*annualize)
+		}
+	}
+	return history
+}
+
+// VolatilityLevelFromPercentile maps a volatility percentile to "HIGH" (top 20%), "LOW" (bottom 20%) or "NORMAL"
+func VolatilityLevelFromPercentile(percentile float64) string {
+	if percentile >= 80 {
+		return "HIGH"
+	}
+	if percentile <= 20 {
+		return "LOW"
+	}
+	return "NORMAL"
+}
+
+// closeToClose is the sample standard deviation of log close-to-close returns.
+// The first candle only supplies the previous close.
+func closeToClose(candles []model.Candle) float64 {
+	returns := make([]float64, 0, len(candles)-1)
+	for i := 1; i < len(candles); i++ {
+		returns = append(returns, math.Log(candles[i].Close/candles[i-1].Close))
+	}
+	return math.Sqrt(variance(returns))
+}
+
+// parkinson uses the high-low range: σ² = Σ ln(H/L)² / (4 ln2 · n)
+func parkinson(candles []model.Candle) float64 {
+	sum := 0.0
+	for _, c := range candles {
+		hl := math.Log(c.High / c.Low)
+		sum += hl * hl
+	}
+	return math.Sqrt(sum / (4 * math.Ln2 * float64(len(candles))))
+}
+
+// garmanKlass adds the open-close move: σ² = mean(½ ln(H/L)² − (2 ln2 − 1) ln(C/O)²)
+func garmanKlass(candles []model.Candle) float64 {
+	sum := 0.0
+	for _, c := range candles {
+		hl := math.Log(c.High / c.Low)
+		co := math.Log(c.Close / c.Open)
+		sum += 0.5*hl*hl - (2*math.Ln2-1)*co*co
+	}
+	return math.Sqrt(math.Max(0, sum/float64(len(candles))))
+}
+
+// yangZhang combines overnight (open vs previous close), open-to-close and Rogers-Satchell variances.
+// The first candle only supplies the previous close.
+func yangZhang(candles []model.Candle) float64 {
+	n := len(candles) - 1
+	if n < 2 {
+		return 0
+	}
+
+	overnight := make([]float64, 0, n)
+	openClose := make([]float64, 0, n)
+	rs := 0.0
+	for i := 1; i < len(candles); i++ {
+		c := candles[i]
+		overnight = append(overnight, math.Log(c.Open/candles[i-1].Close))
+		openClose = append(openClose, math.Log(c.Close/c.Open))
+		rs += math.Log(c.High/c.Close)*math.Log(c.High/c.Open) + math.Log(c.Low/c.Close)*math.Log(c.Low/c.Open)
+	}
+
+	k := 0.34 / (1.34 + float64(n+1)/float64(n-1))
+	sigma2 := variance(overnight) + k*variance(openClose) + (1-k)*rs/float64(n)
+	return math.Sqrt(math.Max(0, sigma2))
+}
@@ -0,0 +1,94 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRealizedVolatilityEstimators(t *testing.T) {
+	annualize := math.Sqrt(365*24) * 100
+	hl := math.Log(101.0 / 99.0)
+
+	// Flat closes with a constant 99-101 range: no close-to-close or open-close variance
+	got := CalculateRealizedVolatility(flatCandles(0, 30, 100, 101, 99), "1h", 20)
+	if got == nil {
+		t.Fatal("no estimate for 30 candles")
+	}
+
+	k := 0.34 / (1.34 + 21.0/19.0)
+	rs := math.Log(101.0/100)*math.Log(101.0/100) + math.Log(99.0/100)*math.Log(99.0/100)
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"close to close", got.CloseToClose, 0},
+		{"parkinson", got.Parkinson, hl / math.Sqrt(4*math.Ln2) * annualize},
+		{"garman-klass", got.GarmanKlass, math.Sqrt(0.5) * hl * annualize},
+		{"yang-zhang", got.YangZhang, math.Sqrt((1-k)*rs) * annualize},
+	}
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > 1e-9*math.Max(1, tt.want) {
+			t.Errorf("%s = %.10f, want %.10f", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// Closes alternating 100/101: log returns of ±ln(1.01)
+	alternating := flatCandles(0, 21, 100, 102, 99)
+	for i := range alternating {
+		if i%2 == 1 {
+			alternating[i].Close = 101
+		}
+	}
+	r := math.Log(1.01)
+	wantCC := math.Sqrt(20*r*r/19) * annualize
+	if got := CalculateRealizedVolatility(alternating, "1h", 20); math.Abs(got.CloseToClose-wantCC) > 1e-9*wantCC {
+		t.Errorf("alternating close to close = %.10f, want %.10f", got.CloseToClose, wantCC)
+	}
+
+	if CalculateRealizedVolatility(flatCandles(0, 20, 100, 101, 99), "1h", 20) != nil {
+		t.Error("estimate returned without a previous close for the first bar")
+	}
+}
+
+func TestRealizedVolatilityPercentileUsesStoredHistory(t *testing.T) {
+	// Live window: quiet 99.5-100.5 bars after a wider 99-101 stretch
+	live := append(flatCandles(1000*hourMs, 40, 100, 101, 99), flatCandles(1040*hourMs, 30, 100, 100.5, 99.5)...)
+
+	own := CalculateRealizedVolatility(live, "1h", 20)
+	if own.HistorySize != 50 {
+		t.Fatalf("history size = %d, want 50", own.HistorySize)
+	}
+
+	// Stored history even quieter than the live window ranks the current value higher.
+	// Its last candles overlap the live series and must not be counted twice.
+	stored := flatCandles(0, 1010, 100, 100.1, 99.9)
+	ranked := CalculateRealizedVolatilityWithHistory(live, stored, "1h", 20)
+	if ranked.HistorySize != own.HistorySize+980 {
+		t.Errorf("history size = %d, want %d", ranked.HistorySize, own.HistorySize+980)
+	}
+	if ranked.YangZhang != own.YangZhang {
+		t.Errorf("stored history changed the current estimate: %.4f vs %.4f", ranked.YangZhang, own.YangZhang)
+	}
+	if ranked.Percentile <= own.Percentile {
+		t.Errorf("percentile = %.1f, want above the live-only %.1f", ranked.Percentile, own.Percentile)
+	}
+}
+
+func TestPeriodsPerYear(t *testing.T) {
+	tests := []struct {
+		interval string
+		want     float64
+	}{
+		{"1h", 8760},
+		{"15m", 35040},
+		{"1d", 365},
+		{"1w", 365.0 / 7},
+		{"bogus", 365},
+	}
+	for _, tt := range tests {
+		if got := PeriodsPerYear(tt.interval); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("PeriodsPerYear(%q) = %f, want %f", tt.interval, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package indicator
+
+import (
+	"math"
+	"time"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// VolumeProfileConfig controls which candles feed the profile and how it is binned
+type VolumeProfileConfig struct {
+	Mode         string  // "fixed" (last Lookback candles), "session" (current UTC day), "visible" (all candles)
+	Lookback     int     // Candles used in fixed range mode
+	Bins         int     // Number of price bins
+	ValueAreaPct float64 // Share of volume inside the value area (typically 0.70)
+}
+
+// DefaultVolumeProfileConfig returns the default profile settings (visible range, 24 bins, 70% value area)
+func DefaultVolumeProfileConfig() VolumeProfileConfig {
+	return VolumeProfileConfig{
+		Mode:         "visible",
+		Lookback:     100,
+		Bins:         24,
+		ValueAreaPct: 0.70,
+	}
+}
+
+// SelectVolumeProfileWindow returns the candles covered by the configured profile window
+func SelectVolumeProfileWindow(candles []model.Candle, config VolumeProfileConfig) []model.Candle {
+	if len(candles) == 0 {
+		return candles
+	}
+
+	switch config.Mode {
+	case "fixed":
+		lookback := config.Lookback
+		if lookback <= 0 || lookback > len(candles) {
+			lookback = len(candles)
+		}
+		return candles[len(candles)-lookback:]
+	case "session":
+		// Session starts at 00:00 UTC of the latest candle's day
+		last := time.UnixMilli(candles[len(candles)-1].Timestamp).UTC()
+		sessionStart := time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, time.UTC).UnixMilli()
+		startIdx := len(candles) - 1
+		for startIdx > 0 && candles[startIdx-1].Timestamp >= sessionStart {
+			startIdx--
+		}
+		return candles[startIdx:]
+	default:
+		return candles
+	}
+}
+
+// CalculateVolumeProfile builds a volume-by-price histogram for the configured window
+// Each candle's volume is spread evenly across the part of its high-low range that overlaps each bin
+func CalculateVolumeProfile(candles []model.Candle, config VolumeProfileConfig) *model.VolumeProfile {
+	window := SelectVolumeProfileWindow(candles, config)
+	if len(window) == 0 {
+		return nil
+	}
+
+	bins := config.Bins
+	if bins <= 0 {
+		bins = 24
+	}
+	valueAreaPct := config.ValueAreaPct
+	if valueAreaPct <= 0 || valueAreaPct > 1 {
+		valueAreaPct = 0.70
+	}
+
+	// Determine price range of the window
+	high := window[0].High
+	low := window[0].Low
+	for _, c := range window {
+		high = math.Max(high, c.High)
+		low = math.Min(low, c.Low)
+	}
+	if high <= low {
+		return nil
+	}
+
+	binSize := (high - low) / float64(bins)
+	volumes := make([]float64, bins)
+	totalVolume := 0.0
+
+	for _, c := range window {
+		if c.Volume <= 0 {
+			continue
+		}
+		totalVolume += c.Volume
+
+		candleRange := c.High - c.Low
+		if candleRange <= 0 {
+			// Zero-range candle: all volume goes to the bin containing the price
+			volumes[priceToBin(c.Close, low, binSize, bins)] += c.Volume
+			continue
+		}
+
+		firstBin := priceToBin(c.Low, low, binSize, bins)
+		lastBin := priceToBin(c.High, low, binSize, bins)
+		for b := firstBin; b <= lastBin; b++ {
+			binLow := low + float64(b)*binSize
+			binHigh := binLow + binSize
+			overlap := math.Min(c.High, binHigh) - math.Max(c.Low, binLow)
+			if overlap > 0 {
+				volumes[b] += c.Volume * overlap / candleRange
+			}
+		}
+	}
+
+	if totalVolume == 0 {
+		return nil
+	}
+
+	// Point of control: bin with the highest traded volume
+	pocIdx := 0
+	for i := 1; i < bins; i++ {
+		if volumes[i] > volumes[pocIdx] {
+			pocIdx = i
+		}
+	}
+
+	valLow, valHigh := calculateValueArea(volumes, pocIdx, totalVolume*valueAreaPct)
+
+	profileBins := make([]model.VolumeBin, bins)
+	for i := 0; i < bins; i++ {
+		binLow := low + float64(i)*binSize
+		profileBins[i] = model.VolumeBin{
+			PriceLow:  binLow,
+			PriceHigh: binLow + binSize,
+			Volume:    volumes[i],
+		}
+	}
+
+	hvn, lvn := findVolumeNodes(volumes, low, binSize, pocIdx)
+
+	return &model.VolumeProfile{
+		Mode:             config.Mode,
+		High:             high,
+		Low:              low,
+		TotalVolume:      totalVolume,
+		POC:              low + (float64(pocIdx)+0.5)*binSize,
+		ValueAreaHigh:    low + float64(valHigh+1)*binSize,
+		ValueAreaLow:     low + float64(valLow)*binSize,
+		HighVolumeNodes:  hvn,
+		LowVolumeNodes:   lvn,
+		Bins:             profileBins,
+		CandleCount:      len(window),
+		ValueAreaPercent: valueAreaPct * 100,
+	}
+}
+
+// priceToBin maps a price into a bin index, clamped to the valid range
+func priceToBin(price, low, binSize float64, bins int) int {
+	idx := int((price - low) / binSize)
+	if idx < 0 {
+		return 0
+	}
+	if idx >= bins {
+		return bins - 1
+	}
+	return idx
+}
+
+// calculateValueArea expands outward from the POC, always adding the heavier neighbouring bin,
+// until the target volume is covered. Returns the inclusive bin range.
+func calculateValueArea(volumes []float64, pocIdx int, target float64) (int, int) {
+	lowIdx, highIdx := pocIdx, pocIdx
+	covered := volumes[pocIdx]
+
+	for covered < target && (lowIdx > 0 || highIdx < len(volumes)-1) {
+		below := -1.0
+		above := -1.0
+		if lowIdx > 0 {
+			below = volumes[lowIdx-1]
+		}
+		if highIdx < len(volumes)-1 {
+			above = volumes[highIdx+1]
+		}
+
+		if above >= below {
+			highIdx++
+			covered += above
+		} else {
+			lowIdx--
+			covered += below
+		}
+	}
+
+	return lowIdx, highIdx
+}
+
+// findVolumeNodes returns high volume nodes (local peaks above average volume)
+// and low volume nodes (local troughs well below average volume), as bin mid prices
+func findVolumeNodes(volumes []float64, low, binSize float64, pocIdx int) ([]float64, []float64) {
+	hvn := make([]float64, 0)
+	lvn := make([]float64, 0)
+
+	avgVolume := average(volumes)
+	for i := 1; i < len(volumes)-1; i++ {
+		mid := low + (float64(i)+0.5)*binSize
+
+		// The POC is reported separately
+		if i != pocIdx && volumes[i] >= volumes[i-1] && volumes[i] >= volumes[i+1] && volumes[i] > avgVolume*1.2 {
+			hvn = append(hvn, mid)
+		}
+		if volumes[i] <= volumes[i-1] && volumes[i] <= volumes[i+1] && volumes[i] < avgVolume*0.5 {
+			lvn = append(lvn, mid)
+		}
+	}
+
+	return hvn, lvn
+}
@@ -0,0 +1,50 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+func TestCalculateVolumeProfile(t *testing.T) {
+	// Candle i spans exactly bin i of a 0-10 range, so the histogram equals the volumes
+	volumes := []float64{1, 2, 3, 5, 10, 20, 8, 2, 9, 1}
+	candles := make([]model.Candle, len(volumes))
+	for i, v := range volumes {
+		p := float64(i)
+		candles[i] = model.Candle{Timestamp: int64(i) * 3600000, Open: p, High: p + 1, Low: p, Close: p + 1, Volume: v}
+	}
+
+	config := DefaultVolumeProfileConfig()
+	config.Bins = 10
+	profile := CalculateVolumeProfile(candles, config)
+	if profile == nil {
+		t.Fatal("expected a profile")
+	}
+
+	for i, bin := range profile.Bins {
+		if math.Abs(bin.Volume-volumes[i]) > 1e-9 {
+			t.Errorf("bin %d: volume %v, want %v", i, bin.Volume, volumes[i])
+		}
+	}
+	if profile.TotalVolume != 61 || profile.POC != 5.5 {
+		t.Errorf("total %v POC %v, want 61 and 5.5", profile.TotalVolume, profile.POC)
+	}
+	// From the POC: 10 below, 8 above, then 5 below reaches 43 >= 70% of 61
+	if profile.ValueAreaLow != 3 || profile.ValueAreaHigh != 7 {
+		t.Errorf("value area %v-%v, want 3-7", profile.ValueAreaLow, profile.ValueAreaHigh)
+	}
+	if len(profile.HighVolumeNodes) != 1 || profile.HighVolumeNodes[0] != 8.5 {
+		t.Errorf("HVN %v, want [8.5]", profile.HighVolumeNodes)
+	}
+	if len(profile.LowVolumeNodes) != 1 || profile.LowVolumeNodes[0] != 7.5 {
+		t.Errorf("LVN %v, want [7.5]", profile.LowVolumeNodes)
+	}
+
+	config.Mode = "fixed"
+	config.Lookback = 4
+	if fixed := CalculateVolumeProfile(candles, config); fixed == nil || fixed.CandleCount != 4 || fixed.Low != 6 {
+		t.Errorf("fixed range profile %+v, want the last 4 candles", fixed)
+	}
+}
@@ -50,20 +50,150 @@ type FibonacciLevels struct {
 	Direction   string             `json:"direction"`   // "UPTREND" or "DOWNTREND"
 }
 
+// FibonacciConfluence represents Fibonacci levels from several swing pairs and where they overlap
+type FibonacciConfluence struct {
+	Pairs     []FibSwingPair `json:"pairs"`
+	Clusters  []FibCluster   `json:"clusters"`
+	TimeZones []FibTimeZone  `json:"time_zones"`
+}
+
+// FibSwingPair represents a swing-to-swing move that Fibonacci ratios are measured on
+type FibSwingPair struct {
+	ID        int        `json:"id"`
+	From      SwingPoint `json:"from"`
+	To        SwingPoint `json:"to"`
+	Direction string     `json:"direction"` // "UP" or "DOWN"
+}
+
+// FibLevelRef represents one Fibonacci level of a swing pair
+type FibLevelRef struct {
+	PairID int     `json:"pair_id"`
+	Kind   string  `json:"kind"` // "RETRACEMENT" or "EXTENSION"
+	Ratio  float64 `json:"ratio"`
+	Price  float64 `json:"price"`
+}
+
+// FibCluster represents overlapping Fibonacci levels from different swing pairs
+type FibCluster struct {
+	Price     float64       `json:"price"` // Average of the member levels
+	PriceLow  float64       `json:"price_low"`
+	PriceHigh float64       `json:"price_high"`
+	Levels    []FibLevelRef `json:"levels"`
+	PairIDs   []int         `json:"pair_ids"` // Swing pairs that produced the cluster
+	Strength  float64       `json:"strength"` // 0-1
+	Type      string        `json:"type"`     // "SUPPORT" or "RESISTANCE"
+}
+
+// FibTimeZone represents a Fibonacci bar count projected from a swing
+type FibTimeZone struct {
+	Bars      int   `json:"bars"`      // Fibonacci number of bars after the anchor
+	Index     int   `json:"index"`     // Candle index (may be beyond the data)
+	Timestamp int64 `json:"timestamp"` // Projected turn time
+	Future    bool  `json:"future"`
+	Anchor    int64 `json:"anchor"` // Anchor swing timestamp
+}
+
+// RealizedVolatility represents annualized realized volatility estimates (percent)
+type RealizedVolatility struct {
+	Interval     string  `json:"interval"`
+	Window       int     `json:"window"`         // Bars per estimate
+	CloseToClose float64 `json:"close_to_close"` // Annualized %
+	Parkinson    float64 `json:"parkinson"`      // Annualized %
+	GarmanKlass  float64 `json:"garman_klass"`   // Annualized %
+	YangZhang    float64 `json:"yang_zhang"`     // Annualized %
+	Percentile   float64 `json:"percentile"`     // Yang-Zhang percentile vs stored and own history, 0-100
+	HistorySize  int     `json:"history_size"`   // Rolling windows ranked
+}
+
+// VolumeBin represents traded volume within a price bin
+type VolumeBin struct {
+	PriceLow  float64 `json:"price_low"`
+	PriceHigh float64 `json:"price_high"`
+	Volume    float64 `json:"volume"`
+}
+
+// VolumeProfile represents volume distribution by price over a window
+type VolumeProfile struct {
+	Mode             string      `json:"mode"`               // "fixed", "session", "visible"
+	High             float64     `json:"high"`               // Highest price in window
+	Low              float64     `json:"low"`                // Lowest price in window
+	TotalVolume      float64     `json:"total_volume"`       // Volume traded in window
+	POC              float64     `json:"poc"`                // Point of control
+	ValueAreaHigh    float64     `json:"value_area_high"`    // VAH
+	ValueAreaLow     float64     `json:"value_area_low"`     // VAL
+	ValueAreaPercent float64     `json:"value_area_percent"` // Share of volume in value area (%)
+	HighVolumeNodes  []float64   `json:"high_volume_nodes"`  // HVN prices
+	LowVolumeNodes   []float64   `json:"low_volume_nodes"`   // LVN prices
+	Bins             []VolumeBin `json:"bins"`
+	CandleCount      int         `json:"candle_count"` // Candles in window
+}
+
+// PivotPoints represents pivot levels computed from the prior higher-timeframe candle
+type PivotPoints struct {
+	Timeframe string             `json:"timeframe"` // Source timeframe ("1d", "1w", "1M")
+	Timestamp int64              `json:"timestamp"` // Open time of the source candle
+	High      float64            `json:"high"`
+	Low       float64            `json:"low"`
+	Close     float64            `json:"close"`
+	Classic   map[string]float64 `json:"classic"`
+	Fibonacci map[string]float64 `json:"fibonacci"`
+	Camarilla map[string]float64 `json:"camarilla"`
+	Woodie    map[string]float64 `json:"woodie"`
+	DeMark    map[string]float64 `json:"demark"`
+}
+
 // Indicators contains all technical indicators
 type Indicators struct {
-	MACD      MACDIndicator    `json:"macd"`
-	KDJ       KDJIndicator     `json:"kdj"`
-	RSI       RSIIndicator     `json:"rsi"`
-	ATR       ATRIndicator     `json:"atr"`
-	EMA       EMAIndicator     `json:"ema"`
-	Fibonacci *FibonacciLevels `json:"fibonacci,omitempty"`
+	MACD          MACDIndicator        `json:"macd"`
+	KDJ           KDJIndicator         `json:"kdj"`
+	RSI           RSIIndicator         `json:"rsi"`
+	ATR           ATRIndicator         `json:"atr"`
+	EMA           EMAIndicator         `json:"ema"`
+	Fibonacci     *FibonacciLevels     `json:"fibonacci,omitempty"`
+	FibConfluence *FibonacciConfluence `json:"fibonacci_confluence,omitempty"` // Multi-swing clusters and time zones
+	VolumeProfile *VolumeProfile       `json:"volume_profile,omitempty"`
+	Pivots        *PivotPoints         `json:"pivots,omitempty"`
+	Volatility    *RealizedVolatility  `json:"realized_volatility,omitempty"`
+	Sessions      *SessionAnalysis     `json:"sessions,omitempty"`
 }
 
 // SRLevel represents a support or resistance level
 type SRLevel struct {
 	Price    float64 `json:"price"`
-	Strength float64 `json:"strength"` // 0-1, 强度
+	Strength float64 `json:"strength"`         // 0-1, 强度
+	Source   string  `json:"source,omitempty"` // "PRICE_CLUSTER", "SWING", "VOLUME_POC", "VOLUME_HVN"
+
+	// Zone history
+	Lower          float64 `json:"lower,omitempty"`        // Zone lower bound
+	Upper          float64 `json:"upper,omitempty"`        // Zone upper bound
+	Touches        int     `json:"touches,omitempty"`      // Separate visits into the zone
+	FirstTouch     int64   `json:"first_touch,omitempty"`  // Timestamp of the first visit
+	LastTouch      int64   `json:"last_touch,omitempty"`   // Timestamp of the latest candle in the zone
+	AgeBars        int     `json:"age_bars,omitempty"`     // Bars since the first touch
+	BarsSinceTouch int     `json:"bars_since_touch"`       // Bars since the last touch
+	VolumeScore    float64 `json:"volume_score,omitempty"` // 0-1, touch volume ranked against the symbol's own candles
+	Role           string  `json:"role,omitempty"`         // "SUPPORT" or "RESISTANCE" relative to the current price
+	OriginRole     string  `json:"origin_role,omitempty"`  // Role when first touched
+	Breaks         int     `json:"breaks,omitempty"`       // Closes through the whole zone
+	BrokenAt       int64   `json:"broken_at,omitempty"`    // Timestamp of the latest break
+	Flipped        bool    `json:"flipped,omitempty"`      // Broken and now acting in the opposite role
+
+	// Multi-timeframe merge
+	Timeframe  string   `json:"timeframe,omitempty"`  // Timeframe the level was detected on
+	Timeframes []string `json:"timeframes,omitempty"` // Timeframes that agree on the level
+}
+
+// SRZone is a support/resistance zone persisted with the state needed to keep replaying candles into it
+type SRZone struct {
+	ID       int64  `json:"id"`
+	Symbol   string `json:"symbol"`
+	Interval string `json:"interval"`
+	SRLevel
+	Side        int     `json:"-"` // Side of the latest close outside the zone: 1 above, -1 below, 0 unknown
+	InZone      bool    `json:"-"` // Whether the latest candle overlapped the zone
+	Through     int64   `json:"-"` // Timestamp of the latest candle replayed
+	TouchVolume float64 `json:"-"` // Volume of the candles that overlapped the zone
+	TouchBars   int     `json:"-"` // Candles that overlapped the zone
 }
 
 // SRLevels contains support and resistance levels
@@ -78,8 +208,148 @@ type CandlestickPattern struct {
 	Type        string  `json:"type"`        // "反转" or "持续"
 	Direction   string  `json:"direction"`   // "看涨" or "看跌"
 	Position    int     `json:"position"`    // 相对当前K线的位置
+	Timestamp   int64   `json:"timestamp"`   // 形态完成K线的时间
 	Reliability float64 `json:"reliability"` // 0-1, 可靠性
 	Description string  `json:"description"` // 描述
+
+	// Calibration from stored history, set when statistics exist for the symbol and interval
+	PriorReliability     float64 `json:"prior_reliability,omitempty"`     // Hardcoded reliability (0-1)
+	EmpiricalReliability float64 `json:"empirical_reliability,omitempty"` // Calibrated reliability (0-1)
+	SampleSize           int     `json:"sample_size,omitempty"`           // Historical occurrences behind the calibration
+
+	// Confirmation by the candles after completion; unset for neutral patterns
+	Confirmation      string  `json:"confirmation,omitempty"`       // "PENDING", "CONFIRMED" or "FAILED"
+	ConfirmationPrice float64 `json:"confirmation_price,omitempty"` // Close beyond this confirms
+	InvalidationPrice float64 `json:"invalidation_price,omitempty"` // Close beyond this fails
+	ResolvedAt        int64   `json:"resolved_at,omitempty"`        // Candle that confirmed or failed the pattern
+}
+
+// TrackedPattern is a candlestick pattern persisted for confirmation tracking
+type TrackedPattern struct {
+	Symbol   string `json:"symbol"`
+	Interval string `json:"interval"`
+	Bars     int    `json:"bars"` // Candles in the pattern
+	CandlestickPattern
+}
+
+// Swing point types
+const (
+	SwingHigh = "HIGH"
+	SwingLow  = "LOW"
+)
+
+// SwingPoint represents a swing high or low produced by the swing engine
+type SwingPoint struct {
+	Index     int     `json:"index"`     // Candle index
+	Timestamp int64   `json:"timestamp"` // Candle timestamp
+	Price     float64 `json:"price"`
+	Type      string  `json:"type"`            // "HIGH" or "LOW"
+	Confirmed bool    `json:"confirmed"`       // False for the extreme of the open leg
+	Label     string  `json:"label,omitempty"` // "HH", "HL", "LH", "LL" once labeled
+}
+
+// StructureEvent represents a break of structure (BOS) or change of character (CHoCH)
+type StructureEvent struct {
+	Type           string  `json:"type"`      // "BOS" or "CHOCH"
+	Direction      string  `json:"direction"` // "BULLISH" or "BEARISH"
+	Level          float64 `json:"level"`     // Broken swing price
+	SwingLabel     string  `json:"swing_label,omitempty"`
+	SwingIndex     int     `json:"swing_index"`
+	SwingTimestamp int64   `json:"swing_timestamp"`
+	BreakIndex     int     `json:"break_index"` // Candle that closed beyond the level
+	BreakTimestamp int64   `json:"break_timestamp"`
+	BreakClose     float64 `json:"break_close"`
+}
+
+// PatternPoint represents an anchor point of a chart pattern
+type PatternPoint struct {
+	Label     string  `json:"label"` // e.g. "LS", "HEAD", "RS", "NL1"
+	Index     int     `json:"index"` // Candle index in the analyzed window
+	Timestamp int64   `json:"timestamp"`
+	Price     float64 `json:"price"`
+}
+
+// Trendline is a sloped line fitted through swing highs (resistance) or swing lows (support)
+type Trendline struct {
+	Type         string          `json:"type"`      // "SUPPORT" or "RESISTANCE"
+	Direction    string          `json:"direction"` // "UP", "DOWN" or "FLAT"
+	Touches      []PatternPoint  `json:"touches"`   // Swings on the line, oldest first
+	Slope        float64         `json:"slope"`     // Price change per bar
+	StartIndex   int             `json:"start_index"`
+	Violations   int             `json:"violations"`    // Closes through the line between its first and last touch
+	Score        float64         `json:"score"`         // 0-1
+	CurrentPrice float64         `json:"current_price"` // Line projected to the last candle
+	Broken       bool            `json:"broken"`
+	Break        *TrendlineBreak `json:"break,omitempty"`
+}
+
+// TrendlineBreak is a close through a trendline after its last touch
+type TrendlineBreak struct {
+	LineType  string  `json:"line_type"` // "SUPPORT" or "RESISTANCE"
+	Direction string  `json:"direction"` // "UP" (close above) or "DOWN" (close below)
+	Index     int     `json:"index"`
+	Timestamp int64   `json:"timestamp"`
+	Close     float64 `json:"close"`
+	LinePrice float64 `json:"line_price"` // Line value at the break candle
+}
+
+// TrendChannel is a pair of roughly parallel support and resistance trendlines
+type TrendChannel struct {
+	Direction string    `json:"direction"` // "UP", "DOWN" or "FLAT"
+	Upper     Trendline `json:"upper"`
+	Lower     Trendline `json:"lower"`
+	Width     float64   `json:"width"`    // Upper minus lower at the last candle
+	Position  float64   `json:"position"` // 0 at the lower line, 1 at the upper line
+	Score     float64   `json:"score"`    // 0-1
+}
+
+// TrendlineAnalysis groups detected trendlines, channels and break events
+type TrendlineAnalysis struct {
+	Lines       []Trendline      `json:"lines"`
+	Channels    []TrendChannel   `json:"channels"`
+	BreakEvents []TrendlineBreak `json:"break_events"` // Oldest first
+}
+
+// ChartPattern represents a multi-bar classical chart pattern
+type ChartPattern struct {
+	Pattern           string         `json:"pattern"`            // 形态名称
+	Code              string         `json:"code"`               // e.g. "HEAD_AND_SHOULDERS", "DOUBLE_TOP"
+	Type              string         `json:"type"`               // "反转" or "持续"
+	Direction         string         `json:"direction"`          // "看涨" or "看跌"
+	Points            []PatternPoint `json:"points"`             // Anchor points for drawing
+	Neckline          []PatternPoint `json:"neckline,omitempty"` // Neckline/boundary line points
+	BreakoutLevel     float64        `json:"breakout_level"`     // Breakout level at the latest candle
+	InvalidationLevel float64        `json:"invalidation_level"` // Level that invalidates the pattern
+	Target            float64        `json:"target"`             // Measured-move target
+	Status            string         `json:"status"`             // "FORMING", "CONFIRMED", "FAILED"
+	BreakoutIndex     int            `json:"breakout_index"`     // Candle index of breakout (-1 if none)
+	Reliability       float64        `json:"reliability"`        // 0-1
+}
+
+// HarmonicPattern represents an XABCD harmonic pattern
+type HarmonicPattern struct {
+	Pattern     string             `json:"pattern"`     // "Gartley", "Bat", "Butterfly", "Crab"
+	Direction   string             `json:"direction"`   // "看涨" or "看跌"
+	Status      string             `json:"status"`      // "FORMING" or "COMPLETED"
+	Points      []PatternPoint     `json:"points"`      // X, A, B, C, D (D index -1 when projected)
+	Ratios      map[string]float64 `json:"ratios"`      // Measured Fibonacci ratios
+	PRZ         [2]float64         `json:"prz"`         // Potential reversal zone [low, high]
+	ProjectedD  float64            `json:"projected_d"` // Ideal D price
+	StopLoss    float64            `json:"stop_loss"`
+	Targets     []float64          `json:"targets"`     // 38.2% and 61.8% AD retracements
+	Score       float64            `json:"score"`       // 0-1, ratio accuracy
+	Reliability float64            `json:"reliability"` // 0-1
+}
+
+// MarketRegime represents the classified market regime
+type MarketRegime struct {
+	Regime        string             `json:"regime"`         // "TRENDING", "RANGING", "VOLATILE", "QUIET", "UNKNOWN"
+	Probabilities map[string]float64 `json:"probabilities"`  // Regime -> probability (sums to 1)
+	ADX           float64            `json:"adx"`            // Average Directional Index (14)
+	Choppiness    float64            `json:"choppiness"`     // Choppiness index (14), 0-100
+	Hurst         float64            `json:"hurst"`          // Hurst exponent estimated from the variance ratio
+	VarianceRatio float64            `json:"variance_ratio"` // Lo-MacKinlay variance ratio (q=4)
+	ATRPercentile float64            `json:"atr_percentile"` // Current ATR% percentile vs own history, 0-100
 }
 
 // MarketStructure represents comprehensive market structure analysis
@@ -90,12 +360,70 @@ type MarketStructure struct {
 	StructureBreak bool   `json:"structure_break"` // 结构是否被破坏
 	RiskLevel      string `json:"risk_level"`      // "低" / "中" / "高"
 
+	// Structure timeline
+	Swings              []SwingPoint     `json:"swings"`                          // Labeled HH/HL/LH/LL swing sequence
+	StructureEvents     []StructureEvent `json:"structure_events"`                // BOS / CHoCH timeline, oldest first
+	StructureBreakEvent *StructureEvent  `json:"structure_break_event,omitempty"` // Event behind StructureBreak
+
 	// Enhanced Multi-Indicator Analysis
 	TrendConfirmation  TrendConfirmation  `json:"trend_confirmation"`
 	VolatilityProfile  VolatilityProfile  `json:"volatility_profile"`
 	KeyLevelConfluence KeyLevelConfluence `json:"key_level_confluence"`
 	PatternSignals     PatternSignals     `json:"pattern_signals"`
 	MarketQuality      MarketQuality      `json:"market_quality"`
+
+	// Smart-money concepts
+	SmartMoney SmartMoney `json:"smart_money"`
+}
+
+// SmartMoney aggregates smart-money concept structures
+type SmartMoney struct {
+	OrderBlocks     []OrderBlock     `json:"order_blocks"`
+	FairValueGaps   []FairValueGap   `json:"fair_value_gaps"`
+	LiquidityPools  []LiquidityPool  `json:"liquidity_pools"`
+	LiquiditySweeps []LiquiditySweep `json:"liquidity_sweeps"`
+}
+
+// OrderBlock represents the last opposite candle before a structure-breaking displacement
+type OrderBlock struct {
+	Type      string  `json:"type"` // "BULLISH" or "BEARISH"
+	High      float64 `json:"high"`
+	Low       float64 `json:"low"`
+	Index     int     `json:"index"`
+	Timestamp int64   `json:"timestamp"`
+	Mitigated bool    `json:"mitigated"` // Price has returned into the block
+	Strength  float64 `json:"strength"`  // 0-1
+}
+
+// FairValueGap represents a three-candle imbalance
+type FairValueGap struct {
+	Type        string  `json:"type"` // "BULLISH" or "BEARISH"
+	High        float64 `json:"high"`
+	Low         float64 `json:"low"`
+	Index       int     `json:"index"` // Middle candle
+	Timestamp   int64   `json:"timestamp"`
+	FillPercent float64 `json:"fill_percent"` // 0-100
+	Filled      bool    `json:"filled"`
+}
+
+// LiquidityPool represents equal highs or lows where resting liquidity sits
+type LiquidityPool struct {
+	Type    string  `json:"type"` // "EQUAL_HIGHS" or "EQUAL_LOWS"
+	Price   float64 `json:"price"`
+	Touches int     `json:"touches"`
+	Indices []int   `json:"indices"`
+	Swept   bool    `json:"swept"`
+}
+
+// LiquiditySweep represents a wick through an SR level that closed back inside
+type LiquiditySweep struct {
+	Type        string  `json:"type"` // "BUY_SIDE" (above resistance) or "SELL_SIDE" (below support)
+	Level       float64 `json:"level"`
+	LevelSource string  `json:"level_source,omitempty"`
+	Index       int     `json:"index"`
+	Timestamp   int64   `json:"timestamp"`
+	WickPrice   float64 `json:"wick_price"`
+	Close       float64 `json:"close"`
 }
 
 // TrendConfirmation analyzes trend strength using multiple indicators
@@ -111,7 +439,9 @@ type TrendConfirmation struct {
 type VolatilityProfile struct {
 	CurrentATR      float64 `json:"current_atr"`
 	ATRPercentage   float64 `json:"atr_percentage"`   // ATR as % of price
-	VolatilityLevel string  `json:"volatility_level"` // "HIGH", "NORMAL", "LOW"
+	VolatilityLevel string  `json:"volatility_level"` // "HIGH", "NORMAL", "LOW" from the percentile
+	RealizedVol     float64 `json:"realized_vol"`     // Annualized Yang-Zhang volatility (%)
+	VolPercentile   float64 `json:"vol_percentile"`   // Realized volatility percentile vs own history
 	IsExpanding     bool    `json:"is_expanding"`     // Volatility trend
 	RiskAdjustment  string  `json:"risk_adjustment"`  // Suggested position sizing
 }
@@ -175,9 +505,16 @@ type AnalysisResult struct {
 	Interval            string               `json:"interval"`
 	Timestamp           int64                `json:"timestamp"`
 	Trend               TrendAnalysis        `json:"trend"`
+	Regime              MarketRegime         `json:"regime"`
 	Indicators          Indicators           `json:"indicators"`
 	SRLevels            SRLevels             `json:"sr_levels"`
 	CandlestickPatterns []CandlestickPattern `json:"candlestick_patterns"`
+	PatternHistory      []CandlestickPattern `json:"pattern_history,omitempty"` // Whole-window scan, opt-in
+	TrackedPatterns     []CandlestickPattern `json:"tracked_patterns"`          // Persisted recent patterns with confirmation state
+	ChartPatterns       []ChartPattern       `json:"chart_patterns"`
+	HarmonicPatterns    []HarmonicPattern    `json:"harmonic_patterns"`
+	Trendlines          TrendlineAnalysis    `json:"trendlines"`
+	RelativeStrength    *RelativeStrength    `json:"relative_strength,omitempty"` // Ratio analysis against a benchmark, opt-in
 	MarketStructure     MarketStructure      `json:"market_structure"`
 }
 
@@ -0,0 +1,28 @@
+package model
+
+// PatternStats holds the empirical forward performance of a candlestick pattern on one symbol and interval
+type PatternStats struct {
+	Symbol               string  `json:"symbol"`
+	Interval             string  `json:"interval"`
+	Pattern              string  `json:"pattern"`
+	Direction            string  `json:"direction"`             // "看涨" or "看跌"
+	Horizon              int     `json:"horizon"`               // Forward bars measured
+	Samples              int     `json:"samples"`               // Occurrences with a full forward window
+	Hits                 int     `json:"hits"`                  // Occurrences that moved in the pattern's direction
+	HitRate              float64 `json:"hit_rate"`              // 0-1
+	AvgReturn            float64 `json:"avg_return"`            // Mean direction-aligned forward return (%)
+	PriorReliability     float64 `json:"prior_reliability"`     // Hardcoded reliability (0-1)
+	EmpiricalReliability float64 `json:"empirical_reliability"` // Hit rate shrunk toward the prior (0-1)
+	UpdatedAt            int64   `json:"updated_at"`
+}
+
+// CalibrationResult is the outcome of a calibration run over stored history
+type CalibrationResult struct {
+	Symbol   string         `json:"symbol"`
+	Interval string         `json:"interval"`
+	Horizon  int            `json:"horizon"`
+	Candles  int            `json:"candles"` // Stored candles scanned
+	From     int64          `json:"from"`    // First candle timestamp
+	To       int64          `json:"to"`      // Last candle timestamp
+	Stats    []PatternStats `json:"stats"`
+}
@@ -0,0 +1,29 @@
+package model
+
+// CorrelationMatrix holds return correlations between symbols and each symbol's beta to a benchmark
+type CorrelationMatrix struct {
+	Interval     string       `json:"interval"`
+	Window       int          `json:"window"`       // Returns per correlation window
+	Benchmark    string       `json:"benchmark"`    // Symbol betas are measured against
+	Symbols      []string     `json:"symbols"`      // Row and column order of Matrix
+	Matrix       [][]float64  `json:"matrix"`       // Pearson correlation of log returns over the latest window
+	Betas        []SymbolBeta `json:"betas"`        // One per symbol, in Symbols order
+	Observations int          `json:"observations"` // Aligned candles across all symbols
+	From         int64        `json:"from"`         // First aligned timestamp
+	To           int64        `json:"to"`           // Last aligned timestamp
+}
+
+// SymbolBeta is a symbol's correlation and beta to the benchmark, latest and rolling
+type SymbolBeta struct {
+	Symbol      string        `json:"symbol"`
+	Correlation float64       `json:"correlation"`
+	Beta        float64       `json:"beta"`
+	Rolling     []RollingBeta `json:"rolling"` // One point per aligned candle once a full window is available
+}
+
+// RollingBeta is the correlation and beta to the benchmark over the window ending at Timestamp
+type RollingBeta struct {
+	Timestamp   int64   `json:"timestamp"`
+	Correlation float64 `json:"correlation"`
+	Beta        float64 `json:"beta"`
+}
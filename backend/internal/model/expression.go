@@ -0,0 +1,26 @@
+package model
+
+// SavedExpression represents a named custom indicator expression
+type SavedExpression struct {
+	Name        string `json:"name"`
+	Expression  string `json:"expression"`
+	Description string `json:"description"`
+	CreatedAt   int64  `json:"created_at"`
+	UpdatedAt   int64  `json:"updated_at"`
+}
+
+// ExpressionPoint represents one value of an evaluated expression
+type ExpressionPoint struct {
+	Timestamp int64    `json:"timestamp"`
+	Value     *float64 `json:"value"` // null when undefined (e.g. indicator warm-up)
+}
+
+// ExpressionResult represents an expression evaluated over a candle series
+type ExpressionResult struct {
+	Symbol     string            `json:"symbol"`
+	Interval   string            `json:"interval"`
+	Name       string            `json:"name,omitempty"`
+	Expression string            `json:"expression"`
+	Series     []ExpressionPoint `json:"series"`
+	Latest     *float64          `json:"latest"`
+}
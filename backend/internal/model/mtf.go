@@ -0,0 +1,42 @@
+package model
+
+// MTFAnalysis holds one symbol's analysis on several timeframes and how well they agree
+type MTFAnalysis struct {
+	Symbol     string             `json:"symbol"`
+	Timeframes []TimeframeResult  `json:"timeframes"` // In request order
+	Alignment  TimeframeAlignment `json:"alignment"`  // Over the timeframes that succeeded
+}
+
+// TimeframeResult is the analysis of one timeframe, or the error that prevented it
+type TimeframeResult struct {
+	Interval string          `json:"interval"`
+	Result   *AnalysisResult `json:"result,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// TimeframeAlignment scores the agreement between timeframes
+type TimeframeAlignment struct {
+	Score          float64         `json:"score"`           // 0-100
+	Direction      string          `json:"direction"`       // "BULLISH", "BEARISH", "NEUTRAL"
+	TrendAgreement float64         `json:"trend_agreement"` // 0-1, net share of timeframes trending the same way
+	EMAAgreement   float64         `json:"ema_agreement"`   // 0-1, net share of timeframes with the same EMA stack
+	LevelAgreement float64         `json:"level_agreement"` // 0-1, share of timeframes whose nearest level another timeframe shares
+	Biases         []TimeframeBias `json:"biases"`
+	SharedLevels   []SharedLevel   `json:"shared_levels"`
+}
+
+// TimeframeBias is the trend and EMA stack direction of one timeframe
+type TimeframeBias struct {
+	Interval      string  `json:"interval"`
+	Trend         string  `json:"trend"` // "上升" / "下降" / "盘整"
+	TrendStrength float64 `json:"trend_strength"`
+	TrendSign     int     `json:"trend_sign"`    // 1 up, -1 down, 0 sideways
+	EMAAlignment  int     `json:"ema_alignment"` // 1 bullish stack, -1 bearish stack, 0 mixed
+}
+
+// SharedLevel is a nearest confluence level that several timeframes agree on
+type SharedLevel struct {
+	Price      float64  `json:"price"`
+	Type       string   `json:"type"` // "SUPPORT" or "RESISTANCE"
+	Timeframes []string `json:"timeframes"`
+}
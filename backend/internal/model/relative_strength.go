@@ -0,0 +1,24 @@
+package model
+
+// RelativeStrength measures a symbol against a benchmark by analysing the ratio series symbol/benchmark
+type RelativeStrength struct {
+	Benchmark     string        `json:"benchmark"`
+	Ratio         float64       `json:"ratio"`         // Current symbol/benchmark price ratio
+	Change        float64       `json:"change"`        // % change of the ratio over the window
+	Score         float64       `json:"score"`         // Blended % change of the ratio over 10/30/90 bars
+	Outperforming bool          `json:"outperforming"` // Positive score with the ratio above its EMA21
+	Trend         TrendAnalysis `json:"trend"`         // Trend of the ratio
+	EMA           EMAIndicator  `json:"ema"`           // EMAs of the ratio
+	SRLevels      SRLevels      `json:"sr_levels"`     // SR levels of the ratio
+	Rank          int           `json:"rank"`          // 1 = strongest in the watchlist, 0 when unranked
+	Percentile    float64       `json:"percentile"`    // Share of the watchlist this symbol outperforms, 0-100
+	Watchlist     []RSRank      `json:"watchlist"`     // Watchlist ranked by score, strongest first
+}
+
+// RSRank is one watchlist symbol's relative strength against the benchmark
+type RSRank struct {
+	Symbol string  `json:"symbol"`
+	Score  float64 `json:"score"`
+	Change float64 `json:"change"`
+	Rank   int     `json:"rank"`
+}
@@ -0,0 +1,24 @@
+package model
+
+// ScanResponse is one page of a market scan: the analyses that passed the filter, in sort order
+type ScanResponse struct {
+	Interval   string            `json:"interval"`
+	Universe   []string          `json:"universe"` // Symbols scanned
+	Scanned    int               `json:"scanned"`  // Symbols analysed successfully
+	Matched    int               `json:"matched"`  // Analyses that passed the filter
+	Filter     string            `json:"filter,omitempty"`
+	Sort       string            `json:"sort,omitempty"`
+	Order      string            `json:"order"` // "asc" or "desc"
+	Page       int               `json:"page"`  // 1-based
+	PageSize   int               `json:"page_size"`
+	TotalPages int               `json:"total_pages"`
+	ScannedAt  int64             `json:"scanned_at"` // When the cached scan ran (ms)
+	Results    []*AnalysisResult `json:"results"`
+	Failed     []ScanFailure     `json:"failed"`
+}
+
+// ScanFailure is a symbol whose analysis failed during a scan
+type ScanFailure struct {
+	Symbol string `json:"symbol"`
+	Error  string `json:"error"`
+}
@@ -0,0 +1,28 @@
+package model
+
+// Seasonality holds per-candle return, range and volume statistics grouped by calendar bucket
+type Seasonality struct {
+	Symbol   string              `json:"symbol"`
+	Interval string              `json:"interval"`
+	Timezone string              `json:"timezone"` // IANA zone the buckets are computed in
+	Candles  int                 `json:"candles"`  // Closed candles measured
+	From     int64               `json:"from"`     // First candle timestamp
+	To       int64               `json:"to"`       // Last candle timestamp
+	Hours    []SeasonalityBucket `json:"hours"`    // Hour of day 0-23, buckets without candles omitted
+	Weekdays []SeasonalityBucket `json:"weekdays"` // Day of week, 0 = Sunday
+	Months   []SeasonalityBucket `json:"months"`   // Month 1-12
+}
+
+// SeasonalityBucket aggregates the candles opening in one hour, weekday or month
+type SeasonalityBucket struct {
+	Key            int     `json:"key"`
+	Label          string  `json:"label"`
+	Samples        int     `json:"samples"`
+	AvgReturn      float64 `json:"avg_return"`      // Mean close-to-open return (%)
+	WinRate        float64 `json:"win_rate"`        // Share of candles closing above their open (0-1)
+	AvgRange       float64 `json:"avg_range"`       // Mean high-low range as % of the open
+	AvgVolume      float64 `json:"avg_volume"`      // Mean base-asset volume
+	RelativeRange  float64 `json:"relative_range"`  // AvgRange over the average of all candles
+	RelativeVolume float64 `json:"relative_volume"` // AvgVolume over the average of all candles
+	Dead           bool    `json:"dead"`            // Both range and volume well below average
+}
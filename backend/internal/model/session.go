@@ -0,0 +1,43 @@
+package model
+
+// SessionAnalysis holds the ranges and sweeps of the configured trading sessions
+type SessionAnalysis struct {
+	Timeframe string           `json:"timeframe"` // Candle interval the sessions were built from
+	Active    []string         `json:"active"`    // Sessions open at the latest candle
+	Sessions  []TradingSession `json:"sessions"`
+}
+
+// TradingSession is one session definition with its latest instances and sweeps of prior extremes
+type TradingSession struct {
+	Name       string         `json:"name"`
+	Timezone   string         `json:"timezone"` // IANA zone Start and End are expressed in
+	Start      string         `json:"start"`    // Local "HH:MM"
+	End        string         `json:"end"`      // Local "HH:MM", before Start when the session spans midnight
+	Active     bool           `json:"active"`
+	Current    *SessionRange  `json:"current,omitempty"`  // Instance in progress, when active
+	Previous   *SessionRange  `json:"previous,omitempty"` // Latest completed instance
+	AvgRange   float64        `json:"avg_range"`          // Mean high-low range of completed instances
+	RangeRatio float64        `json:"range_ratio"`        // Current range over AvgRange, 0 when inactive
+	Sweeps     []SessionSweep `json:"sweeps"`
+}
+
+// SessionRange is the price range of one session instance
+type SessionRange struct {
+	Start    int64   `json:"start"` // Session open timestamp (ms)
+	End      int64   `json:"end"`   // Session close timestamp (ms)
+	Open     float64 `json:"open"`
+	High     float64 `json:"high"`
+	Low      float64 `json:"low"`
+	Close    float64 `json:"close"`    // Latest close while in progress
+	Complete bool    `json:"complete"` // Every candle of the session has closed
+}
+
+// SessionSweep is a candle after a session that traded beyond its high or low and closed back inside
+type SessionSweep struct {
+	Session      string  `json:"session"`
+	Side         string  `json:"side"`          // "HIGH" or "LOW"
+	Price        float64 `json:"price"`         // Session extreme that was swept
+	Extreme      float64 `json:"extreme"`       // Wick extreme of the sweeping candle
+	SessionStart int64   `json:"session_start"` // Open timestamp of the swept session
+	Timestamp    int64   `json:"timestamp"`     // Sweeping candle
+}
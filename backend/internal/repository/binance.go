@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/adshao/go-binance/v2/futures"
 	"github.com/kudaompq/ai_trending/backend/internal/model"
@@ -24,6 +26,7 @@ func NewBinanceRepository() *BinanceRepository {
 
 // GetKlines fetches K-line data from Binance Futures
 func (r *BinanceRepository) GetKlines(symbol, interval string, limit int) ([]model.Candle, error) {
+	binanceLimiter.Wait(klinesWeight(limit))
 	klines, err := r.client.NewKlinesService().
 		Symbol(symbol).
 		Interval(interval).
@@ -36,21 +39,101 @@ func (r *BinanceRepository) GetKlines(symbol, interval string, limit int) ([]mod
 
 	candles := make([]model.Candle, 0, len(klines))
 	for _, k := range klines {
-		open, _ := strconv.ParseFloat(k.Open, 64)
-		high, _ := strconv.ParseFloat(k.High, 64)
-		low, _ := strconv.ParseFloat(k.Low, 64)
-		close, _ := strconv.ParseFloat(k.Close, 64)
-		volume, _ := strconv.ParseFloat(k.Volume, 64)
-
-		candles = append(candles, model.Candle{
-			Timestamp: k.OpenTime,
-			Open:      open,
-			High:      high,
-			Low:       low,
-			Close:     close,
-			Volume:    volume,
-		})
+		candles = append(candles, toCandle(k))
 	}
 
 	return candles, nil
 }
+
+// GetKlinesBefore fetches up to limit K-lines that open at or before endTime (milliseconds), oldest first
+func (r *BinanceRepository) GetKlinesBefore(symbol, interval string, limit int, endTime int64) ([]model.Candle, error) {
+	binanceLimiter.Wait(klinesWeight(limit))
+	klines, err := r.client.NewKlinesService().
+		Symbol(symbol).
+		Interval(interval).
+		Limit(limit).
+		EndTime(endTime).
+		Do(context.Background())
+
+	if err != nil {
+		return nil, err
+	}
+
+	candles := make([]model.Candle, 0, len(klines))
+	for _, k := range klines {
+		candles = append(candles, toCandle(k))
+	}
+
+	return candles, nil
+}
+
+// GetKlinesAfter fetches up to limit K-lines that open at or after startTime (milliseconds), oldest first
+func (r *BinanceRepository) GetKlinesAfter(symbol, interval string, limit int, startTime int64) ([]model.Candle, error) {
+	binanceLimiter.Wait(klinesWeight(limit))
+	klines, err := r.client.NewKlinesService().
+		Symbol(symbol).
+		Interval(interval).
+		Limit(limit).
+		StartTime(startTime).
+		Do(context.Background())
+
+	if err != nil {
+		return nil, err
+	}
+
+	candles := make([]model.Candle, 0, len(klines))
+	for _, k := range klines {
+		candles = append(candles, toCandle(k))
+	}
+
+	return candles, nil
+}
+
+// GetTopSymbolsByVolume returns the n USDT-margined symbols with the highest 24h quote volume, highest first
+func (r *BinanceRepository) GetTopSymbolsByVolume(n int) ([]string, error) {
+	binanceLimiter.Wait(40) // All-symbol 24h ticker
+	stats, err := r.client.NewListPriceChangeStatsService().Do(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	type symbolVolume struct {
+		symbol string
+		volume float64
+	}
+	volumes := make([]symbolVolume, 0, len(stats))
+	for _, s := range stats {
+		if !strings.HasSuffix(s.Symbol, "USDT") {
+			continue
+		}
+		volume, _ := strconv.ParseFloat(s.QuoteVolume, 64)
+		volumes = append(volumes, symbolVolume{s.Symbol, volume})
+	}
+	sort.Slice(volumes, func(i, j int) bool {
+		return volumes[i].volume > volumes[j].volume
+	})
+
+	symbols := make([]string, 0, n)
+	for _, v := range volumes[:min(n, len(volumes))] {
+		symbols = append(symbols, v.symbol)
+	}
+	return symbols, nil
+}
+
+// toCandle converts a Binance kline into a candle
+func toCandle(k *futures.Kline) model.Candle {
+	open, _ := strconv.ParseFloat(k.Open, 64)
+	high, _ := strconv.ParseFloat(k.High, 64)
+	low, _ := strconv.ParseFloat(k.Low, 64)
+	close, _ := strconv.ParseFloat(k.Close, 64)
+	volume, _ := strconv.ParseFloat(k.Volume, 64)
+
+	return model.Candle{
+		Timestamp: k.OpenTime,
+		Open:      open,
+		High:      high,
+		Low:       low,
+		Close:     close,
+		Volume:    volume,
+	}
+}
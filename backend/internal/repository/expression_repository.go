@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/kudaompq/ai_trending/backend/internal/database"
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// ErrExpressionNotFound is returned when no saved expression has the requested name
+var ErrExpressionNotFound = errors.New("saved expression not found")
+
+// ExpressionRepository handles saved expression persistence
+type ExpressionRepository struct {
+	db *sql.DB
+}
+
+// NewExpressionRepository creates a new expression repository
+func NewExpressionRepository() *ExpressionRepository {
+	return &ExpressionRepository{
+		db: database.DB,
+	}
+}
+
+// Save inserts or updates a saved expression and fills in its stored timestamps
+func (r *ExpressionRepository) Save(expr *model.SavedExpression) error {
+	now := time.Now().Unix() * 1000
+
+	query := `
+		INSERT INTO expressions (name, expression, description, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			expression = excluded.expression,
+			description = excluded.description,
+			updated_at = excluded.updated_at
+	`
+
+	if _, err := r.db.Exec(query, expr.Name, expr.Expression, expr.Description, now, now); err != nil {
+		return err
+	}
+
+	// An update keeps the original created_at
+	stored, err := r.FindByName(expr.Name)
+	if err != nil {
+		return err
+	}
+	*expr = *stored
+	return nil
+}
+
+// FindByName finds a saved expression by name, returning ErrExpressionNotFound when there is none
+func (r *ExpressionRepository) FindByName(name string) (*model.SavedExpression, error) {
+	query := `SELECT name, expression, description, created_at, updated_at FROM expressions WHERE name = ?`
+
+	var expr model.SavedExpression
+	err := r.db.QueryRow(query, name).Scan(
+		&expr.Name, &expr.Expression, &expr.Description, &expr.CreatedAt, &expr.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrExpressionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &expr, nil
+}
+
+// FindAll returns all saved expressions ordered by name
+func (r *ExpressionRepository) FindAll() ([]model.SavedExpression, error) {
+	query := `SELECT name, expression, description, created_at, updated_at FROM expressions ORDER BY name`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	expressions := []model.SavedExpression{}
+	for rows.Next() {
+		var expr model.SavedExpression
+		if err := rows.Scan(&expr.Name, &expr.Expression, &expr.Description, &expr.CreatedAt, &expr.UpdatedAt); err != nil {
+			return nil, err
+		}
+		expressions = append(expressions, expr)
+	}
+
+	return expressions, nil
+}
+
+// Delete removes a saved expression, returning ErrExpressionNotFound when there is none
+func (r *ExpressionRepository) Delete(name string) error {
+	result, err := r.db.Exec(`DELETE FROM expressions WHERE name = ?`, name)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return ErrExpressionNotFound
+	}
+	return nil
+}
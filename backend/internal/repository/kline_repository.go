@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/kudaompq/ai_trending/backend/internal/database"
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// KlineRepository handles stored K-line history
+type KlineRepository struct {
+	db *sql.DB
+}
+
+// NewKlineRepository creates a new K-line repository
+func NewKlineRepository() *KlineRepository {
+	return &KlineRepository{
+		db: database.DB,
+	}
+}
+
+// SaveCandles inserts or updates candles for a symbol and interval
+func (r *KlineRepository) SaveCandles(symbol, interval string, candles []model.Candle) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO klines (symbol, interval, open_time, open, high, low, close, volume)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(symbol, interval, open_time) DO UPDATE SET
+			open = excluded.open,
+			high = excluded.high,
+			low = excluded.low,
+			close = excluded.close,
+			volume = excluded.volume
+	`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, c := range candles {
+		if _, err := stmt.Exec(symbol, interval, c.Timestamp, c.Open, c.High, c.Low, c.Close, c.Volume); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindCandles returns the most recent limit stored candles, oldest first (limit <= 0 returns all)
+func (r *KlineRepository) FindCandles(symbol, interval string, limit int) ([]model.Candle, error) {
+	query := `
+		SELECT open_time, open, high, low, close, volume FROM (
+			SELECT open_time, open, high, low, close, volume FROM klines
+			WHERE symbol = ? AND interval = ?
+			ORDER BY open_time DESC
+			LIMIT ?
+		) ORDER BY open_time ASC
+	`
+	if limit <= 0 {
+		limit = -1
+	}
+
+	rows, err := r.db.Query(query, symbol, interval, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candles := []model.Candle{}
+	for rows.Next() {
+		var c model.Candle
+		if err := rows.Scan(&c.Timestamp, &c.Open, &c.High, &c.Low, &c.Close, &c.Volume); err != nil {
+			return nil, err
+		}
+		candles = append(candles, c)
+	}
+
+	return candles, rows.Err()
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/kudaompq/ai_trending/backend/internal/database"
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// PatternConfirmationRepository handles persisted candlestick patterns and their confirmation state
+type PatternConfirmationRepository struct {
+	db *sql.DB
+}
+
+// NewPatternConfirmationRepository creates a new pattern confirmation repository
+func NewPatternConfirmationRepository() *PatternConfirmationRepository {
+	return &PatternConfirmationRepository{
+		db: database.DB,
+	}
+}
+
+const patternConfirmationColumns = `symbol, interval, pattern, timestamp, type, direction, bars, reliability,
+	description, confirmation_price, invalidation_price, status, resolved_at`
+
+// InsertAll stores newly detected patterns in one transaction; a pattern already tracked keeps its state
+func (r *PatternConfirmationRepository) InsertAll(patterns []model.TrackedPattern) error {
+	now := time.Now().Unix() * 1000
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO pattern_confirmations (` + patternConfirmationColumns + `, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(symbol, interval, pattern, timestamp) DO NOTHING
+	`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, p := range patterns {
+		_, err := stmt.Exec(
+			p.Symbol, p.Interval, p.Pattern, p.Timestamp, p.Type, p.Direction, p.Bars, p.Reliability,
+			p.Description, p.ConfirmationPrice, p.InvalidationPrice, p.Confirmation, p.ResolvedAt,
+			now, now,
+		)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UpdateStatus records the resolved confirmation state of a tracked pattern
+func (r *PatternConfirmationRepository) UpdateStatus(p *model.TrackedPattern) error {
+	query := `
+		UPDATE pattern_confirmations
+		SET status = ?, resolved_at = ?, updated_at = ?
+		WHERE symbol = ? AND interval = ? AND pattern = ? AND timestamp = ?
+	`
+
+	_, err := r.db.Exec(query,
+		p.Confirmation, p.ResolvedAt, time.Now().Unix()*1000,
+		p.Symbol, p.Interval, p.Pattern, p.Timestamp,
+	)
+	return err
+}
+
+// FindByStatus returns the tracked patterns of a symbol and interval in a state, oldest first
+func (r *PatternConfirmationRepository) FindByStatus(symbol, interval, status string) ([]model.TrackedPattern, error) {
+	query := `SELECT ` + patternConfirmationColumns + ` FROM pattern_confirmations
+		WHERE symbol = ? AND interval = ? AND status = ?
+		ORDER BY timestamp ASC`
+
+	return r.query(query, symbol, interval, status)
+}
+
+// FindSince returns the tracked patterns of a symbol and interval completed at or after since, newest first
+func (r *PatternConfirmationRepository) FindSince(symbol, interval string, since int64) ([]model.TrackedPattern, error) {
+	query := `SELECT ` + patternConfirmationColumns + ` FROM pattern_confirmations
+		WHERE symbol = ? AND interval = ? AND timestamp >= ?
+		ORDER BY timestamp DESC, pattern`
+
+	return r.query(query, symbol, interval, since)
+}
+
+func (r *PatternConfirmationRepository) query(query string, args ...interface{}) ([]model.TrackedPattern, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	patterns := []model.TrackedPattern{}
+	for rows.Next() {
+		var p model.TrackedPattern
+		if err := rows.Scan(
+			&p.Symbol, &p.Interval, &p.Pattern, &p.Timestamp, &p.Type, &p.Direction, &p.Bars, &p.Reliability,
+			&p.Description, &p.ConfirmationPrice, &p.InvalidationPrice, &p.Confirmation, &p.ResolvedAt,
+		); err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, p)
+	}
+
+	return patterns, rows.Err()
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/kudaompq/ai_trending/backend/internal/database"
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// PatternStatsRepository handles calibrated candlestick pattern statistics
+type PatternStatsRepository struct {
+	db *sql.DB
+}
+
+// NewPatternStatsRepository creates a new pattern statistics repository
+func NewPatternStatsRepository() *PatternStatsRepository {
+	return &PatternStatsRepository{
+		db: database.DB,
+	}
+}
+
+// ReplaceAll replaces the statistics of a symbol and interval with a fresh calibration
+func (r *PatternStatsRepository) ReplaceAll(symbol, interval string, stats []model.PatternStats) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM pattern_stats WHERE symbol = ? AND interval = ?`, symbol, interval); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	query := `
+		INSERT INTO pattern_stats (
+			symbol, interval, pattern, direction, horizon, samples, hits, hit_rate,
+			avg_return, prior_reliability, empirical_reliability, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	for _, s := range stats {
+		_, err := tx.Exec(query,
+			symbol, interval, s.Pattern, s.Direction, s.Horizon, s.Samples, s.Hits, s.HitRate,
+			s.AvgReturn, s.PriorReliability, s.EmpiricalReliability, s.UpdatedAt,
+		)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindBySymbolInterval returns the statistics of a symbol and interval, most observed first
+func (r *PatternStatsRepository) FindBySymbolInterval(symbol, interval string) ([]model.PatternStats, error) {
+	query := `
+		SELECT symbol, interval, pattern, direction, horizon, samples, hits, hit_rate,
+			avg_return, prior_reliability, empirical_reliability, updated_at
+		FROM pattern_stats
+		WHERE symbol = ? AND interval = ?
+		ORDER BY samples DESC, pattern
+	`
+
+	rows, err := r.db.Query(query, symbol, interval)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []model.PatternStats{}
+	for rows.Next() {
+		var s model.PatternStats
+		if err := rows.Scan(
+			&s.Symbol, &s.Interval, &s.Pattern, &s.Direction, &s.Horizon, &s.Samples, &s.Hits, &s.HitRate,
+			&s.AvgReturn, &s.PriorReliability, &s.EmpiricalReliability, &s.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, rows.Err()
+}
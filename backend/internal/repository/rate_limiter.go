@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// binanceWeightPerMinute is the request weight spent per minute, half of the futures API's
+// 2400 per-IP limit so other clients on the same IP keep headroom
+const binanceWeightPerMinute = 1200
+
+// binanceLimiter is shared by every BinanceRepository so concurrent scans and analyses stay under the limit
+var binanceLimiter = newWeightLimiter(binanceWeightPerMinute)
+
+// weightLimiter is a token bucket of request weight that refills continuously
+type weightLimiter struct {
+	mu       sync.Mutex
+	capacity float64
+	tokens   float64
+	rate     float64 // Tokens per second
+	last     time.Time
+}
+
+func newWeightLimiter(perMinute float64) *weightLimiter {
+	return &weightLimiter{
+		capacity: perMinute,
+		tokens:   perMinute,
+		rate:     perMinute / 60,
+		last:     time.Now(),
+	}
+}
+
+// Wait blocks until weight tokens are available and takes them
+func (l *weightLimiter) Wait(weight int) {
+	for {
+		wait := l.take(weight, time.Now())
+		if wait == 0 {
+			return
+		}
+		time.Sleep(wait)
+	}
+}
+
+// take refills the bucket up to now and takes weight tokens, or returns how long to wait for them.
+// Weights above the capacity wait for a full bucket.
+func (l *weightLimiter) take(weight int, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w := math.Min(float64(weight), l.capacity)
+	if now.After(l.last) {
+		l.tokens = math.Min(l.capacity, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+		l.last = now
+	}
+	if l.tokens >= w {
+		l.tokens -= w
+		return 0
+	}
+	return time.Duration(math.Ceil((w - l.tokens) / l.rate * float64(time.Second)))
+}
+
+// klinesWeight is the request weight of a klines call for limit candles
+func klinesWeight(limit int) int {
+	switch {
+	case limit < 100:
+		return 1
+	case limit < 500:
+		return 2
+	case limit <= 1000:
+		return 5
+	default:
+		return 10
+	}
+}
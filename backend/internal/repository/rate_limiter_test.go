@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWeightLimiterRefill(t *testing.T) {
+	start := time.Now()
+	l := newWeightLimiter(1200) // 20 weight per second
+	l.last = start
+
+	if wait := l.take(1200, start); wait != 0 {
+		t.Fatalf("full bucket: waited %v", wait)
+	}
+	if wait := l.take(40, start); wait != 2*time.Second {
+		t.Fatalf("empty bucket: wait %v, want 2s", wait)
+	}
+
+	// Half a second refills 10 weight: not enough for 40, but the refill is kept
+	if wait := l.take(40, start.Add(500*time.Millisecond)); wait != 1500*time.Millisecond {
+		t.Fatalf("after 0.5s: wait %v, want 1.5s", wait)
+	}
+	if wait := l.take(40, start.Add(2*time.Second)); wait != 0 {
+		t.Fatalf("after 2s: waited %v", wait)
+	}
+
+	// Idle time never refills past the capacity, and oversized weights wait for a full bucket
+	later := start.Add(time.Hour)
+	if wait := l.take(1200, later); wait != 0 {
+		t.Fatalf("after an hour: waited %v", wait)
+	}
+	if wait := l.take(1, later); wait != 50*time.Millisecond {
+		t.Fatalf("capacity exceeded: wait %v, want 50ms", wait)
+	}
+	if wait := l.take(5000, later.Add(time.Minute)); wait != 0 {
+		t.Fatalf("oversized weight after a full refill: waited %v", wait)
+	}
+
+	// A clock step backwards does not refill
+	if wait := l.take(20, later); wait == 0 {
+		t.Fatal("refilled on a backwards clock")
+	}
+}
+
+func TestKlinesWeight(t *testing.T) {
+	tests := map[int]int{1: 1, 99: 1, 100: 2, 499: 2, 500: 5, 1000: 5, 1500: 10}
+	for limit, want := range tests {
+		if got := klinesWeight(limit); got != want {
+			t.Errorf("klinesWeight(%d) = %d, want %d", limit, got, want)
+		}
+	}
+}
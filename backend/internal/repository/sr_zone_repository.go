@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"database/sql"
+	"math"
+	"time"
+
+	"github.com/kudaompq/ai_trending/backend/internal/database"
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// SRZoneRepository handles persisted support/resistance zones
+type SRZoneRepository struct {
+	db *sql.DB
+}
+
+// NewSRZoneRepository creates a new SR zone repository
+func NewSRZoneRepository() *SRZoneRepository {
+	return &SRZoneRepository{
+		db: database.DB,
+	}
+}
+
+// srZonePriceKey rounds a zone price to four significant digits. Zones that close are always
+// overlapping, so the key only stops the same zone from being inserted twice.
+func srZonePriceKey(price float64) float64 {
+	if price <= 0 {
+		return 0
+	}
+	scale := math.Pow(10, 3-math.Floor(math.Log10(price)))
+	return math.Round(price*scale) / scale
+}
+
+// Save inserts a new zone (ID 0, assigning its ID) or updates an existing one. A new zone whose rounded
+// price is already stored, e.g. by a concurrent analysis, updates that zone instead.
+func (r *SRZoneRepository) Save(zone *model.SRZone) error {
+	now := time.Now().Unix() * 1000
+
+	if zone.ID == 0 {
+		query := `
+			INSERT INTO sr_zones (
+				symbol, interval, price, price_key, lower, upper, source, touches, first_touch, last_touch,
+				origin_role, breaks, broken_at, side, in_zone, through, touch_volume, touch_bars,
+				created_at, updated_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(symbol, interval, price_key) DO UPDATE SET
+				touches = excluded.touches,
+				first_touch = excluded.first_touch,
+				last_touch = excluded.last_touch,
+				origin_role = excluded.origin_role,
+				breaks = excluded.breaks,
+				broken_at = excluded.broken_at,
+				side = excluded.side,
+				in_zone = excluded.in_zone,
+				through = excluded.through,
+				touch_volume = excluded.touch_volume,
+				touch_bars = excluded.touch_bars,
+				updated_at = excluded.updated_at
+			RETURNING id
+		`
+		return r.db.QueryRow(query,
+			zone.Symbol, zone.Interval, zone.Price, srZonePriceKey(zone.Price), zone.Lower, zone.Upper, zone.Source,
+			zone.Touches, zone.FirstTouch, zone.LastTouch, zone.OriginRole, zone.Breaks, zone.BrokenAt,
+			zone.Side, zone.InZone, zone.Through, zone.TouchVolume, zone.TouchBars,
+			now, now,
+		).Scan(&zone.ID)
+	}
+
+	query := `
+		UPDATE sr_zones SET
+			lower = ?, upper = ?, source = ?, touches = ?, first_touch = ?, last_touch = ?, origin_role = ?, breaks = ?,
+			broken_at = ?, side = ?, in_zone = ?, through = ?, touch_volume = ?, touch_bars = ?, updated_at = ?
+		WHERE id = ?
+	`
+	_, err := r.db.Exec(query,
+		zone.Lower, zone.Upper, zone.Source, zone.Touches, zone.FirstTouch, zone.LastTouch, zone.OriginRole, zone.Breaks,
+		zone.BrokenAt, zone.Side, zone.InZone, zone.Through, zone.TouchVolume, zone.TouchBars, now,
+		zone.ID,
+	)
+	return err
+}
+
+// Delete removes stored zones by ID
+func (r *SRZoneRepository) Delete(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if _, err := tx.Exec(`DELETE FROM sr_zones WHERE id = ?`, id); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// FindBySymbolInterval returns every stored zone of a symbol and interval, lowest first
+func (r *SRZoneRepository) FindBySymbolInterval(symbol, interval string) ([]model.SRZone, error) {
+	query := `
+		SELECT id, symbol, interval, price, lower, upper, source, touches, first_touch, last_touch,
+			origin_role, breaks, broken_at, side, in_zone, through, touch_volume, touch_bars
+		FROM sr_zones
+		WHERE symbol = ? AND interval = ?
+		ORDER BY price
+	`
+
+	rows, err := r.db.Query(query, symbol, interval)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	zones := []model.SRZone{}
+	for rows.Next() {
+		var z model.SRZone
+		if err := rows.Scan(
+			&z.ID, &z.Symbol, &z.Interval, &z.Price, &z.Lower, &z.Upper, &z.Source, &z.Touches, &z.FirstTouch, &z.LastTouch,
+			&z.OriginRole, &z.Breaks, &z.BrokenAt, &z.Side, &z.InZone, &z.Through, &z.TouchVolume, &z.TouchBars,
+		); err != nil {
+			return nil, err
+		}
+		zones = append(zones, z)
+	}
+
+	return zones, rows.Err()
+}
@@ -1,67 +1,100 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"log"
 
 	"github.com/kudaompq/ai_trending/backend/internal/indicator"
 	"github.com/kudaompq/ai_trending/backend/internal/model"
 	"github.com/kudaompq/ai_trending/backend/internal/repository"
 )
 
+// realizedVolHistoryBars caps the stored candles the realized volatility percentile is ranked against
+const realizedVolHistoryBars = 5000
+
 // AnalysisService orchestrates the complete analysis
 type AnalysisService struct {
-	binanceRepo            *repository.BinanceRepository
-	trendService           *TrendService
-	marketStructureService *MarketStructureService
+	binanceRepo             *repository.BinanceRepository
+	trendService            *TrendService
+	marketStructureService  *MarketStructureService
+	patternStatsRepo        *repository.PatternStatsRepository
+	patternTrackingService  *PatternTrackingService
+	srZoneService           *SRZoneService
+	relativeStrengthService *RelativeStrengthService
+	historyService          *HistoryService
 }
 
 // NewAnalysisService creates a new analysis service
 func NewAnalysisService() *AnalysisService {
 	return &AnalysisService{
-		binanceRepo:            repository.NewBinanceRepository(),
-		trendService:           NewTrendService(),
-		marketStructureService: NewMarketStructureService(),
+		binanceRepo:             repository.NewBinanceRepository(),
+		trendService:            NewTrendService(),
+		marketStructureService:  NewMarketStructureService(),
+		patternStatsRepo:        repository.NewPatternStatsRepository(),
+		patternTrackingService:  NewPatternTrackingService(),
+		srZoneService:           NewSRZoneService(),
+		relativeStrengthService: NewRelativeStrengthService(),
+		historyService:          NewHistoryService(),
+	}
+}
+
+// AnalysisOptions holds optional analysis settings
+type AnalysisOptions struct {
+	VolumeProfile indicator.VolumeProfileConfig
+	PatternScan   bool // Scan candlestick patterns across the whole window
+	// Use calibrated reliability (when backed by enough samples) instead of the hardcoded prior
+	EmpiricalReliability bool
+	// Higher timeframes (e.g. "4h", "1d", "1w") whose SR levels are merged into the result
+	SRTimeframes []string
+	// Benchmark symbol (e.g. "BTCUSDT") for the relative strength block; empty skips it
+	Benchmark string
+	// Symbols the relative strength score is ranked against (DefaultWatchlist when empty)
+	RSWatchlist []string
+	// Trading sessions whose ranges, sweeps and prior highs/lows are reported
+	Sessions []indicator.SessionDefinition
+	// Streaming engines kept across analyses (e.g. by the scanner); nil computes indicators from scratch
+	Engines *IndicatorEngineService
+	// Swing detection shared by SR levels, structure, chart and harmonic patterns, trendlines, SMC and Fibonacci
+	Swings indicator.SwingConfig
+}
+
+// DefaultAnalysisOptions returns the options used by PerformAnalysis
+func DefaultAnalysisOptions() AnalysisOptions {
+	return AnalysisOptions{
+		VolumeProfile: indicator.DefaultVolumeProfileConfig(),
+		Sessions:      indicator.DefaultSessions(),
+		Swings:        indicator.DefaultSwingConfig(),
 	}
 }
 
 // PerformAnalysis performs complete analysis for a symbol
 func (s *AnalysisService) PerformAnalysis(symbol, interval string, limit int) (*model.AnalysisResult, error) {
+	return s.PerformAnalysisWithOptions(symbol, interval, limit, DefaultAnalysisOptions())
+}
+
+// PerformAnalysisWithOptions performs complete analysis for a symbol with custom options
+func (s *AnalysisService) PerformAnalysisWithOptions(symbol, interval string, limit int, opts AnalysisOptions) (*model.AnalysisResult, error) {
 	// Fetch K-line data
 	candles, err := s.binanceRepo.GetKlines(symbol, interval, limit)
 	if err != nil {
 		return nil, err
 	}
+	return s.AnalyzeCandles(symbol, interval, limit, candles, opts)
+}
 
+// AnalyzeCandles performs complete analysis on candles already fetched for a symbol, the last one
+// still forming
+func (s *AnalysisService) AnalyzeCandles(symbol, interval string, limit int, candles []model.Candle, opts AnalysisOptions) (*model.AnalysisResult, error) {
 	if len(candles) < 20 {
 		return nil, fmt.Errorf("insufficient data: need at least 20 candles")
 	}
 
-	// Calculate existing indicators
-	macd := indicator.CalculateMACD(candles)
-	kdj := indicator.CalculateKDJWithHistory(candles)
-	rsi := indicator.CalculateRSI(candles)
-
-	// Calculate new indicators
-	// ATR (14-period)
-	atrResult := indicator.CalculateATR(candles, 14)
-	atrIndicator := model.ATRIndicator{
-		Value:  atrResult.GetCurrentATR(),
-		Period: 14,
-	}
-
-	// EMA (multiple periods: 9, 21, 50, 200)
-	closePrices := make([]float64, len(candles))
-	for i, candle := range candles {
-		closePrices[i] = candle.Close
-	}
+	// MACD, KDJ, RSI, ATR, EMA, volume profile and pivots
+	indicators := s.coreIndicators(symbol, interval, candles, opts)
 
-	emaResults := indicator.CalculateMultipleEMA(closePrices, []int{9, 21, 50, 200})
-	emaIndicator := model.EMAIndicator{
-		EMA9:   emaResults[9].GetCurrentEMA(),
-		EMA21:  emaResults[21].GetCurrentEMA(),
-		EMA50:  emaResults[50].GetCurrentEMA(),
-		EMA200: emaResults[200].GetCurrentEMA(),
-	}
+	// One swing set feeds every swing-based study so they agree on the turning points
+	swings := indicator.FindSwings(candles, opts.Swings)
 
 	// Fibonacci levels (using last 100 candles for swing high/low)
 	var fibLevels *model.FibonacciLevels
@@ -70,7 +103,7 @@ func (s *AnalysisService) PerformAnalysis(symbol, interval string, limit int) (*
 		if len(candles) < lookback {
 			lookback = len(candles)
 		}
-		fibResult := indicator.CalculateFibonacciFromCandles(candles, lookback)
+		fibResult := indicator.CalculateFibonacciFromCandles(candles, swings, lookback)
 		if fibResult != nil {
 			fibLevels = &model.FibonacciLevels{
 				High:        fibResult.High,
@@ -82,29 +115,96 @@ func (s *AnalysisService) PerformAnalysis(symbol, interval string, limit int) (*
 		}
 	}
 
+	// Trading sessions, built from hourly candles when the interval is coarser
+	sessions := s.tradingSessions(symbol, interval, candles, opts.Sessions)
+
+	// Realized volatility, annualized for the interval and ranked against stored history and its own
+	stored, err := s.historyService.StoredHistory(symbol, interval, realizedVolHistoryBars)
+	if err != nil {
+		log.Printf("Failed to load stored history: %v", err)
+	}
+	realizedVol := indicator.CalculateRealizedVolatilityWithHistory(candles, stored, interval, indicator.RealizedVolatilityWindow)
+
 	// Analyze trend
 	trend := s.trendService.AnalyzeTrend(candles)
 
-	// Calculate SR levels with interval awareness
-	srLevels := indicator.CalculateSRLevelsWithInterval(candles, limit, interval)
+	// Classify the market regime
+	regime := indicator.ClassifyRegime(candles)
+
+	// Calculate SR levels with interval awareness, confirmed by volume profile nodes
+	srLevels := indicator.CalculateSRLevelsWithSwings(candles, swings, limit, interval)
+	srLevels = indicator.MergeVolumeProfileLevels(srLevels, indicators.VolumeProfile, candles, interval)
+
+	// Accumulate the levels into persisted zones with touch history and role flips
+	srLevels = s.srZoneService.Accumulate(symbol, interval, candles, srLevels)
+
+	// Optionally merge SR levels from higher timeframes, weighted by timeframe
+	if len(opts.SRTimeframes) > 0 {
+		srLevels = indicator.MergeTimeframeLevels(srLevels, interval, s.higherTimeframeLevels(symbol, interval, opts.SRTimeframes, opts.Swings), candles[len(candles)-1].Close, indicators.ATR.Value)
+	}
+
+	// Trendlines and channels; unbroken lines act as dynamic SR at their current projection
+	trendlines := indicator.DetectTrendlines(candles, swings)
+	srLevels = indicator.MergeTrendlineLevels(srLevels, trendlines, candles[len(candles)-1].Close)
 
 	// Identify candlestick patterns
 	trendDirection := s.trendService.DetermineTrendDirection(candles)
 	patterns := indicator.IdentifyPatterns(candles, trendDirection)
 
-	// Build indicators struct for market structure analysis
-	indicators := model.Indicators{
-		MACD:      macd,
-		KDJ:       kdj,
-		RSI:       rsi,
-		ATR:       atrIndicator,
-		EMA:       emaIndicator,
-		Fibonacci: fibLevels,
+	// Confirmation state from the candles after each pattern; tracked patterns persist it across requests
+	indicator.ConfirmPatterns(candles, patterns)
+
+	// Optionally scan the closed candles, judging each bar against its own trend context. Like pattern
+	// tracking, the forming candle is left out; positions then count back from it again.
+	var patternHistory []model.CandlestickPattern
+	if opts.PatternScan {
+		closed := candles[:len(candles)-1]
+		patternHistory = indicator.ScanPatterns(closed, s.trendService.DetermineTrendDirection)
+		indicator.ConfirmPatterns(closed, patternHistory)
+		for i := range patternHistory {
+			patternHistory[i].Position--
+		}
+	}
+	trackedPatterns := s.patternTrackingService.Track(symbol, interval, candles, s.trendService.DetermineTrendDirection)
+
+	// Report calibrated reliability next to the prior when this symbol and interval have been calibrated
+	if stats, err := s.patternStatsRepo.FindBySymbolInterval(symbol, interval); err == nil && len(stats) > 0 {
+		indicator.ApplyPatternStats(patterns, stats, opts.EmpiricalReliability)
+		indicator.ApplyPatternStats(patternHistory, stats, opts.EmpiricalReliability)
+		indicator.ApplyPatternStats(trackedPatterns, stats, opts.EmpiricalReliability)
+	}
+
+	// Optionally measure relative strength against a benchmark through the ratio series. A bad benchmark
+	// fails the request; too little shared history only drops the block.
+	var relativeStrength *model.RelativeStrength
+	if opts.Benchmark != "" {
+		var err error
+		relativeStrength, err = s.relativeStrengthService.Analyze(symbol, interval, limit, candles, opts.Benchmark, opts.RSWatchlist)
+		if errors.Is(err, ErrInvalidBenchmark) {
+			return nil, err
+		}
+		if err != nil {
+			log.Printf("Failed to analyze relative strength: %v", err)
+		}
 	}
 
+	// Detect multi-bar chart patterns
+	chartPatterns := indicator.DetectChartPatterns(candles, swings)
+
+	// Scan for XABCD harmonic patterns
+	harmonicPatterns := indicator.DetectHarmonicPatterns(candles, swings)
+
+	// Complete the indicators struct for market structure analysis
+	indicators.Fibonacci = fibLevels
+	indicators.FibConfluence = indicator.CalculateFibonacciConfluence(candles, swings)
+	indicators.Volatility = realizedVol
+	indicators.Sessions = sessions
+
 	// Analyze market structure with comprehensive multi-indicator analysis
 	marketStructure := s.marketStructureService.AnalyzeStructure(
 		candles,
+		swings,
+		opts.Swings,
 		trendDirection,
 		indicators,
 		srLevels,
@@ -116,9 +216,106 @@ func (s *AnalysisService) PerformAnalysis(symbol, interval string, limit int) (*
 		Interval:            interval,
 		Timestamp:           candles[len(candles)-1].Timestamp,
 		Trend:               trend,
+		Regime:              regime,
 		Indicators:          indicators,
 		SRLevels:            srLevels,
 		CandlestickPatterns: patterns,
+		PatternHistory:      patternHistory,
+		TrackedPatterns:     trackedPatterns,
+		ChartPatterns:       chartPatterns,
+		HarmonicPatterns:    harmonicPatterns,
+		Trendlines:          trendlines,
+		RelativeStrength:    relativeStrength,
 		MarketStructure:     marketStructure,
 	}, nil
 }
+
+// coreIndicators computes the indicators that have streaming versions: from opts.Engines when set,
+// otherwise over the candles. Pivots come from the prior higher-timeframe candle, fetched when the
+// engine has not seen a whole one.
+func (s *AnalysisService) coreIndicators(symbol, interval string, candles []model.Candle, opts AnalysisOptions) model.Indicators {
+	if opts.Engines != nil {
+		indicators := opts.Engines.Indicators(symbol, interval, candles, opts.VolumeProfile)
+		if indicators.Pivots == nil {
+			indicators.Pivots = s.pivotPoints(symbol, interval)
+		}
+		return indicators
+	}
+
+	// ATR (14-period)
+	atrResult := indicator.CalculateATR(candles, 14)
+
+	// EMA (multiple periods: 9, 21, 50, 200)
+	closePrices := make([]float64, len(candles))
+	for i, candle := range candles {
+		closePrices[i] = candle.Close
+	}
+	emaResults := indicator.CalculateMultipleEMA(closePrices, []int{9, 21, 50, 200})
+
+	return model.Indicators{
+		MACD: indicator.CalculateMACD(candles),
+		KDJ:  indicator.CalculateKDJWithHistory(candles),
+		RSI:  indicator.CalculateRSI(candles),
+		ATR: model.ATRIndicator{
+			Value:  atrResult.GetCurrentATR(),
+			Period: 14,
+		},
+		EMA: model.EMAIndicator{
+			EMA9:   emaResults[9].GetCurrentEMA(),
+			EMA21:  emaResults[21].GetCurrentEMA(),
+			EMA50:  emaResults[50].GetCurrentEMA(),
+			EMA200: emaResults[200].GetCurrentEMA(),
+		},
+		// Volume profile (POC, value area, HVN/LVN)
+		VolumeProfile: indicator.CalculateVolumeProfile(candles, opts.VolumeProfile),
+		Pivots:        s.pivotPoints(symbol, interval),
+	}
+}
+
+// pivotPoints computes pivots from the prior completed higher-timeframe candle; nil when it fails to load
+func (s *AnalysisService) pivotPoints(symbol, interval string) *model.PivotPoints {
+	pivotTimeframe := indicator.PivotTimeframe(interval)
+	htfCandles, err := s.binanceRepo.GetKlines(symbol, pivotTimeframe, 2)
+	if err != nil || len(htfCandles) < 2 {
+		return nil
+	}
+	return indicator.CalculatePivotPoints(htfCandles[len(htfCandles)-2], pivotTimeframe)
+}
+
+// higherTimeframeLevels computes SR levels on each requested timeframe above interval with the analysis
+// swing settings; timeframes that are not higher than interval or fail to load are skipped
+func (s *AnalysisService) higherTimeframeLevels(symbol, interval string, timeframes []string, swingConfig indicator.SwingConfig) []indicator.TimeframeLevels {
+	levels := make([]indicator.TimeframeLevels, 0, len(timeframes))
+	for _, tf := range timeframes {
+		if indicator.IntervalDuration(tf) <= indicator.IntervalDuration(interval) {
+			continue
+		}
+		htfCandles, err := s.binanceRepo.GetKlines(symbol, tf, 200)
+		if err != nil || len(htfCandles) < 20 {
+			continue
+		}
+		levels = append(levels, indicator.TimeframeLevels{
+			Timeframe: tf,
+			Levels:    indicator.CalculateSRLevelsWithSwings(htfCandles, indicator.FindSwings(htfCandles, swingConfig), 0, tf),
+		})
+	}
+	return levels
+}
+
+// tradingSessions computes the sessions on the analysis candles for intervals up to SessionTimeframe and
+// on separately fetched SessionTimeframe candles otherwise; nil when no sessions are configured
+func (s *AnalysisService) tradingSessions(symbol, interval string, candles []model.Candle, defs []indicator.SessionDefinition) *model.SessionAnalysis {
+	if len(defs) == 0 {
+		return nil
+	}
+	if indicator.IntervalDuration(interval) <= indicator.IntervalDuration(indicator.SessionTimeframe) {
+		return indicator.CalculateSessions(candles, interval, defs)
+	}
+
+	sessionCandles, err := s.binanceRepo.GetKlines(symbol, indicator.SessionTimeframe, 500)
+	if err != nil {
+		log.Printf("Failed to fetch session candles: %v", err)
+		return nil
+	}
+	return indicator.CalculateSessions(sessionCandles, indicator.SessionTimeframe, defs)
+}
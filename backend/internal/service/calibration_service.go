@@ -0,0 +1,74 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kudaompq/ai_trending/backend/internal/indicator"
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+	"github.com/kudaompq/ai_trending/backend/internal/repository"
+)
+
+// CalibrationService measures candlestick pattern performance on stored history
+type CalibrationService struct {
+	historyService   *HistoryService
+	patternStatsRepo *repository.PatternStatsRepository
+	trendService     *TrendService
+}
+
+// NewCalibrationService creates a new calibration service
+func NewCalibrationService() *CalibrationService {
+	return &CalibrationService{
+		historyService:   NewHistoryService(),
+		patternStatsRepo: repository.NewPatternStatsRepository(),
+		trendService:     NewTrendService(),
+	}
+}
+
+// SyncHistory brings the stored closed candles of a symbol and interval up to date, backfilled to at least bars
+func (s *CalibrationService) SyncHistory(symbol, interval string, bars int) (int, error) {
+	return s.historyService.SyncHistory(symbol, interval, bars)
+}
+
+// Calibrate scans the stored history of a symbol and interval (the most recent bars candles, all when
+// bars <= 0), measures every directional pattern's forward return over horizon bars and stores the statistics
+func (s *CalibrationService) Calibrate(symbol, interval string, horizon, bars int) (*model.CalibrationResult, error) {
+	if horizon <= 0 {
+		horizon = indicator.DefaultCalibrationHorizon
+	}
+
+	candles, err := s.historyService.StoredHistory(symbol, interval, bars)
+	if err != nil {
+		return nil, err
+	}
+	if len(candles) < horizon+50 {
+		return nil, fmt.Errorf("insufficient stored history: %d candles for %s %s, sync history first", len(candles), symbol, interval)
+	}
+
+	stats := indicator.CalibratePatterns(candles, s.trendService.DetermineTrendDirection, horizon)
+	now := time.Now().Unix() * 1000
+	for i := range stats {
+		stats[i].Symbol = symbol
+		stats[i].Interval = interval
+		stats[i].UpdatedAt = now
+	}
+
+	if err := s.patternStatsRepo.ReplaceAll(symbol, interval, stats); err != nil {
+		return nil, err
+	}
+
+	return &model.CalibrationResult{
+		Symbol:   symbol,
+		Interval: interval,
+		Horizon:  horizon,
+		Candles:  len(candles),
+		From:     candles[0].Timestamp,
+		To:       candles[len(candles)-1].Timestamp,
+		Stats:    stats,
+	}, nil
+}
+
+// GetStats returns the stored pattern statistics of a symbol and interval
+func (s *CalibrationService) GetStats(symbol, interval string) ([]model.PatternStats, error) {
+	return s.patternStatsRepo.FindBySymbolInterval(symbol, interval)
+}
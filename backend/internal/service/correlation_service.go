@@ -0,0 +1,104 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/kudaompq/ai_trending/backend/internal/indicator"
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// CorrelationService computes cross-symbol return correlation and beta from stored history
+type CorrelationService struct {
+	historyService *HistoryService
+}
+
+// NewCorrelationService creates a new correlation service
+func NewCorrelationService() *CorrelationService {
+	return &CorrelationService{
+		historyService: NewHistoryService(),
+	}
+}
+
+// ComputeMatrix loads bars candles per symbol (syncing stored history when it is short or stale),
+// aligns them on shared timestamps and computes the correlation matrix of log returns over the latest
+// window returns, plus each symbol's latest and rolling correlation and beta to the benchmark.
+// The benchmark is added to the symbols when missing. An unknown interval returns ErrInvalidInterval.
+func (s *CorrelationService) ComputeMatrix(symbols []string, benchmark, interval string, window, bars int) (*model.CorrelationMatrix, error) {
+	if !containsInterval(binanceIntervals, interval) {
+		return nil, fmt.Errorf("%w %q", ErrInvalidInterval, interval)
+	}
+	symbols = uniqueSymbols(append([]string{benchmark}, symbols...))
+	if len(symbols) < 2 {
+		return nil, fmt.Errorf("at least one symbol besides the benchmark is required")
+	}
+
+	series := make([][]model.Candle, len(symbols))
+	for i, symbol := range symbols {
+		candles, err := s.historyService.LoadHistory(symbol, interval, bars)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s history: %w", symbol, err)
+		}
+		series[i] = candles
+	}
+
+	timestamps, closes := indicator.AlignCloses(series)
+	if len(timestamps) < window+1 {
+		return nil, fmt.Errorf("insufficient aligned history: %d candles shared by all symbols, need %d", len(timestamps), window+1)
+	}
+
+	returns := make([][]float64, len(symbols))
+	latest := make([][]float64, len(symbols))
+	for i := range symbols {
+		returns[i] = indicator.LogReturns(closes[i])
+		latest[i] = returns[i][len(returns[i])-window:]
+	}
+
+	matrix := make([][]float64, len(symbols))
+	for i := range symbols {
+		matrix[i] = make([]float64, len(symbols))
+		for j := range symbols {
+			if i == j {
+				matrix[i][j] = 1
+			} else {
+				matrix[i][j] = indicator.Correlation(latest[i], latest[j])
+			}
+		}
+	}
+
+	// The benchmark is always the first symbol
+	betas := make([]model.SymbolBeta, len(symbols))
+	for i, symbol := range symbols {
+		betas[i] = model.SymbolBeta{
+			Symbol:      symbol,
+			Correlation: matrix[i][0],
+			Beta:        indicator.Beta(latest[i], latest[0]),
+			Rolling:     indicator.RollingBeta(returns[i], returns[0], timestamps, window),
+		}
+	}
+
+	return &model.CorrelationMatrix{
+		Interval:     interval,
+		Window:       window,
+		Benchmark:    benchmark,
+		Symbols:      symbols,
+		Matrix:       matrix,
+		Betas:        betas,
+		Observations: len(timestamps),
+		From:         timestamps[0],
+		To:           timestamps[len(timestamps)-1],
+	}, nil
+}
+
+// uniqueSymbols drops empty and repeated symbols, keeping the first occurrence
+func uniqueSymbols(symbols []string) []string {
+	seen := make(map[string]bool, len(symbols))
+	result := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		if symbol == "" || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		result = append(result, symbol)
+	}
+	return result
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/kudaompq/ai_trending/backend/internal/expression"
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+	"github.com/kudaompq/ai_trending/backend/internal/repository"
+)
+
+// ExpressionService evaluates and manages custom indicator expressions
+type ExpressionService struct {
+	binanceRepo *repository.BinanceRepository
+	repository  *repository.ExpressionRepository
+}
+
+// NewExpressionService creates a new expression service
+func NewExpressionService() *ExpressionService {
+	return &ExpressionService{
+		binanceRepo: repository.NewBinanceRepository(),
+		repository:  repository.NewExpressionRepository(),
+	}
+}
+
+// Evaluate evaluates an expression (or a saved expression when name is set) over fresh candles
+func (s *ExpressionService) Evaluate(symbol, interval string, limit int, source, name string) (*model.ExpressionResult, error) {
+	if name != "" {
+		saved, err := s.findExpression(name)
+		if err != nil {
+			return nil, err
+		}
+		source = saved.Expression
+	}
+	if source == "" {
+		return nil, fmt.Errorf("%w: expression or name is required", expression.ErrInvalidExpression)
+	}
+
+	candles, err := s.binanceRepo.GetKlines(symbol, interval, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	series, err := s.EvaluateSeries(source, candles)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]model.ExpressionPoint, len(candles))
+	for i, c := range candles {
+		points[i] = model.ExpressionPoint{
+			Timestamp: c.Timestamp,
+			Value:     seriesValue(series[i]),
+		}
+	}
+
+	var latest *float64
+	if len(series) > 0 {
+		latest = seriesValue(series[len(series)-1])
+	}
+
+	return &model.ExpressionResult{
+		Symbol:     symbol,
+		Interval:   interval,
+		Name:       name,
+		Expression: source,
+		Series:     points,
+		Latest:     latest,
+	}, nil
+}
+
+// EvaluateSeries evaluates an expression over the given candles, resolving saved expressions by name
+func (s *ExpressionService) EvaluateSeries(source string, candles []model.Candle) ([]float64, error) {
+	return expression.Evaluate(source, candles, s.resolve)
+}
+
+// HasExpression reports whether a saved expression exists
+func (s *ExpressionService) HasExpression(name string) (bool, error) {
+	_, found, err := s.resolve(name)
+	return found, err
+}
+
+// LatestValue evaluates a saved expression over the candles and returns its value on the last one,
+// NaN when undefined there. Used by the scanner to filter on saved expressions by name.
+func (s *ExpressionService) LatestValue(name string, candles []model.Candle) (float64, error) {
+	saved, err := s.findExpression(name)
+	if err != nil {
+		return 0, err
+	}
+
+	series, err := s.EvaluateSeries(saved.Expression, candles)
+	if err != nil {
+		return 0, fmt.Errorf("saved expression %q: %w", name, err)
+	}
+	if len(series) == 0 {
+		return math.NaN(), nil
+	}
+	return series[len(series)-1], nil
+}
+
+// SaveExpression validates and stores a named expression
+func (s *ExpressionService) SaveExpression(expr *model.SavedExpression) error {
+	if err := expression.ValidateName(expr.Name); err != nil {
+		return err
+	}
+	if _, err := expression.Parse(expr.Expression); err != nil {
+		return err
+	}
+	return s.repository.Save(expr)
+}
+
+// ListExpressions returns all saved expressions
+func (s *ExpressionService) ListExpressions() ([]model.SavedExpression, error) {
+	return s.repository.FindAll()
+}
+
+// DeleteExpression removes a saved expression
+func (s *ExpressionService) DeleteExpression(name string) error {
+	return s.repository.Delete(name)
+}
+
+// findExpression loads a saved expression, naming it in the not found error
+func (s *ExpressionService) findExpression(name string) (*model.SavedExpression, error) {
+	saved, err := s.repository.FindByName(name)
+	if errors.Is(err, repository.ErrExpressionNotFound) {
+		return nil, fmt.Errorf("%w: %q", err, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load saved expression %q: %w", name, err)
+	}
+	return saved, nil
+}
+
+// resolve looks up saved expressions for the evaluator
+func (s *ExpressionService) resolve(name string) (string, bool, error) {
+	saved, err := s.repository.FindByName(name)
+	if errors.Is(err, repository.ErrExpressionNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return saved.Expression, true, nil
+}
+
+// seriesValue converts NaN to nil for JSON output
+func seriesValue(v float64) *float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	return &v
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"time"
+
+	"github.com/kudaompq/ai_trending/backend/internal/indicator"
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+	"github.com/kudaompq/ai_trending/backend/internal/repository"
+)
+
+// binanceMaxKlines is the largest page the futures klines endpoint returns
+const binanceMaxKlines = 1500
+
+// HistoryService keeps stored K-line history in sync with Binance
+type HistoryService struct {
+	binanceRepo *repository.BinanceRepository
+	klineRepo   *repository.KlineRepository
+}
+
+// NewHistoryService creates a new history service
+func NewHistoryService() *HistoryService {
+	return &HistoryService{
+		binanceRepo: repository.NewBinanceRepository(),
+		klineRepo:   repository.NewKlineRepository(),
+	}
+}
+
+// SyncHistory brings the stored candles of a symbol and interval up to date and backfills them to at
+// least bars candles, returning how many new candles were stored. New candles are paged forward from the
+// latest stored one, which is refreshed; missing history is paged backwards from the oldest.
+// The still-forming candle is never stored.
+func (s *HistoryService) SyncHistory(symbol, interval string, bars int) (int, error) {
+	stored, err := s.klineRepo.FindCandles(symbol, interval, bars)
+	if err != nil {
+		return 0, err
+	}
+
+	added := 0
+	endTime := time.Now().UnixMilli()
+	if len(stored) > 0 {
+		if added, err = s.syncForward(symbol, interval, stored[len(stored)-1].Timestamp); err != nil {
+			return added, err
+		}
+		endTime = stored[0].Timestamp - 1
+	}
+
+	// Backfill older candles until bars are stored or the listing is reached
+	for missing := bars - len(stored) - added; missing > 0; {
+		page := min(binanceMaxKlines, missing)
+		candles, err := s.binanceRepo.GetKlinesBefore(symbol, interval, page, endTime)
+		if err != nil {
+			return added, err
+		}
+		listed := len(candles) < page
+		if len(candles) == 0 {
+			break
+		}
+		endTime = candles[0].Timestamp - 1
+
+		candles = closedCandles(candles, interval)
+		if err := s.klineRepo.SaveCandles(symbol, interval, candles); err != nil {
+			return added, err
+		}
+		added += len(candles)
+		missing -= len(candles)
+
+		if listed {
+			break
+		}
+	}
+
+	return added, nil
+}
+
+// syncForward stores every closed candle opening at or after from, returning how many open after it
+func (s *HistoryService) syncForward(symbol, interval string, from int64) (int, error) {
+	added := 0
+	for startTime := from; ; {
+		candles, err := s.binanceRepo.GetKlinesAfter(symbol, interval, binanceMaxKlines, startTime)
+		if err != nil {
+			return added, err
+		}
+		more := len(candles) == binanceMaxKlines
+
+		candles = closedCandles(candles, interval)
+		if len(candles) == 0 {
+			return added, nil
+		}
+		if err := s.klineRepo.SaveCandles(symbol, interval, candles); err != nil {
+			return added, err
+		}
+		for _, c := range candles {
+			if c.Timestamp > from {
+				added++
+			}
+		}
+
+		if !more {
+			return added, nil
+		}
+		startTime = candles[len(candles)-1].Timestamp + 1
+	}
+}
+
+// closedCandles drops the trailing candles that have not closed yet
+func closedCandles(candles []model.Candle, interval string) []model.Candle {
+	now := time.Now().UnixMilli()
+	barMillis := indicator.IntervalDuration(interval).Milliseconds()
+	n := len(candles)
+	for n > 0 && candles[n-1].Timestamp+barMillis > now {
+		n--
+	}
+	return candles[:n]
+}
+
+// LoadHistory returns the most recent bars stored candles, oldest first. History that is shorter than
+// bars or whose latest candle is more than one bar old is synced from Binance first.
+func (s *HistoryService) LoadHistory(symbol, interval string, bars int) ([]model.Candle, error) {
+	candles, err := s.klineRepo.FindCandles(symbol, interval, bars)
+	if err != nil {
+		return nil, err
+	}
+
+	barDuration := indicator.IntervalDuration(interval)
+	stale := len(candles) == 0 ||
+		time.Since(time.UnixMilli(candles[len(candles)-1].Timestamp)) > 2*barDuration
+	if len(candles) >= bars && !stale {
+		return candles, nil
+	}
+
+	if _, err := s.SyncHistory(symbol, interval, bars); err != nil {
+		return nil, err
+	}
+	return s.klineRepo.FindCandles(symbol, interval, bars)
+}
+
+// StoredHistory returns the most recent bars stored candles without syncing (bars <= 0 returns all)
+func (s *HistoryService) StoredHistory(symbol, interval string, bars int) ([]model.Candle, error) {
+	return s.klineRepo.FindCandles(symbol, interval, bars)
+}
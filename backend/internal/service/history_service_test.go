@@ -0,0 +1,27 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+func TestClosedCandles(t *testing.T) {
+	hour := time.Hour.Milliseconds()
+	current := time.Now().UnixMilli() / hour * hour
+	candles := []model.Candle{
+		{Timestamp: current - 2*hour},
+		{Timestamp: current - hour},
+		{Timestamp: current}, // Still forming
+	}
+
+	got := closedCandles(candles, "1h")
+	if len(got) != 2 || got[1].Timestamp != current-hour {
+		t.Errorf("closed candles = %+v, want the two before %d", got, current)
+	}
+
+	if got := closedCandles(candles[:2], "1h"); len(got) != 2 {
+		t.Errorf("closed candles = %d, want 2 when nothing is forming", len(got))
+	}
+}
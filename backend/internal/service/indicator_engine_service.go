@@ -0,0 +1,55 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/kudaompq/ai_trending/backend/internal/indicator"
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// IndicatorEngineService keeps a streaming indicator engine per symbol and interval, so repeated
+// analyses of the same market only feed the candles closed since the previous one
+type IndicatorEngineService struct {
+	mu      sync.Mutex
+	engines map[string]*indicator.IndicatorEngine
+}
+
+// NewIndicatorEngineService creates a new indicator engine service
+func NewIndicatorEngineService() *IndicatorEngineService {
+	return &IndicatorEngineService{
+		engines: make(map[string]*indicator.IndicatorEngine),
+	}
+}
+
+// Indicators returns the MACD, KDJ, RSI, ATR, EMA, volume profile and pivot values for candles whose
+// last entry is still forming. The engine is rebuilt from the closed candles when it is new, its
+// settings changed or the candles do not continue what it has seen.
+func (s *IndicatorEngineService) Indicators(symbol, interval string, candles []model.Candle, profile indicator.VolumeProfileConfig) model.Indicators {
+	if len(candles) == 0 {
+		return model.Indicators{}
+	}
+	closed, forming := candles[:len(candles)-1], candles[len(candles)-1]
+	config := indicator.EngineConfig{
+		Interval:      interval,
+		VolumeProfile: profile,
+		Window:        len(candles),
+	}
+
+	key := fmt.Sprintf("%s|%s", symbol, interval)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	engine, ok := s.engines[key]
+	if !ok || engine.Config != config || !engine.Continues(closed) {
+		engine = indicator.NewIndicatorEngineFromCandles(config, closed)
+		s.engines[key] = engine
+	} else {
+		// Candles the engine has already seen are ignored
+		for _, candle := range closed {
+			engine.Update(candle)
+		}
+	}
+
+	return engine.Preview(forming)
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"math"
 	"sort"
+	"strings"
 
 	"github.com/kudaompq/ai_trending/backend/internal/indicator"
 	"github.com/kudaompq/ai_trending/backend/internal/model"
@@ -23,9 +24,12 @@ type levelInfo struct {
 	weight float64
 }
 
-// AnalyzeStructure performs comprehensive market structure analysis using all available indicators
+// AnalyzeStructure performs comprehensive market structure analysis using all available indicators.
+// swings come from indicator.FindSwings with swingConfig.
 func (s *MarketStructureService) AnalyzeStructure(
 	candles []model.Candle,
+	swings []model.SwingPoint,
+	swingConfig indicator.SwingConfig,
 	trend string,
 	indicators model.Indicators,
 	srLevels model.SRLevels,
@@ -36,8 +40,9 @@ func (s *MarketStructureService) AnalyzeStructure(
 	}
 
 	// Basic structure analysis
-	swingHighs := s.findSwingHighs(candles, 5)
-	swingLows := s.findSwingLows(candles, 5)
+	confirmed := indicator.ConfirmedSwings(swings)
+	swingHighs := indicator.SwingHighs(confirmed)
+	swingLows := indicator.SwingLows(confirmed)
 
 	higherHigh := false
 	higherLow := false
@@ -67,13 +72,28 @@ func (s *MarketStructureService) AnalyzeStructure(
 		structureBreak = true
 	}
 
+	// Labeled swing sequence and BOS/CHoCH timeline
+	labeledSwings := indicator.LabelSwings(confirmed)
+	structureEvents := indicator.DetectStructureEvents(candles, swingConfig)
+	var structureBreakEvent *model.StructureEvent
+	if structureBreak {
+		structureBreakEvent = s.findStructureBreakEvent(structureEvents, trend)
+	}
+	if len(labeledSwings) > 20 {
+		labeledSwings = labeledSwings[len(labeledSwings)-20:]
+	}
+	if len(structureEvents) > 20 {
+		structureEvents = structureEvents[len(structureEvents)-20:]
+	}
+
 	riskLevel := s.calculateRiskLevel(trend, higherHigh, higherLow, lowerHigh, lowerLow, structureBreak)
 
 	// Enhanced multi-indicator analysis
 	currentPrice := candles[len(candles)-1].Close
 	trendConfirmation := s.analyzeTrendConfirmation(candles, indicators, trend)
-	volatilityProfile := s.analyzeVolatilityProfile(candles, indicators.ATR, currentPrice)
-	keyLevelConfluence := s.analyzeKeyLevelConfluence(currentPrice, srLevels, indicators.Fibonacci, indicators.EMA)
+	volatilityProfile := s.analyzeVolatilityProfile(candles, indicators.ATR, indicators.Volatility, currentPrice)
+	smartMoney := indicator.DetectSmartMoney(candles, swings, srLevels)
+	keyLevelConfluence := s.analyzeKeyLevelConfluence(currentPrice, srLevels, indicators.Fibonacci, indicators.FibConfluence, indicators.EMA, indicators.VolumeProfile, smartMoney, indicators.Sessions)
 	patternSignals := s.analyzePatternSignals(patterns)
 	marketQuality := s.calculateMarketQuality(
 		trendConfirmation,
@@ -85,15 +105,19 @@ func (s *MarketStructureService) AnalyzeStructure(
 	)
 
 	return model.MarketStructure{
-		HigherHigh:         higherHigh,
-		HigherLow:          higherLow,
-		StructureBreak:     structureBreak,
-		RiskLevel:          riskLevel,
-		TrendConfirmation:  trendConfirmation,
-		VolatilityProfile:  volatilityProfile,
-		KeyLevelConfluence: keyLevelConfluence,
-		PatternSignals:     patternSignals,
-		MarketQuality:      marketQuality,
+		HigherHigh:          higherHigh,
+		HigherLow:           higherLow,
+		StructureBreak:      structureBreak,
+		RiskLevel:           riskLevel,
+		Swings:              labeledSwings,
+		StructureEvents:     structureEvents,
+		StructureBreakEvent: structureBreakEvent,
+		TrendConfirmation:   trendConfirmation,
+		VolatilityProfile:   volatilityProfile,
+		KeyLevelConfluence:  keyLevelConfluence,
+		PatternSignals:      patternSignals,
+		MarketQuality:       marketQuality,
+		SmartMoney:          smartMoney,
 	}
 }
 
@@ -178,21 +202,26 @@ func (s *MarketStructureService) analyzeTrendConfirmation(
 	}
 }
 
-// analyzeVolatilityProfile analyzes market volatility using ATR
+// analyzeVolatilityProfile analyzes market volatility using ATR and realized volatility
 func (s *MarketStructureService) analyzeVolatilityProfile(
 	candles []model.Candle,
 	atr model.ATRIndicator,
+	realizedVol *model.RealizedVolatility,
 	currentPrice float64,
 ) model.VolatilityProfile {
 	atrPercentage := (atr.Value / currentPrice) * 100
 
-	// Determine volatility level
-	volatilityLevel := "NORMAL"
-	if atrPercentage > 5.0 {
-		volatilityLevel = "HIGH"
-	} else if atrPercentage < 2.0 {
-		volatilityLevel = "LOW"
+	// Determine volatility level from the realized volatility percentile so it adapts
+	// per symbol and timeframe; fall back to the ATR percentile when history is short
+	volatilityPercentile := indicator.CalculateATRPercentile(candles, 14)
+	realized := 0.0
+	if realizedVol != nil {
+		realized = realizedVol.YangZhang
+		if realizedVol.HistorySize >= 20 {
+			volatilityPercentile = realizedVol.Percentile
+		}
 	}
+	volatilityLevel := indicator.VolatilityLevelFromPercentile(volatilityPercentile)
 
 	// Check if volatility is expanding
 	isExpanding := false
@@ -217,6 +246,8 @@ func (s *MarketStructureService) analyzeVolatilityProfile(
 		CurrentATR:      atr.Value,
 		ATRPercentage:   atrPercentage,
 		VolatilityLevel: volatilityLevel,
+		RealizedVol:     realized,
+		VolPercentile:   volatilityPercentile,
 		IsExpanding:     isExpanding,
 		RiskAdjustment:  riskAdjustment,
 	}
@@ -227,27 +258,57 @@ func (s *MarketStructureService) analyzeKeyLevelConfluence(
 	currentPrice float64,
 	srLevels model.SRLevels,
 	fibonacci *model.FibonacciLevels,
+	fibConfluence *model.FibonacciConfluence,
 	ema model.EMAIndicator,
+	volumeProfile *model.VolumeProfile,
+	smartMoney model.SmartMoney,
+	sessions *model.SessionAnalysis,
 ) model.KeyLevelConfluence {
 	// Collect all significant levels
 	var allLevels []levelInfo
 
-	// Add SR levels
+	// Add SR levels (volume-derived levels are added below as their own factor)
 	for _, level := range srLevels.Support {
+		if isVolumeProfileLevel(level) {
+			continue
+		}
+		factor := "Support Level"
+		if level.Source == "TRENDLINE" {
+			factor = "Trendline Support"
+		}
 		allLevels = append(allLevels, levelInfo{
 			price:  level.Price,
-			factor: "Support Level",
+			factor: factor,
 			weight: level.Strength,
 		})
 	}
 	for _, level := range srLevels.Resistance {
+		if isVolumeProfileLevel(level) {
+			continue
+		}
+		factor := "Resistance Level"
+		if level.Source == "TRENDLINE" {
+			factor = "Trendline Resistance"
+		}
 		allLevels = append(allLevels, levelInfo{
 			price:  level.Price,
-			factor: "Resistance Level",
+			factor: factor,
 			weight: level.Strength,
 		})
 	}
 
+	// Levels confirmed on several timeframes
+	for _, level := range append(append([]model.SRLevel{}, srLevels.Support...), srLevels.Resistance...) {
+		if len(level.Timeframes) < 2 {
+			continue
+		}
+		allLevels = append(allLevels, levelInfo{
+			price:  level.Price,
+			factor: "Multi-Timeframe Level (" + strings.Join(level.Timeframes, "/") + ")",
+			weight: math.Min(1.0, 0.5+0.2*float64(len(level.Timeframes)-1)),
+		})
+	}
+
 	// Add Fibonacci levels
 	if fibonacci != nil {
 		for label, price := range fibonacci.Retracement {
@@ -260,6 +321,97 @@ func (s *MarketStructureService) analyzeKeyLevelConfluence(
 			}
 		}
 	}
+	if fibConfluence != nil {
+		for _, cluster := range fibConfluence.Clusters {
+			allLevels = append(allLevels, levelInfo{
+				price:  cluster.Price,
+				factor: "Fibonacci Cluster",
+				weight: 0.6 + cluster.Strength*0.4,
+			})
+		}
+	}
+
+	// Add volume profile levels
+	if volumeProfile != nil {
+		allLevels = append(allLevels, levelInfo{
+			price:  volumeProfile.POC,
+			factor: "Volume POC",
+			weight: 0.9,
+		})
+		allLevels = append(allLevels, levelInfo{
+			price:  volumeProfile.ValueAreaHigh,
+			factor: "Value Area High",
+			weight: 0.6,
+		})
+		allLevels = append(allLevels, levelInfo{
+			price:  volumeProfile.ValueAreaLow,
+			factor: "Value Area Low",
+			weight: 0.6,
+		})
+		for _, price := range volumeProfile.HighVolumeNodes {
+			allLevels = append(allLevels, levelInfo{
+				price:  price,
+				factor: "High Volume Node",
+				weight: 0.7,
+			})
+		}
+	}
+
+	// Add smart-money zones that are still open
+	for _, block := range smartMoney.OrderBlocks {
+		factor := "Bullish Order Block"
+		if block.Type == "BEARISH" {
+			factor = "Bearish Order Block"
+		}
+		allLevels = append(allLevels, levelInfo{
+			price:  (block.High + block.Low) / 2,
+			factor: factor,
+			weight: 0.5 + block.Strength*0.3,
+		})
+	}
+	for _, gap := range smartMoney.FairValueGaps {
+		if gap.Filled {
+			continue
+		}
+		allLevels = append(allLevels, levelInfo{
+			price:  (gap.High + gap.Low) / 2,
+			factor: "Fair Value Gap",
+			weight: 0.5,
+		})
+	}
+	for _, pool := range smartMoney.LiquidityPools {
+		if pool.Swept {
+			continue
+		}
+		factor := "Equal Highs"
+		if pool.Type == "EQUAL_LOWS" {
+			factor = "Equal Lows"
+		}
+		allLevels = append(allLevels, levelInfo{
+			price:  pool.Price,
+			factor: factor,
+			weight: 0.6,
+		})
+	}
+
+	// Add the high and low of each session's latest completed instance
+	if sessions != nil {
+		for _, session := range sessions.Sessions {
+			if session.Previous == nil {
+				continue
+			}
+			allLevels = append(allLevels, levelInfo{
+				price:  session.Previous.High,
+				factor: "Prior " + session.Name + " High",
+				weight: 0.6,
+			})
+			allLevels = append(allLevels, levelInfo{
+				price:  session.Previous.Low,
+				factor: "Prior " + session.Name + " Low",
+				weight: 0.6,
+			})
+		}
+	}
 
 	// Add key EMAs
 	emaLevels := []struct {
@@ -294,6 +446,11 @@ func (s *MarketStructureService) analyzeKeyLevelConfluence(
 	}
 }
 
+// isVolumeProfileLevel reports whether an SR level was added from the volume profile
+func isVolumeProfileLevel(level model.SRLevel) bool {
+	return level.Source == "VOLUME_POC" || level.Source == "VOLUME_HVN"
+}
+
 // findConfluenceZones identifies areas where multiple levels cluster
 func (s *MarketStructureService) findConfluenceZones(
 	levels []levelInfo,
@@ -635,49 +792,26 @@ func (s *MarketStructureService) generateRecommendation(
 	}
 }
 
-// Helper methods from original implementation
-
-func (s *MarketStructureService) findSwingHighs(candles []model.Candle, lookback int) []float64 {
-	swingHighs := make([]float64, 0)
-
-	for i := lookback; i < len(candles)-lookback; i++ {
-		isSwingHigh := true
-
-		for j := i - lookback; j <= i+lookback; j++ {
-			if j != i && candles[j].High >= candles[i].High {
-				isSwingHigh = false
-				break
-			}
-		}
-
-		if isSwingHigh {
-			swingHighs = append(swingHighs, candles[i].High)
+// findStructureBreakEvent returns the most recent event against the trend, which explains StructureBreak.
+// A consolidation has no trend to break, so the latest event of either direction is returned.
+func (s *MarketStructureService) findStructureBreakEvent(events []model.StructureEvent, trend string) *model.StructureEvent {
+	against := ""
+	switch trend {
+	case "上升":
+		against = "BEARISH"
+	case "下降":
+		against = "BULLISH"
+	}
+	for i := len(events) - 1; i >= 0; i-- {
+		if against == "" || events[i].Direction == against {
+			event := events[i]
+			return &event
 		}
 	}
-
-	return swingHighs
+	return nil
 }
 
-func (s *MarketStructureService) findSwingLows(candles []model.Candle, lookback int) []float64 {
-	swingLows := make([]float64, 0)
-
-	for i := lookback; i < len(candles)-lookback; i++ {
-		isSwingLow := true
-
-		for j := i - lookback; j <= i+lookback; j++ {
-			if j != i && candles[j].Low <= candles[i].Low {
-				isSwingLow = false
-				break
-			}
-		}
-
-		if isSwingLow {
-			swingLows = append(swingLows, candles[i].Low)
-		}
-	}
-
-	return swingLows
-}
+// Helper methods from original implementation
 
 func (s *MarketStructureService) calculateRiskLevel(trend string, hh, hl, lh, ll, structureBreak bool) string {
 	if structureBreak {
@@ -701,10 +835,12 @@ func (s *MarketStructureService) calculateRiskLevel(trend string, hh, hl, lh, ll
 
 func (s *MarketStructureService) getDefaultStructure() model.MarketStructure {
 	return model.MarketStructure{
-		HigherHigh:     false,
-		HigherLow:      false,
-		StructureBreak: false,
-		RiskLevel:      "中",
+		HigherHigh:      false,
+		HigherLow:       false,
+		StructureBreak:  false,
+		RiskLevel:       "中",
+		Swings:          []model.SwingPoint{},
+		StructureEvents: []model.StructureEvent{},
 		TrendConfirmation: model.TrendConfirmation{
 			EMAAlignment:      "NEUTRAL",
 			MACDSignal:        "NEUTRAL",
@@ -716,6 +852,7 @@ func (s *MarketStructureService) getDefaultStructure() model.MarketStructure {
 			CurrentATR:      0,
 			ATRPercentage:   0,
 			VolatilityLevel: "NORMAL",
+			VolPercentile:   50,
 			IsExpanding:     false,
 			RiskAdjustment:  "NORMAL",
 		},
@@ -740,5 +877,11 @@ func (s *MarketStructureService) getDefaultStructure() model.MarketStructure {
 			Recommendation:   "等待更多数据",
 			ScoreBreakdown:   make(map[string]float64),
 		},
+		SmartMoney: model.SmartMoney{
+			OrderBlocks:     []model.OrderBlock{},
+			FairValueGaps:   []model.FairValueGap{},
+			LiquidityPools:  []model.LiquidityPool{},
+			LiquiditySweeps: []model.LiquiditySweep{},
+		},
 	}
 }
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+func TestFindStructureBreakEvent(t *testing.T) {
+	events := []model.StructureEvent{
+		{Type: "BOS", Direction: "BEARISH", BreakIndex: 10},
+		{Type: "CHOCH", Direction: "BULLISH", BreakIndex: 20},
+		{Type: "BOS", Direction: "BULLISH", BreakIndex: 30},
+	}
+
+	tests := []struct {
+		trend     string
+		wantIndex int
+	}{
+		{"上升", 10},
+		{"下降", 30},
+		// No trend to break: the latest event of either direction
+		{"盘整", 30},
+	}
+	s := &MarketStructureService{}
+	for _, tt := range tests {
+		got := s.findStructureBreakEvent(events, tt.trend)
+		if got == nil || got.BreakIndex != tt.wantIndex {
+			t.Errorf("%s: event = %+v, want the one breaking at %d", tt.trend, got, tt.wantIndex)
+		}
+	}
+
+	if got := s.findStructureBreakEvent(events[1:], "上升"); got != nil {
+		t.Errorf("uptrend without a bearish event returned %+v", got)
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/kudaompq/ai_trending/backend/internal/indicator"
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+	"github.com/kudaompq/ai_trending/backend/internal/repository"
+)
+
+// DefaultMTFIntervals are the timeframes analysed when none are requested
+var DefaultMTFIntervals = []string{"15m", "1h", "4h", "1d"}
+
+// maxMTFIntervals caps the timeframes of one multi-timeframe analysis
+const maxMTFIntervals = 6
+
+// binanceIntervals are the kline intervals Binance futures serves
+var binanceIntervals = []string{"1m", "3m", "5m", "15m", "30m", "1h", "2h", "4h", "6h", "8h", "12h", "1d", "3d", "1w", "1M"}
+
+// ErrInvalidInterval is returned for an unknown interval or too many intervals
+var ErrInvalidInterval = errors.New("invalid interval")
+
+// MTFService runs the analysis on several timeframes and measures their alignment
+type MTFService struct {
+	analysisService *AnalysisService
+	binanceRepo     *repository.BinanceRepository
+	trendService    *TrendService
+}
+
+// NewMTFService creates a new multi-timeframe service
+func NewMTFService() *MTFService {
+	return &MTFService{
+		analysisService: NewAnalysisService(),
+		binanceRepo:     repository.NewBinanceRepository(),
+		trendService:    NewTrendService(),
+	}
+}
+
+// Analyze runs PerformAnalysisWithOptions for every distinct interval concurrently and scores the
+// alignment of the ones that succeeded; a failing interval reports its error without failing the others.
+// Unknown intervals and more than maxMTFIntervals distinct ones return ErrInvalidInterval, and a
+// benchmark equal to the symbol returns ErrInvalidBenchmark.
+func (s *MTFService) Analyze(symbol string, intervals []string, limit int, opts AnalysisOptions) (*model.MTFAnalysis, error) {
+	intervals, err := distinctIntervals(intervals)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Benchmark != "" && strings.EqualFold(opts.Benchmark, symbol) {
+		return nil, fmt.Errorf("%w: symbol %s is its own benchmark", ErrInvalidBenchmark, symbol)
+	}
+	timeframes := make([]model.TimeframeResult, len(intervals))
+
+	var wg sync.WaitGroup
+	for i, interval := range intervals {
+		wg.Add(1)
+		go func(i int, interval string) {
+			defer wg.Done()
+			timeframes[i].Interval = interval
+			result, err := s.analysisService.PerformAnalysisWithOptions(symbol, interval, limit, opts)
+			if err != nil {
+				timeframes[i].Error = err.Error()
+				return
+			}
+			timeframes[i].Result = result
+		}(i, interval)
+	}
+	wg.Wait()
+
+	results := make([]*model.AnalysisResult, 0, len(timeframes))
+	for _, tf := range timeframes {
+		if tf.Result != nil {
+			results = append(results, tf.Result)
+		}
+	}
+
+	return &model.MTFAnalysis{
+		Symbol:     symbol,
+		Timeframes: timeframes,
+		Alignment:  indicator.CalculateTimeframeAlignment(results),
+	}, nil
+}
+
+// distinctIntervals trims the intervals and drops repeats, keeping the requested order
+func distinctIntervals(intervals []string) ([]string, error) {
+	distinct := make([]string, 0, len(intervals))
+	for _, interval := range intervals {
+		interval = strings.TrimSpace(interval)
+		if !containsInterval(binanceIntervals, interval) {
+			return nil, fmt.Errorf("%w %q", ErrInvalidInterval, interval)
+		}
+		if !containsInterval(distinct, interval) {
+			distinct = append(distinct, interval)
+		}
+	}
+	if len(distinct) > maxMTFIntervals {
+		return nil, fmt.Errorf("%w: at most %d intervals are allowed", ErrInvalidInterval, maxMTFIntervals)
+	}
+	return distinct, nil
+}
+
+func containsInterval(intervals []string, interval string) bool {
+	for _, v := range intervals {
+		if v == interval {
+			return true
+		}
+	}
+	return false
+}
+
+// HigherTimeframeBiases computes the trend and EMA stack of each timeframe above interval from its own
+// candles, without the full analysis; timeframes that fail to load are skipped
+func (s *MTFService) HigherTimeframeBiases(symbol, interval string, timeframes []string) []model.TimeframeBias {
+	biases := make([]model.TimeframeBias, 0, len(timeframes))
+	for _, tf := range timeframes {
+		if indicator.IntervalDuration(tf) <= indicator.IntervalDuration(interval) {
+			continue
+		}
+		candles, err := s.binanceRepo.GetKlines(symbol, tf, 200)
+		if err != nil || len(candles) < 20 {
+			continue
+		}
+
+		closes := make([]float64, len(candles))
+		for i, c := range candles {
+			closes[i] = c.Close
+		}
+		emaResults := indicator.CalculateMultipleEMA(closes, []int{9, 21, 50, 200})
+		ema := model.EMAIndicator{
+			EMA9:   emaResults[9].GetCurrentEMA(),
+			EMA21:  emaResults[21].GetCurrentEMA(),
+			EMA50:  emaResults[50].GetCurrentEMA(),
+			EMA200: emaResults[200].GetCurrentEMA(),
+		}
+
+		biases = append(biases, indicator.CalculateTimeframeBias(tf, s.trendService.AnalyzeTrend(candles), ema))
+	}
+	return biases
+}
+
+// DefaultHigherTimeframes returns the timeframes whose context is applied to opportunities on interval
+func DefaultHigherTimeframes(interval string) []string {
+	switch interval {
+	case "1m", "3m", "5m":
+		return []string{"15m", "1h"}
+	case "15m", "30m":
+		return []string{"1h", "4h"}
+	case "1h", "2h":
+		return []string{"4h", "1d"}
+	case "4h", "6h", "8h", "12h":
+		return []string{"1d"}
+	default:
+		return []string{"1w"}
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDistinctIntervals(t *testing.T) {
+	tests := []struct {
+		name      string
+		intervals []string
+		want      []string
+		wantErr   bool
+	}{
+		{"repeats dropped in order", []string{"4h", " 1h", "4h", "1h "}, []string{"4h", "1h"}, false},
+		{"unknown interval", []string{"1h", "7m"}, nil, true},
+		{"empty interval", []string{"1h", ""}, nil, true},
+		{"more than six distinct", []string{"1m", "5m", "15m", "1h", "4h", "1d", "1w"}, nil, true},
+		{"six distinct after repeats", []string{"1m", "5m", "15m", "1h", "4h", "1d", "1d"}, []string{"1m", "5m", "15m", "1h", "4h", "1d"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := distinctIntervals(tt.intervals)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidInterval) {
+					t.Fatalf("err = %v, want ErrInvalidInterval", err)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, %v; want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMTFAnalyzeRejectsOwnBenchmark(t *testing.T) {
+	s := &MTFService{}
+	_, err := s.Analyze("ETHUSDT", []string{"1h"}, 100, AnalysisOptions{Benchmark: "ethusdt"})
+	if !errors.Is(err, ErrInvalidBenchmark) {
+		t.Fatalf("err = %v, want ErrInvalidBenchmark", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"math"
 	"time"
 
+	"github.com/kudaompq/ai_trending/backend/internal/indicator"
 	"github.com/kudaompq/ai_trending/backend/internal/model"
 	"github.com/kudaompq/ai_trending/backend/internal/repository"
 )
@@ -21,11 +22,74 @@ func NewOpportunityService() *OpportunityService {
 	}
 }
 
+// opportunityStrategy is a detector together with the market regimes it may fire in
+type opportunityStrategy struct {
+	name    string
+	regimes []string
+	detect  func(candles []model.Candle, analysis *model.AnalysisResult, opts OpportunityOptions) *model.TradingOpportunity
+}
+
+// OpportunityOptions holds per-request detection settings
+type OpportunityOptions struct {
+	MinRiskReward float64
+	// Only act on candlestick patterns confirmed by a later closed candle
+	ConfirmedPatternsOnly bool
+	// Suppress new signals during the seasonality's dead hours; nil disables the filter
+	Seasonality *model.Seasonality
+	// Trend and EMA direction of higher timeframes; trades against them lose confidence
+	HigherTimeframes []model.TimeframeBias
+}
+
+// allows reports whether the strategy may fire in the given regime; an unclassified regime allows all
+func (st opportunityStrategy) allows(regime model.MarketRegime) bool {
+	if regime.Regime == "" || regime.Regime == indicator.RegimeUnknown {
+		return true
+	}
+	for _, r := range st.regimes {
+		if r == regime.Regime {
+			return true
+		}
+	}
+	return false
+}
+
+// strategies lists the opportunity strategies and the regimes they are allowed in
+func (s *OpportunityService) strategies() []opportunityStrategy {
+	return []opportunityStrategy{
+		{
+			// Bounces need levels that hold: ranges and orderly trends
+			name:    "SUPPORT_BOUNCE",
+			regimes: []string{indicator.RegimeRanging, indicator.RegimeQuiet, indicator.RegimeTrending},
+			detect:  s.detectSupportBounce,
+		},
+		{
+			// Breakouts need follow-through
+			name:    "BREAKOUT_RETEST",
+			regimes: []string{indicator.RegimeTrending, indicator.RegimeVolatile},
+			detect:  s.detectBreakoutRetest,
+		},
+		{
+			name:    "TREND_CONTINUATION",
+			regimes: []string{indicator.RegimeTrending},
+			detect:  s.detectTrendContinuation,
+		},
+	}
+}
+
 // DetectOpportunities detects trading opportunities based on analysis
 func (s *OpportunityService) DetectOpportunities(
 	candles []model.Candle,
 	analysis *model.AnalysisResult,
 	minRiskReward float64,
+) []model.TradingOpportunity {
+	return s.DetectOpportunitiesWithOptions(candles, analysis, OpportunityOptions{MinRiskReward: minRiskReward})
+}
+
+// DetectOpportunitiesWithOptions detects trading opportunities based on analysis with custom options
+func (s *OpportunityService) DetectOpportunitiesWithOptions(
+	candles []model.Candle,
+	analysis *model.AnalysisResult,
+	opts OpportunityOptions,
 ) []model.TradingOpportunity {
 	// First, update expired opportunities
 	s.repository.UpdateExpiredOpportunities()
@@ -33,31 +97,23 @@ func (s *OpportunityService) DetectOpportunities(
 	// Get existing active opportunities for this symbol
 	existingOpps, _ := s.repository.FindBySymbol(analysis.Symbol, "ACTIVE")
 
-	// Detect new opportunities
+	// Detect new opportunities with every strategy allowed in the current regime,
+	// unless the current hour is historically dead
 	newlyDetected := []model.TradingOpportunity{}
+	deadHour := indicator.IsDeadHour(opts.Seasonality, time.Now().UnixMilli())
 
-	// Try support bounce strategy
-	if opp := s.detectSupportBounce(candles, analysis); opp != nil {
-		if opp.RiskReward.Ratio >= minRiskReward {
-			// Save to database
-			s.repository.Save(opp)
-			newlyDetected = append(newlyDetected, *opp)
+	for _, strategy := range s.strategies() {
+		if deadHour || !strategy.allows(analysis.Regime) {
+			continue
 		}
-	}
-
-	// Try breakout retest strategy
-	if opp := s.detectBreakoutRetest(candles, analysis); opp != nil {
-		if opp.RiskReward.Ratio >= minRiskReward {
-			s.repository.Save(opp)
-			newlyDetected = append(newlyDetected, *opp)
-		}
-	}
-
-	// Try trend continuation strategy
-	if opp := s.detectTrendContinuation(candles, analysis); opp != nil {
-		if opp.RiskReward.Ratio >= minRiskReward {
-			s.repository.Save(opp)
-			newlyDetected = append(newlyDetected, *opp)
+		if opp := strategy.detect(candles, analysis, opts); opp != nil {
+			opp.Strategy = strategy.name
+			s.applyTimeframeContext(opp, opts.HigherTimeframes)
+			if opp.RiskReward.Ratio >= opts.MinRiskReward {
+				// Save to database
+				s.repository.Save(opp)
+				newlyDetected = append(newlyDetected, *opp)
+			}
 		}
 	}
 
@@ -87,6 +143,7 @@ func (s *OpportunityService) DetectOpportunities(
 func (s *OpportunityService) detectSupportBounce(
 	candles []model.Candle,
 	analysis *model.AnalysisResult,
+	opts OpportunityOptions,
 ) *model.TradingOpportunity {
 	if len(candles) < 50 || len(analysis.SRLevels.Support) == 0 {
 		return nil
@@ -118,19 +175,11 @@ func (s *OpportunityService) detectSupportBounce(
 	}
 
 	// Check for bullish candlestick pattern
-	hasBullishPattern := false
-	var patternName string
-	for _, pattern := range analysis.CandlestickPatterns {
-		if pattern.Direction == "看涨" && pattern.Reliability > 0.7 {
-			hasBullishPattern = true
-			patternName = pattern.Pattern
-			break
-		}
-	}
-
+	bullishPattern, hasBullishPattern := s.findBullishPattern(candles, analysis, opts.ConfirmedPatternsOnly)
 	if !hasBullishPattern {
 		return nil
 	}
+	patternName := bullishPattern.Pattern
 
 	// Calculate entry, stop-loss, and targets
 	supportPrice := strongestSupport.Price
@@ -141,7 +190,7 @@ func (s *OpportunityService) detectSupportBounce(
 	stopLossPrice := supportPrice - stopLossDistance
 
 	// Find resistance targets
-	targets := s.findResistanceTargets(currentPrice, analysis.SRLevels.Resistance, analysis.Indicators.Fibonacci)
+	targets := s.findResistanceTargets(currentPrice, analysis.SRLevels.Resistance, analysis.Indicators.Fibonacci, analysis.Indicators.Pivots)
 
 	if len(targets) == 0 {
 		return nil
@@ -161,6 +210,9 @@ func (s *OpportunityService) detectSupportBounce(
 		fmt.Sprintf("Strong support at $%.2f (strength %.2f)", supportPrice, strongestSupport.Strength),
 		fmt.Sprintf("%s pattern (reliability %.1f)", patternName, 0.8),
 	}
+	if bullishPattern.Confirmation == indicator.PatternConfirmed {
+		reasons = append(reasons, fmt.Sprintf("%s confirmed by close above $%.2f", patternName, bullishPattern.ConfirmationPrice))
+	}
 
 	// Check for EMA support
 	if analysis.Indicators.EMA.EMA50 > 0 && math.Abs(analysis.Indicators.EMA.EMA50-supportPrice) < supportPrice*0.01 {
@@ -185,7 +237,6 @@ func (s *OpportunityService) detectSupportBounce(
 		ID:        fmt.Sprintf("opp_%d", time.Now().Unix()),
 		Symbol:    analysis.Symbol,
 		Type:      "LONG",
-		Strategy:  "SUPPORT_BOUNCE",
 		Timestamp: analysis.Timestamp,
 		Entry: model.EntryPoint{
 			Price:   entryPrice,
@@ -214,29 +265,170 @@ func (s *OpportunityService) detectSupportBounce(
 	return opportunity
 }
 
-// detectBreakoutRetest detects breakout retest opportunities
+// findBullishPattern returns a reliable bullish candlestick pattern: any current one, or with
+// confirmedOnly a tracked pattern confirmed within the last few closed candles
+func (s *OpportunityService) findBullishPattern(
+	candles []model.Candle,
+	analysis *model.AnalysisResult,
+	confirmedOnly bool,
+) (model.CandlestickPattern, bool) {
+	if !confirmedOnly {
+		for _, pattern := range analysis.CandlestickPatterns {
+			if pattern.Direction == "看涨" && pattern.Reliability > 0.7 {
+				return pattern, true
+			}
+		}
+		return model.CandlestickPattern{}, false
+	}
+
+	since := candles[max(0, len(candles)-1-indicator.PatternConfirmationBars)].Timestamp
+	for _, pattern := range analysis.TrackedPatterns {
+		if pattern.Direction == "看涨" && pattern.Reliability > 0.7 &&
+			pattern.Confirmation == indicator.PatternConfirmed && pattern.ResolvedAt >= since {
+			return pattern, true
+		}
+	}
+	return model.CandlestickPattern{}, false
+}
+
+// detectBreakoutRetest detects retests of confirmed chart pattern breakouts: a pullback from above to a
+// bullish breakout level goes long, a rally from below to a bearish one goes short
 func (s *OpportunityService) detectBreakoutRetest(
 	candles []model.Candle,
 	analysis *model.AnalysisResult,
+	opts OpportunityOptions,
 ) *model.TradingOpportunity {
-	// TODO: Implement breakout retest strategy
-	return nil
+	if len(candles) < 50 || len(analysis.ChartPatterns) == 0 {
+		return nil
+	}
+
+	currentPrice := candles[len(candles)-1].Close
+	atr := analysis.Indicators.ATR.Value
+
+	// Find a confirmed breakout that price has come back to; side is 1 for bullish, -1 for bearish
+	var retested *model.ChartPattern
+	side := 0.0
+	for i := range analysis.ChartPatterns {
+		pattern := &analysis.ChartPatterns[i]
+		if pattern.Status != "CONFIRMED" {
+			continue
+		}
+		switch pattern.Direction {
+		case "看涨":
+			side = 1
+		case "看跌":
+			side = -1
+		default:
+			continue
+		}
+		// The retest must happen after the breakout candle
+		if pattern.BreakoutIndex < 0 || pattern.BreakoutIndex >= len(candles)-1 {
+			continue
+		}
+		distancePct := side * (currentPrice - pattern.BreakoutLevel) / pattern.BreakoutLevel * 100
+		if distancePct >= 0 && distancePct < 1.0 && side*(pattern.Target-currentPrice) > 0 {
+			retested = pattern
+			break
+		}
+	}
+
+	if retested == nil {
+		return nil
+	}
+
+	entryPrice := currentPrice
+	stopLossPrice := retested.BreakoutLevel - side*math.Max(atr, retested.BreakoutLevel*0.005)
+
+	// Targets: half and full measured move
+	halfMove := retested.BreakoutLevel + (retested.Target-retested.BreakoutLevel)*0.5
+	targets := []model.TakeProfitLevel{}
+	if side*(halfMove-entryPrice) > 0 {
+		targets = append(targets, model.TakeProfitLevel{
+			Level:            1,
+			Price:            halfMove,
+			DistancePct:      side * (halfMove - entryPrice) / entryPrice * 100,
+			Target:           retested.Pattern + " 50% measured move",
+			PositionClosePct: 50,
+		})
+	}
+	targets = append(targets, model.TakeProfitLevel{
+		Level:            len(targets) + 1,
+		Price:            retested.Target,
+		DistancePct:      side * (retested.Target - entryPrice) / entryPrice * 100,
+		Target:           retested.Pattern + " measured move target",
+		PositionClosePct: 30,
+	})
+
+	riskAmount := side * (entryPrice - stopLossPrice)
+	rewardAmount := side * (targets[0].Price - entryPrice)
+	rrRatio := rewardAmount / riskAmount
+
+	if rrRatio < 2.0 {
+		return nil
+	}
+
+	tradeType, position := "LONG", "above"
+	if side < 0 {
+		tradeType, position = "SHORT", "below"
+	}
+	reasons := []string{
+		fmt.Sprintf("%s breakout confirmed at $%.2f", retested.Pattern, retested.BreakoutLevel),
+		fmt.Sprintf("Price retesting breakout level (%.2f%% %s)", side*(currentPrice-retested.BreakoutLevel)/retested.BreakoutLevel*100, position),
+	}
+
+	if ema21 := analysis.Indicators.EMA.EMA21; ema21 > 0 && side*(currentPrice-ema21) > 0 {
+		reasons = append(reasons, fmt.Sprintf("Price %s EMA(21) at $%.2f", position, ema21))
+	}
+
+	confidence := s.calculateConfidence(reasons, true, retested.Reliability, rrRatio)
+
+	return &model.TradingOpportunity{
+		ID:        fmt.Sprintf("opp_br_%d", time.Now().Unix()),
+		Symbol:    analysis.Symbol,
+		Type:      tradeType,
+		Timestamp: analysis.Timestamp,
+		Entry: model.EntryPoint{
+			Price:   entryPrice,
+			Reasons: reasons,
+		},
+		StopLoss: model.StopLossInfo{
+			Price:       stopLossPrice,
+			DistancePct: riskAmount / entryPrice * 100,
+			Method:      "TECHNICAL_LEVEL",
+		},
+		TakeProfit: targets,
+		RiskReward: model.RiskRewardInfo{
+			Ratio:        rrRatio,
+			RiskAmount:   riskAmount,
+			RewardAmount: rewardAmount,
+			RiskPct:      riskAmount / entryPrice * 100,
+			RewardPct:    rewardAmount / entryPrice * 100,
+		},
+		Confidence: confidence,
+		Validity: model.ValidityInfo{
+			ExpiresAt: time.Now().Add(4*time.Hour).Unix() * 1000,
+			Status:    "ACTIVE",
+		},
+	}
 }
 
 // detectTrendContinuation detects trend continuation opportunities
 func (s *OpportunityService) detectTrendContinuation(
 	candles []model.Candle,
 	analysis *model.AnalysisResult,
+	opts OpportunityOptions,
 ) *model.TradingOpportunity {
 	// TODO: Implement trend continuation strategy
 	return nil
 }
 
 // findResistanceTargets finds resistance levels for take-profit targets
+// SR resistances are preferred; pivot levels fill in when SR levels are missing
 func (s *OpportunityService) findResistanceTargets(
 	currentPrice float64,
 	resistanceLevels []model.SRLevel,
 	fibonacci *model.FibonacciLevels,
+	pivots *model.PivotPoints,
 ) []model.TakeProfitLevel {
 	targets := []model.TakeProfitLevel{}
 
@@ -248,7 +440,15 @@ func (s *OpportunityService) findResistanceTargets(
 		}
 	}
 
-	if len(validResistances) == 0 {
+	// Pivot resistances above current price (ascending)
+	pivotLevels := []indicator.PivotLevel{}
+	for _, p := range indicator.ListPivotLevels(pivots) {
+		if p.Price > currentPrice*1.002 {
+			pivotLevels = append(pivotLevels, p)
+		}
+	}
+
+	if len(validResistances) == 0 && len(pivotLevels) == 0 {
 		return targets
 	}
 
@@ -261,24 +461,40 @@ func (s *OpportunityService) findResistanceTargets(
 		}
 	}
 
-	// Target 1: First resistance (50% position close)
+	// Target 1: First resistance, or nearest pivot (50% position close)
 	if len(validResistances) > 0 {
 		targets = append(targets, model.TakeProfitLevel{
 			Level:            1,
 			Price:            validResistances[0].Price,
 			DistancePct:      (validResistances[0].Price - currentPrice) / currentPrice * 100,
-			Target:           "SR Level resistance",
+			Target:           s.srTargetLabel(validResistances[0].Price, pivotLevels),
+			PositionClosePct: 50,
+		})
+	} else {
+		targets = append(targets, model.TakeProfitLevel{
+			Level:            1,
+			Price:            pivotLevels[0].Price,
+			DistancePct:      (pivotLevels[0].Price - currentPrice) / currentPrice * 100,
+			Target:           pivotLevels[0].Name + " pivot",
 			PositionClosePct: 50,
 		})
 	}
 
-	// Target 2: Second resistance or Fibonacci extension (30% position close)
+	// Target 2: Second resistance, next pivot or Fibonacci extension (30% position close)
 	if len(validResistances) > 1 {
 		targets = append(targets, model.TakeProfitLevel{
 			Level:            2,
 			Price:            validResistances[1].Price,
 			DistancePct:      (validResistances[1].Price - currentPrice) / currentPrice * 100,
-			Target:           "SR Level resistance",
+			Target:           s.srTargetLabel(validResistances[1].Price, pivotLevels),
+			PositionClosePct: 30,
+		})
+	} else if next := s.nextPivotAbove(targets[0].Price, pivotLevels); next != nil {
+		targets = append(targets, model.TakeProfitLevel{
+			Level:            2,
+			Price:            next.Price,
+			DistancePct:      (next.Price - currentPrice) / currentPrice * 100,
+			Target:           next.Name + " pivot",
 			PositionClosePct: 30,
 		})
 	} else if fibonacci != nil && fibonacci.Extension["1.618"] > currentPrice {
@@ -294,6 +510,26 @@ func (s *OpportunityService) findResistanceTargets(
 	return targets
 }
 
+// srTargetLabel labels an SR target, naming a pivot that coincides with it (within 0.3%)
+func (s *OpportunityService) srTargetLabel(price float64, pivotLevels []indicator.PivotLevel) string {
+	for _, p := range pivotLevels {
+		if math.Abs(p.Price-price)/price < 0.003 {
+			return "SR Level resistance + " + p.Name + " pivot"
+		}
+	}
+	return "SR Level resistance"
+}
+
+// nextPivotAbove returns the first pivot level meaningfully above the given price
+func (s *OpportunityService) nextPivotAbove(price float64, pivotLevels []indicator.PivotLevel) *indicator.PivotLevel {
+	for i := range pivotLevels {
+		if pivotLevels[i].Price > price*1.003 {
+			return &pivotLevels[i]
+		}
+	}
+	return nil
+}
+
 // calculateConfidence calculates confidence score for an opportunity
 func (s *OpportunityService) calculateConfidence(
 	reasons []string,
@@ -331,14 +567,6 @@ func (s *OpportunityService) calculateConfidence(
 		score += 5
 	}
 
-	// Determine level
-	level := "LOW"
-	if score >= 80 {
-		level = "HIGH"
-	} else if score >= 60 {
-		level = "MEDIUM"
-	}
-
 	factors := []string{}
 	if len(reasons) >= 3 {
 		factors = append(factors, "Multiple support convergence")
@@ -355,7 +583,48 @@ func (s *OpportunityService) calculateConfidence(
 
 	return model.ConfidenceInfo{
 		Score:   score,
-		Level:   level,
+		Level:   confidenceLevel(score),
 		Factors: factors,
 	}
 }
+
+// confidenceLevel maps a confidence score to its level
+func confidenceLevel(score int) string {
+	if score >= 80 {
+		return "HIGH"
+	} else if score >= 60 {
+		return "MEDIUM"
+	}
+	return "LOW"
+}
+
+// applyTimeframeContext adjusts an opportunity's confidence by the higher timeframes: -15 for each
+// timeframe trending against the trade (-5 more when its EMA stack agrees with that trend) and +5 for
+// each trending with it
+func (s *OpportunityService) applyTimeframeContext(opp *model.TradingOpportunity, biases []model.TimeframeBias) {
+	if len(biases) == 0 {
+		return
+	}
+	side := 1
+	if opp.Type == "SHORT" {
+		side = -1
+	}
+
+	score := opp.Confidence.Score
+	for _, bias := range biases {
+		switch bias.TrendSign * side {
+		case -1:
+			score -= 15
+			if bias.EMAAlignment == bias.TrendSign {
+				score -= 5
+			}
+			opp.Confidence.Factors = append(opp.Confidence.Factors, fmt.Sprintf("Against %s %s trend", bias.Interval, bias.Trend))
+		case 1:
+			score += 5
+			opp.Confidence.Factors = append(opp.Confidence.Factors, fmt.Sprintf("Aligned with %s %s trend", bias.Interval, bias.Trend))
+		}
+	}
+
+	opp.Confidence.Score = max(0, min(100, score))
+	opp.Confidence.Level = confidenceLevel(opp.Confidence.Score)
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kudaompq/ai_trending/backend/internal/indicator"
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+func TestDetectBreakoutRetest(t *testing.T) {
+	tests := []struct {
+		name      string
+		direction string
+		close     float64
+		target    float64
+		wantType  string
+		wantStop  float64
+		wantTPs   []float64
+	}{
+		// Long: price pulled back to 0.5% above the breakout level of 100
+		{"bullish", "看涨", 100.5, 120, "LONG", 99.5, []float64{110, 120}},
+		// Short: price rallied back to 0.5% below it
+		{"bearish", "看跌", 99.5, 80, "SHORT", 100.5, []float64{90, 80}},
+		// Too far from the level to be a retest
+		{"bearish away from the level", "看跌", 97, 80, "", 0, nil},
+		// Wrong side of the level for the pattern's direction
+		{"bullish below the level", "看涨", 99.5, 120, "", 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candles := make([]model.Candle, 60)
+			for i := range candles {
+				candles[i] = model.Candle{Open: tt.close, High: tt.close, Low: tt.close, Close: tt.close}
+			}
+			analysis := &model.AnalysisResult{
+				Symbol:     "BTCUSDT",
+				Indicators: model.Indicators{ATR: model.ATRIndicator{Value: 0.4}},
+				ChartPatterns: []model.ChartPattern{{
+					Pattern:       "形态",
+					Direction:     tt.direction,
+					Status:        "CONFIRMED",
+					BreakoutLevel: 100,
+					BreakoutIndex: 50,
+					Target:        tt.target,
+					Reliability:   0.75,
+				}},
+			}
+
+			opp := (&OpportunityService{}).detectBreakoutRetest(candles, analysis, OpportunityOptions{})
+			if tt.wantType == "" {
+				if opp != nil {
+					t.Fatalf("got a %s opportunity, want none", opp.Type)
+				}
+				return
+			}
+			if opp == nil {
+				t.Fatal("expected an opportunity")
+			}
+			if opp.Type != tt.wantType || math.Abs(opp.StopLoss.Price-tt.wantStop) > 1e-9 {
+				t.Errorf("%s with stop %v, want %s with stop %v", opp.Type, opp.StopLoss.Price, tt.wantType, tt.wantStop)
+			}
+			if len(opp.TakeProfit) != len(tt.wantTPs) {
+				t.Fatalf("got %d targets, want %d", len(opp.TakeProfit), len(tt.wantTPs))
+			}
+			for i, tp := range opp.TakeProfit {
+				if math.Abs(tp.Price-tt.wantTPs[i]) > 1e-9 || tp.DistancePct <= 0 {
+					t.Errorf("target %d: %v at %.2f%%, want %v at a positive distance", i+1, tp.Price, tp.DistancePct, tt.wantTPs[i])
+				}
+			}
+			// Risk 1 against 9.5 to the first target
+			if math.Abs(opp.RiskReward.RiskAmount-1) > 1e-9 || math.Abs(opp.RiskReward.Ratio-9.5) > 1e-9 {
+				t.Errorf("risk %v ratio %v, want 1 and 9.5", opp.RiskReward.RiskAmount, opp.RiskReward.Ratio)
+			}
+		})
+	}
+}
+
+func TestStrategyRegimeGate(t *testing.T) {
+	s := &OpportunityService{}
+	for _, st := range s.strategies() {
+		// Too little history to classify: nothing is filtered
+		if !st.allows(model.MarketRegime{Regime: indicator.RegimeUnknown}) {
+			t.Errorf("%s blocked in an unknown regime", st.name)
+		}
+	}
+	for _, st := range s.strategies() {
+		if st.name == "TREND_CONTINUATION" && st.allows(model.MarketRegime{Regime: indicator.RegimeRanging}) {
+			t.Errorf("%s allowed in a ranging regime", st.name)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"log"
+
+	"github.com/kudaompq/ai_trending/backend/internal/indicator"
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+	"github.com/kudaompq/ai_trending/backend/internal/repository"
+)
+
+// trackedPatternBars is how many recent bars of tracked patterns are reported with an analysis
+const trackedPatternBars = 10
+
+// PatternTrackingService persists candlestick patterns and resolves their confirmation state
+// as later candles close
+type PatternTrackingService struct {
+	repository *repository.PatternConfirmationRepository
+}
+
+// NewPatternTrackingService creates a new pattern tracking service
+func NewPatternTrackingService() *PatternTrackingService {
+	return &PatternTrackingService{
+		repository: repository.NewPatternConfirmationRepository(),
+	}
+}
+
+// Track records every directional pattern in the closed candles that is not stored yet, so bars closed
+// between requests are not missed, resolves every pending pattern against the closed candles since, and
+// returns the tracked patterns of the last few bars, newest first. The last candle is still forming,
+// so it neither creates nor resolves patterns.
+// A pending pattern whose completion candle has left the window can no longer be replayed and is failed.
+func (s *PatternTrackingService) Track(
+	symbol, interval string,
+	candles []model.Candle,
+	trendAt func(candles []model.Candle) string,
+) []model.CandlestickPattern {
+	tracked := make([]model.CandlestickPattern, 0)
+	if len(candles) < 2 {
+		return tracked
+	}
+	closed := candles[:len(candles)-1]
+
+	// Record the patterns of the closed window; ones already tracked keep their state
+	detected := indicator.ScanPatterns(closed, trendAt)
+	indicator.ConfirmPatterns(closed, detected)
+	records := make([]model.TrackedPattern, 0, len(detected))
+	for _, p := range detected {
+		if p.Confirmation == "" {
+			continue
+		}
+		// Position is relative to the last closed candle; Bars counts back from the completion candle
+		completion := candleIndex(closed, p.Timestamp)
+		records = append(records, model.TrackedPattern{
+			Symbol:             symbol,
+			Interval:           interval,
+			Bars:               1 - p.Position - (len(closed) - 1 - completion),
+			CandlestickPattern: p,
+		})
+	}
+	if err := s.repository.InsertAll(records); err != nil {
+		log.Printf("Failed to track patterns: %v", err)
+	}
+
+	// Resolve pending patterns against the closed candles after them
+	pending, err := s.repository.FindByStatus(symbol, interval, indicator.PatternPending)
+	if err != nil {
+		log.Printf("Failed to load pending patterns: %v", err)
+	}
+	for i := range pending {
+		p := &pending[i]
+		if p.Timestamp < closed[0].Timestamp {
+			p.Confirmation, p.ResolvedAt = indicator.PatternFailed, closed[0].Timestamp
+		} else {
+			after := closed[candleIndex(closed, p.Timestamp)+1:]
+			p.Confirmation, p.ResolvedAt = indicator.ResolvePatternConfirmation(p.Direction, p.ConfirmationPrice, p.InvalidationPrice, after)
+		}
+		if p.Confirmation == indicator.PatternPending {
+			continue
+		}
+		if err := s.repository.UpdateStatus(p); err != nil {
+			log.Printf("Failed to update pattern %s: %v", p.Pattern, err)
+		}
+	}
+
+	// Report the recent tracked patterns with positions relative to the current candle
+	n := len(candles)
+	since := candles[max(0, n-1-trackedPatternBars)].Timestamp
+	recent, err := s.repository.FindSince(symbol, interval, since)
+	if err != nil {
+		log.Printf("Failed to load tracked patterns: %v", err)
+		return tracked
+	}
+	for _, p := range recent {
+		pattern := p.CandlestickPattern
+		pattern.Position = candleIndex(candles, p.Timestamp) - (n - 1) - (p.Bars - 1)
+		tracked = append(tracked, pattern)
+	}
+
+	return tracked
+}
+
+// candleIndex returns the index of the candle opening at timestamp, or the last candle opening before it
+func candleIndex(candles []model.Candle, timestamp int64) int {
+	i := len(candles) - 1
+	for i > 0 && candles[i].Timestamp > timestamp {
+		i--
+	}
+	return i
+}
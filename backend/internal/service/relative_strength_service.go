@@ -0,0 +1,118 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/kudaompq/ai_trending/backend/internal/indicator"
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+	"github.com/kudaompq/ai_trending/backend/internal/repository"
+)
+
+// DefaultWatchlist is the set of symbols ranked when no watchlist is given
+var DefaultWatchlist = []string{
+	"BTCUSDT", "ETHUSDT", "BNBUSDT", "SOLUSDT", "XRPUSDT",
+	"DOGEUSDT", "ADAUSDT", "AVAXUSDT", "LINKUSDT", "DOTUSDT",
+}
+
+// ErrInvalidBenchmark is returned when the benchmark is the analyzed symbol or cannot be loaded
+var ErrInvalidBenchmark = errors.New("invalid benchmark")
+
+// RelativeStrengthService compares symbols against a benchmark through their ratio series
+type RelativeStrengthService struct {
+	binanceRepo  *repository.BinanceRepository
+	trendService *TrendService
+}
+
+// NewRelativeStrengthService creates a new relative strength service
+func NewRelativeStrengthService() *RelativeStrengthService {
+	return &RelativeStrengthService{
+		binanceRepo:  repository.NewBinanceRepository(),
+		trendService: NewTrendService(),
+	}
+}
+
+// Analyze builds the symbol/benchmark ratio series from candles, runs trend, EMA and SR analysis on it
+// and ranks the symbol's RS score among the watchlist (DefaultWatchlist when empty). Watchlist symbols
+// that fail to load are skipped; the benchmark itself is never ranked. A benchmark equal to the symbol
+// or failing to load returns ErrInvalidBenchmark.
+func (s *RelativeStrengthService) Analyze(symbol, interval string, limit int, candles []model.Candle, benchmark string, watchlist []string) (*model.RelativeStrength, error) {
+	if strings.EqualFold(symbol, benchmark) {
+		return nil, fmt.Errorf("%w: symbol %s is its own benchmark", ErrInvalidBenchmark, symbol)
+	}
+
+	benchCandles, err := s.binanceRepo.GetKlines(benchmark, interval, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to load %s: %w", ErrInvalidBenchmark, benchmark, err)
+	}
+	ratio := indicator.RatioCandles(candles, benchCandles)
+	if len(ratio) < 20 {
+		return nil, fmt.Errorf("insufficient data: %d candles shared with benchmark %s", len(ratio), benchmark)
+	}
+
+	closes := make([]float64, len(ratio))
+	for i, c := range ratio {
+		closes[i] = c.Close
+	}
+	emaResults := indicator.CalculateMultipleEMA(closes, []int{9, 21, 50, 200})
+
+	current := ratio[len(ratio)-1].Close
+	rs := &model.RelativeStrength{
+		Benchmark: benchmark,
+		Ratio:     current,
+		Change:    (current/ratio[0].Close - 1) * 100,
+		Score:     indicator.RelativeStrengthScore(ratio),
+		Trend:     s.trendService.AnalyzeTrend(ratio),
+		EMA: model.EMAIndicator{
+			EMA9:   emaResults[9].GetCurrentEMA(),
+			EMA21:  emaResults[21].GetCurrentEMA(),
+			EMA50:  emaResults[50].GetCurrentEMA(),
+			EMA200: emaResults[200].GetCurrentEMA(),
+		},
+		SRLevels: indicator.CalculateSRLevelsWithInterval(ratio, limit, interval),
+	}
+	rs.Outperforming = rs.Score > 0 && current > rs.EMA.EMA21
+
+	if len(watchlist) == 0 {
+		watchlist = DefaultWatchlist
+	}
+	ranks := []model.RSRank{{Symbol: symbol, Score: rs.Score, Change: rs.Change}}
+	for _, other := range uniqueSymbols(watchlist) {
+		if other == symbol || other == benchmark {
+			continue
+		}
+		otherCandles, err := s.binanceRepo.GetKlines(other, interval, limit)
+		if err != nil {
+			log.Printf("Failed to load %s for relative strength: %v", other, err)
+			continue
+		}
+		otherRatio := indicator.RatioCandles(otherCandles, benchCandles)
+		if len(otherRatio) < 20 {
+			continue
+		}
+		ranks = append(ranks, model.RSRank{
+			Symbol: other,
+			Score:  indicator.RelativeStrengthScore(otherRatio),
+			Change: (otherRatio[len(otherRatio)-1].Close/otherRatio[0].Close - 1) * 100,
+		})
+	}
+
+	sort.SliceStable(ranks, func(i, j int) bool {
+		return ranks[i].Score > ranks[j].Score
+	})
+	for i := range ranks {
+		ranks[i].Rank = i + 1
+		if ranks[i].Symbol == symbol {
+			rs.Rank = i + 1
+			if len(ranks) > 1 {
+				rs.Percentile = float64(len(ranks)-1-i) / float64(len(ranks)-1) * 100
+			}
+		}
+	}
+	rs.Watchlist = ranks
+
+	return rs, nil
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kudaompq/ai_trending/backend/internal/expression"
+	"github.com/kudaompq/ai_trending/backend/internal/indicator"
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+	"github.com/kudaompq/ai_trending/backend/internal/repository"
+)
+
+// scanConcurrency bounds the analyses running at once; Binance request weight is limited separately
+const scanConcurrency = 4
+
+// ScanRequest describes a market scan and the page of results to return
+type ScanRequest struct {
+	Symbols  []string // Universe; the top symbols by 24h volume when empty
+	Top      int      // Universe size when Symbols is empty
+	Interval string
+	Limit    int    // Candles per analysis
+	Filter   string // Conditions on AnalysisResult fields and saved expressions, see expression.ParseFilter
+	Sort     string // AnalysisResult field to sort by; universe order when empty
+	Desc     bool
+	Page     int // 1-based
+	PageSize int
+	Refresh  bool // Ignore the cached scan
+}
+
+// scanEntry is a cached scan of one universe
+type scanEntry struct {
+	universe  []string
+	results   []*model.AnalysisResult
+	documents []map[string]interface{} // JSON form of results, for filtering and sorting
+	candles   [][]model.Candle         // Candles behind each result, for saved expressions in filters
+	failed    []model.ScanFailure
+	scannedAt time.Time
+	expiresAt time.Time
+}
+
+// scanCall is a scan in progress; concurrent identical requests wait on done and share its outcome
+type scanCall struct {
+	done  chan struct{}
+	entry *scanEntry
+	err   error
+}
+
+// ScanService runs the analysis over a universe of symbols and caches the results
+type ScanService struct {
+	analysisService   *AnalysisService
+	engineService     *IndicatorEngineService
+	expressionService *ExpressionService
+	binanceRepo       *repository.BinanceRepository
+
+	mu       sync.Mutex
+	cache    map[string]*scanEntry
+	inflight map[string]*scanCall
+}
+
+// NewScanService creates a new scan service
+func NewScanService() *ScanService {
+	return &ScanService{
+		analysisService:   NewAnalysisService(),
+		engineService:     NewIndicatorEngineService(),
+		expressionService: NewExpressionService(),
+		binanceRepo:       repository.NewBinanceRepository(),
+		cache:             make(map[string]*scanEntry),
+		inflight:          make(map[string]*scanCall),
+	}
+}
+
+// Scan returns one page of the filtered and sorted analyses of the universe. The analyses are cached per
+// universe, interval and limit for one bar (between one and five minutes), so different filters, sorts and
+// pages reuse the same scan. Filter identifiers that are not result fields name saved expressions,
+// evaluated on the latest candle of each symbol.
+func (s *ScanService) Scan(req ScanRequest) (*model.ScanResponse, error) {
+	filter, err := expression.ParseFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.scan(req)
+	if err != nil {
+		return nil, err
+	}
+
+	sortPath := ""
+	if len(entry.documents) > 0 {
+		if err := filter.Bind(entry.documents, s.expressionService.HasExpression); err != nil {
+			return nil, err
+		}
+		if req.Sort != "" {
+			if sortPath, err = expression.ResolveField(entry.documents, req.Sort); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	matched := make([]int, 0, len(entry.results))
+	for i, doc := range entry.documents {
+		candles := entry.candles[i]
+		ok, err := filter.Match(doc, func(name string) (float64, error) {
+			return s.expressionService.LatestValue(name, candles)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, i)
+		}
+	}
+
+	if sortPath != "" {
+		sort.SliceStable(matched, func(i, j int) bool {
+			return expression.CompareFields(entry.documents[matched[i]], entry.documents[matched[j]], sortPath, req.Desc) < 0
+		})
+	}
+
+	start, end, totalPages := pageBounds(len(matched), req.Page, req.PageSize)
+	results := make([]*model.AnalysisResult, 0, end-start)
+	for _, i := range matched[start:end] {
+		results = append(results, entry.results[i])
+	}
+
+	order := "asc"
+	if req.Desc {
+		order = "desc"
+	}
+	return &model.ScanResponse{
+		Interval:   req.Interval,
+		Universe:   entry.universe,
+		Scanned:    len(entry.results),
+		Matched:    len(matched),
+		Filter:     req.Filter,
+		Sort:       req.Sort,
+		Order:      order,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: totalPages,
+		ScannedAt:  entry.scannedAt.UnixMilli(),
+		Results:    results,
+		Failed:     entry.failed,
+	}, nil
+}
+
+// scan returns the cached scan for the request's universe, running it when missing, expired or refreshed.
+// Identical requests arriving while a scan runs wait for it instead of starting their own.
+func (s *ScanService) scan(req ScanRequest) (*scanEntry, error) {
+	universe := fmt.Sprintf("top:%d", req.Top)
+	if len(req.Symbols) > 0 {
+		universe = strings.Join(req.Symbols, ",")
+	}
+	key := fmt.Sprintf("%s|%s|%d", universe, req.Interval, req.Limit)
+
+	s.mu.Lock()
+	s.evictExpired(time.Now())
+	if entry, ok := s.cache[key]; ok && !req.Refresh {
+		s.mu.Unlock()
+		return entry, nil
+	}
+	if call, ok := s.inflight[key]; ok {
+		s.mu.Unlock()
+		<-call.done
+		return call.entry, call.err
+	}
+	call := &scanCall{done: make(chan struct{})}
+	s.inflight[key] = call
+	s.mu.Unlock()
+
+	call.entry, call.err = s.runScan(req)
+
+	s.mu.Lock()
+	delete(s.inflight, key)
+	if call.err == nil {
+		s.cache[key] = call.entry
+	}
+	s.mu.Unlock()
+	close(call.done)
+
+	return call.entry, call.err
+}
+
+// evictExpired drops the cached scans older than their TTL; callers hold s.mu
+func (s *ScanService) evictExpired(now time.Time) {
+	for key, entry := range s.cache {
+		if !now.Before(entry.expiresAt) {
+			delete(s.cache, key)
+		}
+	}
+}
+
+// runScan resolves the universe and analyses it
+func (s *ScanService) runScan(req ScanRequest) (*scanEntry, error) {
+	symbols := req.Symbols
+	if len(symbols) == 0 {
+		top, err := s.binanceRepo.GetTopSymbolsByVolume(req.Top)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load top symbols: %w", err)
+		}
+		symbols = top
+	}
+
+	entry := s.analyzeAll(symbols, req.Interval, req.Limit)
+	entry.expiresAt = entry.scannedAt.Add(scanCacheTTL(req.Interval))
+	return entry, nil
+}
+
+// analyzeAll analyses every symbol with at most scanConcurrency analyses in flight, keeping universe order
+func (s *ScanService) analyzeAll(symbols []string, interval string, limit int) *scanEntry {
+	results := make([]*model.AnalysisResult, len(symbols))
+	candles := make([][]model.Candle, len(symbols))
+	errs := make([]error, len(symbols))
+
+	// Rescans feed only the candles closed since the last scan into each symbol's indicator engine
+	opts := DefaultAnalysisOptions()
+	opts.Engines = s.engineService
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, scanConcurrency)
+	for i, symbol := range symbols {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, symbol string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if candles[i], errs[i] = s.binanceRepo.GetKlines(symbol, interval, limit); errs[i] != nil {
+				return
+			}
+			results[i], errs[i] = s.analysisService.AnalyzeCandles(symbol, interval, limit, candles[i], opts)
+		}(i, symbol)
+	}
+	wg.Wait()
+
+	entry := &scanEntry{
+		universe:  symbols,
+		results:   make([]*model.AnalysisResult, 0, len(symbols)),
+		documents: make([]map[string]interface{}, 0, len(symbols)),
+		candles:   make([][]model.Candle, 0, len(symbols)),
+		failed:    []model.ScanFailure{},
+		scannedAt: time.Now(),
+	}
+	for i, symbol := range symbols {
+		if errs[i] != nil {
+			entry.failed = append(entry.failed, model.ScanFailure{Symbol: symbol, Error: errs[i].Error()})
+			continue
+		}
+		doc, err := expression.Document(results[i])
+		if err != nil {
+			entry.failed = append(entry.failed, model.ScanFailure{Symbol: symbol, Error: err.Error()})
+			continue
+		}
+		entry.results = append(entry.results, results[i])
+		entry.documents = append(entry.documents, doc)
+		entry.candles = append(entry.candles, candles[i])
+	}
+
+	return entry
+}
+
+// pageBounds returns the [start, end) range of a 1-based page and the page count; pages past the end
+// are empty
+func pageBounds(total, page, pageSize int) (int, int, int) {
+	totalPages := (total + pageSize - 1) / pageSize
+	start := min(max(page-1, 0)*pageSize, total)
+	end := min(start+pageSize, total)
+	return start, end, totalPages
+}
+
+// scanCacheTTL keeps a scan for one bar of the interval, between one and five minutes
+func scanCacheTTL(interval string) time.Duration {
+	return max(time.Minute, min(5*time.Minute, indicator.IntervalDuration(interval)))
+}
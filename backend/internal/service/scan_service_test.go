@@ -0,0 +1,53 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPageBounds(t *testing.T) {
+	tests := []struct {
+		total, page, pageSize        int
+		wantStart, wantEnd, wantPage int
+	}{
+		{0, 1, 10, 0, 0, 0},
+		{5, 1, 10, 0, 5, 1},
+		{10, 1, 10, 0, 10, 1},
+		{11, 2, 10, 10, 11, 2},
+		{25, 2, 10, 10, 20, 3},
+		{25, 3, 10, 20, 25, 3},
+		{25, 4, 10, 25, 25, 3}, // Past the last page
+		{25, 0, 10, 0, 10, 3},  // Pages are 1-based
+	}
+
+	for _, tt := range tests {
+		start, end, pages := pageBounds(tt.total, tt.page, tt.pageSize)
+		if start != tt.wantStart || end != tt.wantEnd || pages != tt.wantPage {
+			t.Errorf("pageBounds(%d, %d, %d) = %d, %d, %d, want %d, %d, %d",
+				tt.total, tt.page, tt.pageSize, start, end, pages, tt.wantStart, tt.wantEnd, tt.wantPage)
+		}
+	}
+}
+
+func TestScanCacheEviction(t *testing.T) {
+	now := time.Now()
+	s := &ScanService{cache: map[string]*scanEntry{
+		"fresh":   {expiresAt: now.Add(time.Minute)},
+		"expired": {expiresAt: now.Add(-time.Second)},
+		"due":     {expiresAt: now},
+	}}
+
+	s.evictExpired(now)
+	if len(s.cache) != 1 || s.cache["fresh"] == nil {
+		t.Errorf("cache after eviction: %v", s.cache)
+	}
+}
+
+func TestScanCacheTTL(t *testing.T) {
+	tests := map[string]time.Duration{"1m": time.Minute, "3m": 3 * time.Minute, "1h": 5 * time.Minute, "bad": time.Minute}
+	for interval, want := range tests {
+		if got := scanCacheTTL(interval); got != want {
+			t.Errorf("scanCacheTTL(%q) = %v, want %v", interval, got, want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kudaompq/ai_trending/backend/internal/indicator"
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+)
+
+// ErrInvalidTimezone is returned for a timezone that is not a known IANA name
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+// SeasonalityService computes calendar return statistics from stored history
+type SeasonalityService struct {
+	historyService *HistoryService
+}
+
+// NewSeasonalityService creates a new seasonality service
+func NewSeasonalityService() *SeasonalityService {
+	return &SeasonalityService{
+		historyService: NewHistoryService(),
+	}
+}
+
+// Analyze loads the most recent bars candles of a symbol and interval (syncing stored history when it
+// is short or stale) and groups the closed ones by hour, weekday and month in the IANA timezone
+func (s *SeasonalityService) Analyze(symbol, interval, timezone string, bars int) (*model.Seasonality, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %w", ErrInvalidTimezone, timezone, err)
+	}
+
+	candles, err := s.historyService.LoadHistory(symbol, interval, bars)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop the candle that is still forming
+	if n := len(candles); n > 0 && time.UnixMilli(candles[n-1].Timestamp).Add(indicator.IntervalDuration(interval)).After(time.Now()) {
+		candles = candles[:n-1]
+	}
+	if len(candles) < 100 {
+		return nil, fmt.Errorf("insufficient history: %d closed candles for %s %s", len(candles), symbol, interval)
+	}
+
+	hours, weekdays, months := indicator.CalculateSeasonality(candles, loc)
+
+	return &model.Seasonality{
+		Symbol:   symbol,
+		Interval: interval,
+		Timezone: loc.String(),
+		Candles:  len(candles),
+		From:     candles[0].Timestamp,
+		To:       candles[len(candles)-1].Timestamp,
+		Hours:    hours,
+		Weekdays: weekdays,
+		Months:   months,
+	}, nil
+}
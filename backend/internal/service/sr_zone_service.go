@@ -0,0 +1,148 @@
+package service
+
+import (
+	"log"
+	"math"
+	"sort"
+
+	"github.com/kudaompq/ai_trending/backend/internal/indicator"
+	"github.com/kudaompq/ai_trending/backend/internal/model"
+	"github.com/kudaompq/ai_trending/backend/internal/repository"
+)
+
+// minZoneStrength deletes stored zones that have decayed below this strength and are not currently detected
+const minZoneStrength = 0.1
+
+// maxStoredZones caps the stored zones of a symbol and interval; the weakest undetected ones are deleted
+const maxStoredZones = 40
+
+// SRZoneService accumulates support/resistance zones across requests
+type SRZoneService struct {
+	repository *repository.SRZoneRepository
+}
+
+// NewSRZoneService creates a new SR zone service
+func NewSRZoneService() *SRZoneService {
+	return &SRZoneService{
+		repository: repository.NewSRZoneRepository(),
+	}
+}
+
+// Accumulate merges freshly detected levels into the stored zones of a symbol and interval.
+// Stored zones replay the closed candles since they were last updated and overlapping ones collapse
+// into one, detected levels that overlap no stored zone become new zones, and everything is scored as
+// of the current candle; a detected level keeps at least its own strength. Undetected zones that are
+// broken, decayed below minZoneStrength or beyond the strongest maxStoredZones are deleted and the
+// rest persisted. The result holds the five nearest zones on each side.
+func (s *SRZoneService) Accumulate(symbol, interval string, candles []model.Candle, levels model.SRLevels) model.SRLevels {
+	if len(candles) < 2 {
+		return levels
+	}
+	closed := candles[:len(candles)-1]
+	atr := indicator.CalculateATR(candles, 14).GetCurrentATR()
+
+	stored, err := s.repository.FindBySymbolInterval(symbol, interval)
+	if err != nil {
+		log.Printf("Failed to load SR zones: %v", err)
+		return levels
+	}
+	for i := range stored {
+		indicator.UpdateSRZone(&stored[i], closed)
+	}
+
+	// Overlapping stored zones, e.g. left by concurrent analyses, collapse into one
+	zones, merged := indicator.MergeSRZones(stored)
+	removed := make([]int64, 0, len(merged))
+	for _, zone := range merged {
+		removed = append(removed, zone.ID)
+	}
+
+	// Match detected levels to stored zones; unmatched levels start new zones
+	detected := make(map[int]float64)
+	for _, level := range append(append([]model.SRLevel{}, levels.Resistance...), levels.Support...) {
+		match := -1
+		for i := range zones {
+			if indicator.OverlapsSRZone(zones[i].SRLevel, level) {
+				match = i
+				break
+			}
+		}
+		if match < 0 {
+			zone := indicator.NewSRZone(level, atr)
+			zone.Symbol = symbol
+			zone.Interval = interval
+			indicator.UpdateSRZone(&zone, closed)
+			zones = append(zones, zone)
+			match = len(zones) - 1
+		}
+		detected[match] = math.Max(detected[match], level.Strength)
+	}
+
+	type scoredZone struct {
+		zone     model.SRZone
+		detected bool
+	}
+	scored := make([]scoredZone, 0, len(zones))
+	for i := range zones {
+		zone := zones[i]
+		indicator.ScoreSRZone(&zone, candles)
+		strength, ok := detected[i]
+		if ok {
+			zone.Strength = math.Max(zone.Strength, strength)
+		} else if indicator.IsSRZoneBroken(zone) || zone.Strength < minZoneStrength {
+			removed = append(removed, zone.ID)
+			continue
+		}
+		scored = append(scored, scoredZone{zone, ok})
+	}
+
+	// Detected zones first, then strongest
+	sort.SliceStable(scored, func(i, j int) bool {
+		if scored[i].detected != scored[j].detected {
+			return scored[i].detected
+		}
+		return scored[i].zone.Strength > scored[j].zone.Strength
+	})
+	for len(scored) > maxStoredZones && !scored[len(scored)-1].detected {
+		removed = append(removed, scored[len(scored)-1].zone.ID)
+		scored = scored[:len(scored)-1]
+	}
+
+	for i := range scored {
+		if err := s.repository.Save(&scored[i].zone); err != nil {
+			log.Printf("Failed to save SR zone %.2f: %v", scored[i].zone.Price, err)
+		}
+	}
+	if err := s.repository.Delete(removed); err != nil {
+		log.Printf("Failed to delete SR zones: %v", err)
+	}
+
+	resistance := make([]model.SRLevel, 0)
+	support := make([]model.SRLevel, 0)
+	for _, sz := range scored {
+		if sz.zone.Role == indicator.SRRoleResistance {
+			resistance = append(resistance, sz.zone.SRLevel)
+		} else {
+			support = append(support, sz.zone.SRLevel)
+		}
+	}
+
+	// Nearest first
+	sort.Slice(resistance, func(i, j int) bool {
+		return resistance[i].Price < resistance[j].Price
+	})
+	sort.Slice(support, func(i, j int) bool {
+		return support[i].Price > support[j].Price
+	})
+	if len(resistance) > 5 {
+		resistance = resistance[:5]
+	}
+	if len(support) > 5 {
+		support = support[:5]
+	}
+
+	return model.SRLevels{
+		Resistance: resistance,
+		Support:    support,
+	}
+}
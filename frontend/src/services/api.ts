@@ -60,6 +60,78 @@ export interface FibonacciLevels {
   direction: string
 }
 
+export interface FibSwingPair {
+  id: number
+  from: SwingPoint
+  to: SwingPoint
+  direction: string
+}
+
+export interface FibLevelRef {
+  pair_id: number
+  kind: string
+  ratio: number
+  price: number
+}
+
+export interface FibCluster {
+  price: number
+  price_low: number
+  price_high: number
+  levels: FibLevelRef[]
+  pair_ids: number[]
+  strength: number
+  type: string
+}
+
+export interface FibTimeZone {
+  bars: number
+  index: number
+  timestamp: number
+  future: boolean
+  anchor: number
+}
+
+export interface FibonacciConfluence {
+  pairs: FibSwingPair[]
+  clusters: FibCluster[]
+  time_zones: FibTimeZone[]
+}
+
+export interface VolumeBin {
+  price_low: number
+  price_high: number
+  volume: number
+}
+
+export interface VolumeProfile {
+  mode: string
+  high: number
+  low: number
+  total_volume: number
+  poc: number
+  value_area_high: number
+  value_area_low: number
+  value_area_percent: number
+  high_volume_nodes: number[]
+  low_volume_nodes: number[]
+  bins: VolumeBin[]
+  candle_count: number
+}
+
+export interface PivotPoints {
+  timeframe: string
+  timestamp: number
+  high: number
+  low: number
+  close: number
+  classic: Record<string, number>
+  fibonacci: Record<string, number>
+  camarilla: Record<string, number>
+  woodie: Record<string, number>
+  demark: Record<string, number>
+}
+
 export interface Indicators {
   macd: MACDIndicator
   kdj: KDJIndicator
@@ -67,11 +139,81 @@ export interface Indicators {
   atr: ATRIndicator
   ema: EMAIndicator
   fibonacci?: FibonacciLevels
+  fibonacci_confluence?: FibonacciConfluence
+  volume_profile?: VolumeProfile
+  pivots?: PivotPoints
+  realized_volatility?: RealizedVolatility
+  sessions?: SessionAnalysis
+}
+
+export interface SessionRange {
+  start: number
+  end: number
+  open: number
+  high: number
+  low: number
+  close: number
+  complete: boolean
+}
+
+export interface SessionSweep {
+  session: string
+  side: 'HIGH' | 'LOW'
+  price: number
+  extreme: number
+  session_start: number
+  timestamp: number
+}
+
+export interface TradingSession {
+  name: string
+  timezone: string
+  start: string
+  end: string
+  active: boolean
+  current?: SessionRange
+  previous?: SessionRange
+  avg_range: number
+  range_ratio: number
+  sweeps: SessionSweep[]
+}
+
+export interface SessionAnalysis {
+  timeframe: string
+  active: string[]
+  sessions: TradingSession[]
+}
+
+export interface RealizedVolatility {
+  interval: string
+  window: number
+  close_to_close: number
+  parkinson: number
+  garman_klass: number
+  yang_zhang: number
+  percentile: number
+  history_size: number
 }
 
 export interface SRLevel {
   price: number
   strength: number
+  source?: string
+  lower?: number
+  upper?: number
+  touches?: number
+  first_touch?: number
+  last_touch?: number
+  age_bars?: number
+  bars_since_touch: number
+  volume_score?: number
+  role?: 'SUPPORT' | 'RESISTANCE'
+  origin_role?: 'SUPPORT' | 'RESISTANCE'
+  breaks?: number
+  broken_at?: number
+  flipped?: boolean
+  timeframe?: string
+  timeframes?: string[]
 }
 
 export interface SRLevels {
@@ -84,8 +226,89 @@ export interface CandlestickPattern {
   type: string
   direction: string
   position: number
+  timestamp: number
   reliability: number
   description: string
+  prior_reliability?: number
+  empirical_reliability?: number
+  sample_size?: number
+  confirmation?: 'PENDING' | 'CONFIRMED' | 'FAILED'
+  confirmation_price?: number
+  invalidation_price?: number
+  resolved_at?: number
+}
+
+export interface PatternPoint {
+  label: string
+  index: number
+  timestamp: number
+  price: number
+}
+
+export interface Trendline {
+  type: 'SUPPORT' | 'RESISTANCE'
+  direction: 'UP' | 'DOWN' | 'FLAT'
+  touches: PatternPoint[]
+  slope: number
+  start_index: number
+  violations: number
+  score: number
+  current_price: number
+  broken: boolean
+  break?: TrendlineBreak
+}
+
+export interface TrendlineBreak {
+  line_type: 'SUPPORT' | 'RESISTANCE'
+  direction: 'UP' | 'DOWN'
+  index: number
+  timestamp: number
+  close: number
+  line_price: number
+}
+
+export interface TrendChannel {
+  direction: 'UP' | 'DOWN' | 'FLAT'
+  upper: Trendline
+  lower: Trendline
+  width: number
+  position: number
+  score: number
+}
+
+export interface TrendlineAnalysis {
+  lines: Trendline[]
+  channels: TrendChannel[]
+  break_events: TrendlineBreak[]
+}
+
+export interface ChartPattern {
+  pattern: string
+  code: string
+  type: string
+  direction: string
+  points: PatternPoint[]
+  neckline?: PatternPoint[]
+  breakout_level: number
+  invalidation_level: number
+  target: number
+  status: string
+  breakout_index: number
+  reliability: number
+}
+
+export interface HarmonicPattern {
+  pattern: string
+  direction: string
+  status: string
+  points: PatternPoint[]
+  ratios: Record<string, number>
+  prz: [number, number]
+  projected_d: number
+  stop_loss: number
+  targets: number[]
+  score: number
+  reliability: number
 }
 
 export interface TrendConfirmation {
@@ -100,6 +323,8 @@ export interface VolatilityProfile {
   current_atr: number
   atr_percentage: number
   volatility_level: string
+  realized_vol: number
+  vol_percentile: number
   is_expanding: boolean
   risk_adjustment: string
 }
@@ -143,16 +368,96 @@ export interface MarketQuality {
   score_breakdown: Record<string, number>
 }
 
+export interface SwingPoint {
+  index: number
+  timestamp: number
+  price: number
+  type: string
+  confirmed: boolean
+  label?: string
+}
+
+export interface StructureEvent {
+  type: string
+  direction: string
+  level: number
+  swing_label?: string
+  swing_index: number
+  swing_timestamp: number
+  break_index: number
+  break_timestamp: number
+  break_close: number
+}
+
 export interface MarketStructure {
   higher_high: boolean
   higher_low: boolean
   structure_break: boolean
   risk_level: string
+  swings: SwingPoint[]
+  structure_events: StructureEvent[]
+  structure_break_event?: StructureEvent
   trend_confirmation: TrendConfirmation
   volatility_profile: VolatilityProfile
   key_level_confluence: KeyLevelConfluence
   pattern_signals: PatternSignals
   market_quality: MarketQuality
+  smart_money: SmartMoney
+}
+
+export interface OrderBlock {
+  type: string
+  high: number
+  low: number
+  index: number
+  timestamp: number
+  mitigated: boolean
+  strength: number
+}
+
+export interface FairValueGap {
+  type: string
+  high: number
+  low: number
+  index: number
+  timestamp: number
+  fill_percent: number
+  filled: boolean
+}
+
+export interface LiquidityPool {
+  type: string
+  price: number
+  touches: number
+  indices: number[]
+  swept: boolean
+}
+
+export interface LiquiditySweep {
+  type: string
+  level: number
+  level_source?: string
+  index: number
+  timestamp: number
+  wick_price: number
+  close: number
+}
+
+export interface SmartMoney {
+  order_blocks: OrderBlock[]
+  fair_value_gaps: FairValueGap[]
+  liquidity_pools: LiquidityPool[]
+  liquidity_sweeps: LiquiditySweep[]
+}
+
+export interface MarketRegime {
+  regime: string
+  probabilities: Record<string, number>
+  adx: number
+  choppiness: number
+  hurst: number
+  variance_ratio: number
+  atr_percentile: number
 }
 
 export interface AnalysisResult {
@@ -160,12 +465,40 @@ export interface AnalysisResult {
   interval: string
   timestamp: number
   trend: TrendAnalysis
+  regime: MarketRegime
   indicators: Indicators
   sr_levels: SRLevels
   candlestick_patterns: CandlestickPattern[]
+  pattern_history?: CandlestickPattern[]
+  tracked_patterns: CandlestickPattern[]
+  chart_patterns: ChartPattern[]
+  harmonic_patterns: HarmonicPattern[]
+  trendlines: TrendlineAnalysis
+  relative_strength?: RelativeStrength
   market_structure: MarketStructure
 }
 
+export interface RSRank {
+  symbol: string
+  score: number
+  change: number
+  rank: number
+}
+
+export interface RelativeStrength {
+  benchmark: string
+  ratio: number
+  change: number
+  score: number
+  outperforming: boolean
+  trend: TrendAnalysis
+  ema: EMAIndicator
+  sr_levels: SRLevels
+  rank: number
+  percentile: number
+  watchlist: RSRank[]
+}
+
 // Trading Opportunity Types
 export interface EntryPoint {
   price: number
@@ -230,6 +563,174 @@ export interface OpportunitiesResponse {
   summary: OpportunitySummary
 }
 
+// Custom Expression Types
+export interface SavedExpression {
+  name: string
+  expression: string
+  description: string
+  created_at?: number
+  updated_at?: number
+}
+
+export interface ExpressionPoint {
+  timestamp: number
+  value: number | null
+}
+
+export interface ExpressionResult {
+  symbol: string
+  interval: string
+  name?: string
+  expression: string
+  series: ExpressionPoint[]
+  latest: number | null
+}
+
+export interface PatternStats {
+  symbol: string
+  interval: string
+  pattern: string
+  direction: string
+  horizon: number
+  samples: number
+  hits: number
+  hit_rate: number
+  avg_return: number
+  prior_reliability: number
+  empirical_reliability: number
+  updated_at: number
+}
+
+export interface CalibrationResult {
+  symbol: string
+  interval: string
+  horizon: number
+  candles: number
+  from: number
+  to: number
+  stats: PatternStats[]
+}
+
+export interface RollingBeta {
+  timestamp: number
+  correlation: number
+  beta: number
+}
+
+export interface SymbolBeta {
+  symbol: string
+  correlation: number
+  beta: number
+  rolling: RollingBeta[]
+}
+
+export interface CorrelationMatrix {
+  interval: string
+  window: number
+  benchmark: string
+  symbols: string[]
+  matrix: number[][]
+  betas: SymbolBeta[]
+  observations: number
+  from: number
+  to: number
+}
+
+export interface SeasonalityBucket {
+  key: number
+  label: string
+  samples: number
+  avg_return: number
+  win_rate: number
+  avg_range: number
+  avg_volume: number
+  relative_range: number
+  relative_volume: number
+  dead: boolean
+}
+
+export interface Seasonality {
+  symbol: string
+  interval: string
+  timezone: string
+  candles: number
+  from: number
+  to: number
+  hours: SeasonalityBucket[]
+  weekdays: SeasonalityBucket[]
+  months: SeasonalityBucket[]
+}
+
+export interface TimeframeBias {
+  interval: string
+  trend: string
+  trend_strength: number
+  trend_sign: number
+  ema_alignment: number
+}
+
+export interface SharedLevel {
+  price: number
+  type: 'SUPPORT' | 'RESISTANCE'
+  timeframes: string[]
+}
+
+export interface TimeframeAlignment {
+  score: number
+  direction: 'BULLISH' | 'BEARISH' | 'NEUTRAL'
+  trend_agreement: number
+  ema_agreement: number
+  level_agreement: number
+  biases: TimeframeBias[]
+  shared_levels: SharedLevel[]
+}
+
+export interface TimeframeResult {
+  interval: string
+  result?: AnalysisResult
+  error?: string
+}
+
+export interface MTFAnalysis {
+  symbol: string
+  timeframes: TimeframeResult[]
+  alignment: TimeframeAlignment
+}
+
+export interface ScanFailure {
+  symbol: string
+  error: string
+}
+
+export interface ScanResponse {
+  interval: string
+  universe: string[]
+  scanned: number
+  matched: number
+  filter?: string
+  sort?: string
+  order: 'asc' | 'desc'
+  page: number
+  page_size: number
+  total_pages: number
+  scanned_at: number
+  results: AnalysisResult[]
+  failed: ScanFailure[]
+}
+
+export interface ScanParams {
+  symbols?: string[]
+  top?: number
+  interval?: string
+  limit?: number
+  filter?: string
+  sort?: string
+  order?: 'asc' | 'desc'
+  page?: number
+  pageSize?: number
+  refresh?: boolean
+}
+
 export const api = {
   async getKlineData(symbol: string, interval: string, limit: number): Promise<KlineData> {
     const response = await axios.get(`${API_BASE_URL}/kline`, {
@@ -238,16 +739,145 @@ export const api = {
     return response.data
   },
 
-  async getAnalysis(symbol: string, interval: string, limit: number): Promise<AnalysisResult> {
+  async getAnalysis(
+    symbol: string,
+    interval: string,
+    limit: number,
+    patternScan: boolean = false,
+    empiricalReliability: boolean = false,
+    srTimeframes: string[] = [],
+    benchmark: string = '',
+    rsWatchlist: string[] = [],
+    sessions: string = '',
+    swingMode: '' | 'fractal' | 'percent' | 'atr' = ''
+  ): Promise<AnalysisResult> {
     const response = await axios.get(`${API_BASE_URL}/analysis`, {
-      params: { symbol, interval, limit }
+      params: {
+        symbol,
+        interval,
+        limit,
+        ...(patternScan ? { pattern_scan: true } : {}),
+        ...(empiricalReliability ? { empirical_reliability: true } : {}),
+        ...(srTimeframes.length > 0 ? { sr_timeframes: srTimeframes.join(',') } : {}),
+        ...(benchmark ? { benchmark } : {}),
+        ...(rsWatchlist.length > 0 ? { rs_watchlist: rsWatchlist.join(',') } : {}),
+        ...(sessions ? { sessions } : {}),
+        ...(swingMode ? { swing_mode: swingMode } : {})
+      }
     })
     return response.data
   },
 
-  async getOpportunities(symbol: string, interval: string, minRR: number = 3.0): Promise<OpportunitiesResponse> {
+  async getMTFAnalysis(
+    symbol: string,
+    intervals: string[] = ['15m', '1h', '4h', '1d'],
+    limit: number = 100
+  ): Promise<MTFAnalysis> {
+    const response = await axios.get(`${API_BASE_URL}/analysis/mtf`, {
+      params: { symbol, intervals: intervals.join(','), limit }
+    })
+    return response.data
+  },
+
+  async getOpportunities(
+    symbol: string,
+    interval: string,
+    minRR: number = 3.0,
+    confirmedOnly: boolean = false,
+    skipDeadHours: boolean = false,
+    timezone: string = 'UTC'
+  ): Promise<OpportunitiesResponse> {
     const response = await axios.get(`${API_BASE_URL}/opportunities`, {
-      params: { symbol, interval, min_rr: minRR, limit: 100 }
+      params: {
+        symbol,
+        interval,
+        min_rr: minRR,
+        limit: 100,
+        ...(confirmedOnly ? { confirmed_only: true } : {}),
+        ...(skipDeadHours ? { skip_dead_hours: true, timezone } : {})
+      }
+    })
+    return response.data
+  },
+
+  async evaluateExpression(symbol: string, interval: string, expression: string, limit: number = 200): Promise<ExpressionResult> {
+    const response = await axios.post(`${API_BASE_URL}/expressions/evaluate`, {
+      symbol, interval, limit, expression
+    })
+    return response.data
+  },
+
+  async getExpressions(): Promise<{ expressions: SavedExpression[] }> {
+    const response = await axios.get(`${API_BASE_URL}/expressions`)
+    return response.data
+  },
+
+  async saveExpression(expression: SavedExpression): Promise<SavedExpression> {
+    const response = await axios.post(`${API_BASE_URL}/expressions`, expression)
+    return response.data
+  },
+
+  async deleteExpression(name: string): Promise<{ deleted: string }> {
+    const response = await axios.delete(`${API_BASE_URL}/expressions/${name}`)
+    return response.data
+  },
+
+  async getCalibration(symbol: string, interval: string): Promise<{ symbol: string; interval: string; stats: PatternStats[] }> {
+    const response = await axios.get(`${API_BASE_URL}/calibration`, {
+      params: { symbol, interval }
+    })
+    return response.data
+  },
+
+  async runCalibration(
+    symbol: string,
+    interval: string,
+    horizon: number = 10,
+    bars: number = 5000,
+    sync: boolean = true
+  ): Promise<CalibrationResult> {
+    const response = await axios.post(`${API_BASE_URL}/calibration/run`, {
+      symbol, interval, horizon, bars, sync
+    })
+    return response.data
+  },
+
+  async getCorrelation(
+    symbols: string[],
+    benchmark: string = 'BTCUSDT',
+    interval: string = '1h',
+    window: number = 30
+  ): Promise<CorrelationMatrix> {
+    const response = await axios.get(`${API_BASE_URL}/correlation`, {
+      params: { symbols: symbols.join(','), benchmark, interval, window }
+    })
+    return response.data
+  },
+
+  async getSeasonality(
+    symbol: string,
+    interval: string = '1h',
+    timezone: string = 'UTC',
+    bars: number = 2000
+  ): Promise<Seasonality> {
+    const response = await axios.get(`${API_BASE_URL}/seasonality`, {
+      params: { symbol, interval, timezone, bars }
+    })
+    return response.data
+  },
+
+  async scan(params: ScanParams = {}): Promise<ScanResponse> {
+    const response = await axios.get(`${API_BASE_URL}/scan`, {
+      params: {
+        ...(params.symbols && params.symbols.length > 0 ? { symbols: params.symbols.join(',') } : { top: params.top ?? 20 }),
+        interval: params.interval ?? '1h',
+        limit: params.limit ?? 100,
+        ...(params.filter ? { filter: params.filter } : {}),
+        ...(params.sort ? { sort: params.sort, order: params.order ?? 'desc' } : {}),
+        page: params.page ?? 1,
+        page_size: params.pageSize ?? 10,
+        ...(params.refresh ? { refresh: true } : {})
+      }
     })
     return response.data
   },